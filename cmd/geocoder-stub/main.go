@@ -0,0 +1,85 @@
+// Command geocoder-stub serves the Nominatim compatible /search and /reverse endpoints
+// from the offline gazetteer, so the HTTP geocoder can be run locally without a provider.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/geocoding"
+)
+
+type place struct {
+	Lat         string  `json:"lat"`
+	Lon         string  `json:"lon"`
+	Name        string  `json:"name"`
+	DisplayName string  `json:"display_name"`
+	Address     address `json:"address"`
+}
+
+type address struct {
+	City        string `json:"city"`
+	CountryCode string `json:"country_code"`
+}
+
+func main() {
+	addr := flag.String("addr", ":8088", "listen address")
+	citiesFile := flag.String("cities", "", "GeoNames cities file, the bundled one by default")
+	flag.Parse()
+
+	gazetteer, err := geocoding.NewGazetteer(*citiesFile)
+	if err != nil {
+		log.Fatalf("Unable to load cities: %s", err)
+	}
+
+	http.HandleFunc("/search", func(w http.ResponseWriter, r *http.Request) {
+		p, err := gazetteer.Forward(r.URL.Query().Get("q"))
+		if errors.Is(err, domain.ErrPlaceNotFound) {
+			writeJSON(w, []place{})
+			return
+		}
+		writeJSON(w, []place{toPlace(p)})
+	})
+
+	http.HandleFunc("/reverse", func(w http.ResponseWriter, r *http.Request) {
+		lat, errLat := strconv.ParseFloat(r.URL.Query().Get("lat"), 64)
+		lon, errLon := strconv.ParseFloat(r.URL.Query().Get("lon"), 64)
+		if errLat != nil || errLon != nil {
+			http.Error(w, "invalid coordinates", http.StatusBadRequest)
+			return
+		}
+
+		p, err := gazetteer.Reverse(domain.GeoPoint{Lat: lat, Lon: lon})
+		if errors.Is(err, domain.ErrPlaceNotFound) {
+			writeJSON(w, map[string]string{"error": "Unable to geocode"})
+			return
+		}
+		writeJSON(w, toPlace(p))
+	})
+
+	log.Printf("Geocoder stub listening on %s", *addr)
+	log.Fatal(http.ListenAndServe(*addr, nil))
+}
+
+func toPlace(p domain.GeoPlace) place {
+	return place{
+		Lat:         strconv.FormatFloat(p.Point.Lat, 'f', -1, 64),
+		Lon:         strconv.FormatFloat(p.Point.Lon, 'f', -1, 64),
+		Name:        p.Name,
+		DisplayName: p.Name,
+		Address:     address{City: p.City, CountryCode: p.Country},
+	}
+}
+
+func writeJSON(w http.ResponseWriter, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	err := json.NewEncoder(w).Encode(body)
+	if err != nil {
+		log.Print(err)
+	}
+}
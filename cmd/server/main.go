@@ -48,6 +48,13 @@ func main() {
 
 	cont := container.New(conf)
 
+	// Background workers
+	go cont.SavedSearchMatcher.Run(ctx)
+	go cont.ReminderScheduler.Run(ctx)
+	go cont.MailQueue.Run(ctx)
+	go cont.WebhookDispatcher.Run(ctx)
+	go cont.StreamHub.Run(ctx)
+
 	// HTTP Server
 	err = http.Server(
 		ctx,
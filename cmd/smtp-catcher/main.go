@@ -0,0 +1,127 @@
+// Command smtp-catcher is a local SMTP server which accepts every email and saves it into
+// a directory as an .eml file, so the smtp mail driver can be run and checked locally
+// without a real mail server.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+var received atomic.Uint64
+
+func main() {
+	addr := flag.String("addr", ":1025", "listen address")
+	dir := flag.String("dir", "mail_catcher", "directory the emails are saved to")
+	flag.Parse()
+
+	err := os.MkdirAll(*dir, os.ModePerm)
+	if err != nil {
+		log.Fatalf("Unable to create %s: %s", *dir, err)
+	}
+
+	listener, err := net.Listen("tcp", *addr)
+	if err != nil {
+		log.Fatalf("Unable to listen on %s: %s", *addr, err)
+	}
+	log.Printf("Catching emails on %s into %s", *addr, *dir)
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			log.Printf("Accept: %s", err)
+			continue
+		}
+		go serve(conn, *dir)
+	}
+}
+
+// serve speaks just enough SMTP for net/smtp and most clients, no TLS and no authentication.
+func serve(conn net.Conn, dir string) {
+	defer conn.Close()
+	c := textproto.NewConn(conn)
+
+	var from string
+	var to []string
+	reply(c, 220, "smtp-catcher ready")
+	for {
+		line, err := c.ReadLine()
+		if err != nil {
+			return
+		}
+		verb, arg, _ := strings.Cut(line, " ")
+
+		switch strings.ToUpper(verb) {
+		case "HELO":
+			reply(c, 250, "smtp-catcher")
+		case "EHLO":
+			reply(c, 250, "smtp-catcher", "8BITMIME")
+		case "MAIL":
+			from, to = address(arg), nil
+			reply(c, 250, "OK")
+		case "RCPT":
+			to = append(to, address(arg))
+			reply(c, 250, "OK")
+		case "DATA":
+			if len(to) == 0 {
+				reply(c, 503, "RCPT first")
+				continue
+			}
+			reply(c, 354, "End data with <CR><LF>.<CR><LF>")
+			data, err := c.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			file, err := save(dir, from, to, data)
+			if err != nil {
+				log.Printf("Save: %s", err)
+				reply(c, 451, "unable to save the message")
+				continue
+			}
+			log.Printf("Mail from %s to %s saved to %s", from, strings.Join(to, ", "), file)
+			reply(c, 250, "OK")
+		case "RSET":
+			from, to = "", nil
+			reply(c, 250, "OK")
+		case "NOOP":
+			reply(c, 250, "OK")
+		case "QUIT":
+			reply(c, 221, "Bye")
+			return
+		default:
+			reply(c, 502, "command not implemented")
+		}
+	}
+}
+
+func reply(c *textproto.Conn, code int, lines ...string) {
+	for i, line := range lines {
+		separator := "-"
+		if i == len(lines)-1 {
+			separator = " "
+		}
+		_ = c.PrintfLine("%d%s%s", code, separator, line)
+	}
+}
+
+// address extracts the address from "FROM:<a@b.c> SIZE=100".
+func address(arg string) string {
+	_, addr, _ := strings.Cut(arg, ":")
+	addr, _, _ = strings.Cut(strings.TrimSpace(addr), " ")
+	return strings.Trim(addr, "<>")
+}
+
+func save(dir, from string, to []string, data []byte) (string, error) {
+	name := fmt.Sprintf("%s-%d.eml", time.Now().Format("20060102T150405"), received.Add(1))
+	file := filepath.Join(dir, name)
+	envelope := fmt.Sprintf("X-Envelope-From: %s\r\nX-Envelope-To: %s\r\n", from, strings.Join(to, ", "))
+	return file, os.WriteFile(file, append([]byte(envelope), data...), 0644)
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"log"
 	"os"
+	"strings"
 	"time"
 )
 
@@ -16,6 +17,27 @@ type Configuration struct {
 	FileStorageLocation string
 	JwtSecret           string
 	JwtTTL              time.Duration
+	TicketSecret        string
+	Geocoder            string
+	GeocoderUrl         string
+	GeocoderCitiesFile  string
+	ReminderOffsets     []time.Duration
+	MailDriver          string
+	MailFrom            string
+	MailOutboxLocation  string
+	SmtpAddr            string
+	SmtpUser            string
+	SmtpPassword        string
+	PushDriver          string
+	VapidPublicKey      string
+	VapidPrivateKey     string
+	VapidSubject        string
+	FcmCredentialsFile  string
+	ApnsKeyFile         string
+	ApnsKeyId           string
+	ApnsTeamId          string
+	ApnsTopic           string
+	ApnsProduction      bool
 }
 
 func GetConfiguration() Configuration {
@@ -29,6 +51,27 @@ func GetConfiguration() Configuration {
 		FileStorageLocation: getOrDefault("FILES_LOCATION", "file_storage"),
 		JwtSecret:           getOrDefault("JWT_SECRET", "1234567890"),
 		JwtTTL:              72 * time.Hour,
+		TicketSecret:        getOrDefault("TICKET_SECRET", "0987654321"),
+		Geocoder:            getOrDefault("GEOCODER", "offline"),
+		GeocoderUrl:         getOrDefault("GEOCODER_URL", "http://localhost:8088"),
+		GeocoderCitiesFile:  getOrDefault("GEOCODER_CITIES_FILE", ""),
+		ReminderOffsets:     getDurations("REMINDER_OFFSETS", "24h,1h"),
+		MailDriver:          getOrDefault("MAIL_DRIVER", "log"),
+		MailFrom:            getOrDefault("MAIL_FROM", "Eventio <no-reply@eventio.local>"),
+		MailOutboxLocation:  getOrDefault("MAIL_OUTBOX_LOCATION", "mail_outbox"),
+		SmtpAddr:            getOrDefault("SMTP_ADDR", "localhost:1025"),
+		SmtpUser:            getOrDefault("SMTP_USER", ""),
+		SmtpPassword:        getOrDefault("SMTP_PASSWORD", ""),
+		PushDriver:          getOrDefault("PUSH_DRIVER", "fake"),
+		VapidPublicKey:      getOrDefault("VAPID_PUBLIC_KEY", ""),
+		VapidPrivateKey:     getOrDefault("VAPID_PRIVATE_KEY", ""),
+		VapidSubject:        getOrDefault("VAPID_SUBJECT", "mailto:no-reply@eventio.local"),
+		FcmCredentialsFile:  getOrDefault("FCM_CREDENTIALS_FILE", ""),
+		ApnsKeyFile:         getOrDefault("APNS_KEY_FILE", ""),
+		ApnsKeyId:           getOrDefault("APNS_KEY_ID", ""),
+		ApnsTeamId:          getOrDefault("APNS_TEAM_ID", ""),
+		ApnsTopic:           getOrDefault("APNS_TOPIC", ""),
+		ApnsProduction:      getOrDefault("APNS_PRODUCTION", "false") == "true",
 	}
 }
 
@@ -48,3 +91,20 @@ func getOrDefault(key, defaultVal string) string {
 	}
 	return env
 }
+
+// getDurations parses a comma separated list of durations, e.g. "24h,1h".
+func getDurations(key, defaultVal string) []time.Duration {
+	var durations []time.Duration
+	for _, value := range strings.Split(getOrDefault(key, defaultVal), ",") {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		d, err := time.ParseDuration(value)
+		if err != nil || d < time.Minute {
+			log.Fatalf("%s env var has an invalid duration %q", key, value)
+		}
+		durations = append(durations, d)
+	}
+	return durations
+}
@@ -3,10 +3,15 @@ package container
 import (
 	"github.com/BohdanBoriak/boilerplate-go-back/config"
 	"github.com/BohdanBoriak/boilerplate-go-back/internal/app"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
 	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/database"
 	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/filesystem"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/geocoding"
 	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/http/controllers"
 	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/http/middlewares"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/mail"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/push"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/webhook"
 	"github.com/go-chi/jwtauth/v5"
 	"github.com/upper/db/v4"
 	"github.com/upper/db/v4/adapter/postgresql"
@@ -18,11 +23,21 @@ type Container struct {
 	Middlewares
 	Services
 	Controllers
+	Workers
 }
 
 type Middlewares struct {
-	AuthMw func(http.Handler) http.Handler
-	PathMw func(http.Handler) http.Handler
+	AuthMw             func(http.Handler) http.Handler
+	StreamAuthMw       func(http.Handler) http.Handler
+	PathMw             func(http.Handler) http.Handler
+	CategoryPathMw     func(http.Handler) http.Handler
+	VenuePathMw        func(http.Handler) http.Handler
+	SavedSearchPathMw  func(http.Handler) http.Handler
+	NotificationPathMw func(http.Handler) http.Handler
+	PushDevicePathMw   func(http.Handler) http.Handler
+	WebhookPathMw      func(http.Handler) http.Handler
+	CommentPathMw      func(http.Handler) http.Handler
+	ReviewPathMw       func(http.Handler) http.Handler
 }
 
 type Services struct {
@@ -31,9 +46,30 @@ type Services struct {
 }
 
 type Controllers struct {
-	AuthController  controllers.AuthController
-	UserController  controllers.UserController
-	EventController controllers.EventController
+	AuthController         controllers.AuthController
+	UserController         controllers.UserController
+	EventController        controllers.EventController
+	TicketController       controllers.TicketController
+	CalendarController     controllers.CalendarController
+	CategoryController     controllers.CategoryController
+	VenueController        controllers.VenueController
+	SavedSearchController  controllers.SavedSearchController
+	NotificationController controllers.NotificationController
+	PushDeviceController   controllers.PushDeviceController
+	AnnouncementController controllers.AnnouncementController
+	WebhookController      controllers.WebhookController
+	StreamController       controllers.StreamController
+	CommentController      controllers.CommentController
+	ReviewController       controllers.ReviewController
+}
+
+// Workers run in the background until the server stops.
+type Workers struct {
+	SavedSearchMatcher app.SavedSearchMatcher
+	ReminderScheduler  app.ReminderScheduler
+	MailQueue          app.MailQueue
+	WebhookDispatcher  app.WebhookDispatcher
+	StreamHub          app.StreamHub
 }
 
 func New(conf config.Configuration) Container {
@@ -44,23 +80,85 @@ func New(conf config.Configuration) Container {
 	userRepository := database.NewUserRepository(sess)
 	eventRepository := database.NewEventRepository(sess)
 	subscriptionRepository := database.NewSubscriptionRepository(sess)
+	ticketRepository := database.NewTicketRepository(sess)
+	categoryRepository := database.NewCategoryRepository(sess)
+	venueRepository := database.NewVenueRepository(sess)
+	savedSearchRepository := database.NewSavedSearchRepository(sess)
+	reminderRepository := database.NewReminderRepository(sess)
+	notificationRepository := database.NewNotificationRepository(sess)
+	mailRepository := database.NewMailRepository(sess)
+	pushDeviceRepository := database.NewPushDeviceRepository(sess)
+	announcementRepository := database.NewAnnouncementRepository(sess)
+	webhookRepository := database.NewWebhookRepository(sess)
+	streamRepository := database.NewStreamRepository(sess)
+	commentRepository := database.NewCommentRepository(sess)
+	reviewRepository := database.NewReviewRepository(sess)
+	eventRevisionRepository := database.NewEventRevisionRepository(sess)
 
 	userService := app.NewUserService(userRepository)
 	authService := app.NewAuthService(sessionRepository, userRepository, tknAuth, conf.JwtTTL)
-	eventService := app.NewEventService(eventRepository, subscriptionRepository)
+	mailService := app.NewMailService(mailRepository, getMailTemplates())
+	pushService := app.NewPushService(pushDeviceRepository, getPushSenders(conf))
+	streamService := app.NewStreamService(streamRepository)
+	notificationService := app.NewNotificationService(notificationRepository, userRepository, mailService, pushService, streamService)
+	ticketService := app.NewTicketService(ticketRepository, subscriptionRepository, conf.TicketSecret)
+	webhookSender := webhook.NewHttpSender()
+	webhookService := app.NewWebhookService(webhookRepository, webhookSender)
+	eventService := app.NewEventService(eventRepository, subscriptionRepository, categoryRepository, venueRepository, eventRevisionRepository, ticketService, notificationService, getGeocoder(conf), webhookService, streamService)
+	categoryService := app.NewCategoryService(categoryRepository)
+	venueService := app.NewVenueService(venueRepository)
+	announcementService := app.NewAnnouncementService(announcementRepository, subscriptionRepository, notificationService)
+	commentService := app.NewCommentService(commentRepository, eventRepository, notificationService)
+	reviewService := app.NewReviewService(reviewRepository, subscriptionRepository, eventRepository)
+	savedSearchService := app.NewSavedSearchService(savedSearchRepository, categoryRepository)
 	imageService := filesystem.NewImageStorageService(conf)
+	savedSearchMatcher := app.NewSavedSearchMatcher(eventRepository, savedSearchRepository, notificationService)
+	reminderScheduler := app.NewReminderScheduler(reminderRepository, notificationService, conf.ReminderOffsets)
+	mailQueue := app.NewMailQueue(mailRepository, getMailer(conf))
+	webhookDispatcher := app.NewWebhookDispatcher(webhookRepository, webhookSender)
+	streamHub := app.NewStreamHub(streamRepository, database.NewStreamListener(conf))
 
 	authController := controllers.NewAuthController(authService, userService)
 	userController := controllers.NewUserController(userService, authService, imageService)
 	eventController := controllers.NewEventController(eventService, imageService)
+	ticketController := controllers.NewTicketController(ticketService)
+	calendarController := controllers.NewCalendarController(eventService, userService)
+	categoryController := controllers.NewCategoryController(categoryService)
+	venueController := controllers.NewVenueController(venueService, eventService, imageService)
+	savedSearchController := controllers.NewSavedSearchController(savedSearchService, eventService)
+	notificationController := controllers.NewNotificationController(notificationService)
+	pushDeviceController := controllers.NewPushDeviceController(pushService)
+	announcementController := controllers.NewAnnouncementController(announcementService)
+	webhookController := controllers.NewWebhookController(webhookService)
+	streamController := controllers.NewStreamController(streamService, streamHub)
+	commentController := controllers.NewCommentController(commentService)
+	reviewController := controllers.NewReviewController(reviewService)
 
 	authMiddleware := middlewares.AuthMiddleware(tknAuth, authService, userService)
+	streamAuthMiddleware := middlewares.AuthMiddleware(tknAuth, authService, userService, jwtauth.TokenFromHeader, jwtauth.TokenFromQuery)
 	pathObjMiddleware := middlewares.PathObject("eventId", controllers.EventKey, eventService)
+	categoryPathMiddleware := middlewares.PathObject("categoryId", controllers.CategoryKey, categoryService)
+	venuePathMiddleware := middlewares.PathObject("venueId", controllers.VenueKey, venueService)
+	savedSearchPathMiddleware := middlewares.PathObject("savedSearchId", controllers.SavedSearchKey, savedSearchService)
+	notificationPathMiddleware := middlewares.PathObject("notificationId", controllers.NotificationKey, notificationService)
+	pushDevicePathMiddleware := middlewares.PathObject("pushDeviceId", controllers.PushDeviceKey, pushService)
+	webhookPathMiddleware := middlewares.PathObject("webhookId", controllers.WebhookKey, webhookService)
+	commentPathMiddleware := middlewares.PathObject("commentId", controllers.CommentKey, commentService)
+	reviewPathMiddleware := middlewares.PathObject("reviewId", controllers.ReviewKey, reviewService)
 
 	return Container{
 		Middlewares: Middlewares{
-			AuthMw: authMiddleware,
-			PathMw: pathObjMiddleware,
+			AuthMw:             authMiddleware,
+			StreamAuthMw:       streamAuthMiddleware,
+			PathMw:             pathObjMiddleware,
+			CategoryPathMw:     categoryPathMiddleware,
+			VenuePathMw:        venuePathMiddleware,
+			SavedSearchPathMw:  savedSearchPathMiddleware,
+			NotificationPathMw: notificationPathMiddleware,
+			PushDevicePathMw:   pushDevicePathMiddleware,
+			WebhookPathMw:      webhookPathMiddleware,
+			CommentPathMw:      commentPathMiddleware,
+			ReviewPathMw:       reviewPathMiddleware,
 		},
 		Services: Services{
 			authService,
@@ -70,10 +168,103 @@ func New(conf config.Configuration) Container {
 			authController,
 			userController,
 			eventController,
+			ticketController,
+			calendarController,
+			categoryController,
+			venueController,
+			savedSearchController,
+			notificationController,
+			pushDeviceController,
+			announcementController,
+			webhookController,
+			streamController,
+			commentController,
+			reviewController,
+		},
+		Workers: Workers{
+			SavedSearchMatcher: savedSearchMatcher,
+			ReminderScheduler:  reminderScheduler,
+			MailQueue:          mailQueue,
+			WebhookDispatcher:  webhookDispatcher,
+			StreamHub:          streamHub,
 		},
 	}
 }
 
+func getGeocoder(conf config.Configuration) app.Geocoder {
+	if conf.Geocoder == "http" {
+		return geocoding.NewHttpGeocoder(conf.GeocoderUrl)
+	}
+
+	gazetteer, err := geocoding.NewGazetteer(conf.GeocoderCitiesFile)
+	if err != nil {
+		log.Fatalf("Unable to load geocoder cities: %q\n", err)
+	}
+	return gazetteer
+}
+
+func getMailer(conf config.Configuration) app.Mailer {
+	switch conf.MailDriver {
+	case "smtp":
+		return mail.NewSmtpMailer(conf.SmtpAddr, conf.MailFrom, conf.SmtpUser, conf.SmtpPassword)
+	case "file":
+		outbox, err := mail.NewOutboxMailer(conf.MailOutboxLocation, conf.MailFrom)
+		if err != nil {
+			log.Fatalf("Unable to create mail outbox: %q\n", err)
+		}
+		return outbox
+	case "log":
+		return mail.NewLogMailer()
+	}
+	log.Fatalf("Unknown mail driver: %q\n", conf.MailDriver)
+	return nil
+}
+
+func getMailTemplates() app.MailTemplates {
+	templates, err := mail.NewTemplates()
+	if err != nil {
+		log.Fatalf("Unable to load mail templates: %q\n", err)
+	}
+	return templates
+}
+
+// getPushSenders sends every push to the fake sender unless the driver is "live", then
+// only the platforms with the provider credentials set are supported.
+func getPushSenders(conf config.Configuration) map[domain.PushPlatform]app.PushSender {
+	if conf.PushDriver != "live" {
+		fake := push.NewFakeSender()
+		return map[domain.PushPlatform]app.PushSender{
+			domain.WebPushPlatform:     fake,
+			domain.AndroidPushPlatform: fake,
+			domain.IosPushPlatform:     fake,
+		}
+	}
+
+	senders := make(map[domain.PushPlatform]app.PushSender)
+	if conf.VapidPrivateKey != "" {
+		webPush, err := push.NewWebPushSender(conf.VapidPublicKey, conf.VapidPrivateKey, conf.VapidSubject)
+		if err != nil {
+			log.Fatalf("Unable to create Web Push sender: %q\n", err)
+		}
+		senders[domain.WebPushPlatform] = webPush
+	}
+	if conf.FcmCredentialsFile != "" {
+		fcm, err := push.NewFcmSender(conf.FcmCredentialsFile)
+		if err != nil {
+			log.Fatalf("Unable to create FCM sender: %q\n", err)
+		}
+		senders[domain.AndroidPushPlatform] = fcm
+	}
+	if conf.ApnsKeyFile != "" {
+		apns, err := push.NewApnsSender(conf.ApnsKeyFile, conf.ApnsKeyId, conf.ApnsTeamId, conf.ApnsTopic, conf.ApnsProduction)
+		if err != nil {
+			log.Fatalf("Unable to create APNs sender: %q\n", err)
+		}
+		senders[domain.IosPushPlatform] = apns
+	}
+	return senders
+}
+
 func getDbSess(conf config.Configuration) db.Session {
 	sess, err := postgresql.Open(
 		postgresql.ConnectionURL{
@@ -0,0 +1,127 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/database"
+	"log"
+	"time"
+)
+
+const (
+	// maxAnnouncements can be made for an event per announcementWindow
+	maxAnnouncements   = 3
+	announcementWindow = 24 * time.Hour
+)
+
+var (
+	ErrTooManyAnnouncements = fmt.Errorf("an event can't have more than %d announcements a day", maxAnnouncements)
+	ErrEventNotAnnounceable = errors.New("announcements can't be made for cancelled or finished events")
+)
+
+type AnnouncementService interface {
+	Announce(event domain.Event, a domain.Announcement) (domain.Announcement, error)
+	FindByEvent(eventId uint64, p domain.Pagination) ([]domain.Announcement, uint64, error)
+	CanRead(event domain.Event, user domain.User) (bool, error)
+}
+
+type announcementService struct {
+	announcementRepo database.AnnouncementRepository
+	subscriptionRepo database.SubscriptionRepository
+	notifier         NotificationService
+}
+
+func NewAnnouncementService(ar database.AnnouncementRepository, sr database.SubscriptionRepository, n NotificationService) AnnouncementService {
+	return announcementService{
+		announcementRepo: ar,
+		subscriptionRepo: sr,
+		notifier:         n,
+	}
+}
+
+// Announce stores the announcement and sends it to the subscribers of the event who have
+// not declined it, through the chosen channel. Email is the default.
+func (s announcementService) Announce(event domain.Event, a domain.Announcement) (domain.Announcement, error) {
+	if event.Status != domain.NewEventStatus {
+		return domain.Announcement{}, ErrEventNotAnnounceable
+	}
+
+	subscribers, err := s.subscriptionRepo.FindSubscriberIds(event.Id)
+	if err != nil {
+		log.Printf("AnnouncementService -> Announce -> s.subscriptionRepo.FindSubscriberIds: %s", err)
+		return domain.Announcement{}, err
+	}
+	userIds := make([]uint64, 0, len(subscribers))
+	for _, userId := range subscribers {
+		if userId != event.UserId {
+			userIds = append(userIds, userId)
+		}
+	}
+
+	if a.Channel == "" {
+		a.Channel = domain.EmailNotificationChannel
+	}
+	a.EventId = event.Id
+	a.Recipients = uint64(len(userIds))
+	now := time.Now()
+	a, saved, err := s.announcementRepo.SaveLimited(a, now.Add(-announcementWindow), maxAnnouncements)
+	if err != nil {
+		log.Printf("AnnouncementService -> Announce -> s.announcementRepo.SaveLimited: %s", err)
+		return domain.Announcement{}, err
+	}
+	if !saved {
+		return domain.Announcement{}, s.tooManyAnnouncements(event.Id, now)
+	}
+
+	err = s.notifier.NotifyAll(userIds, domain.Notification{
+		EventId: event.Id,
+		Type:    domain.EventAnnouncementNotification,
+		Channel: a.Channel,
+		Title:   fmt.Sprintf("%s: %s", event.Title, a.Title),
+		Body:    a.Body,
+	})
+	if err != nil {
+		log.Printf("AnnouncementService -> Announce -> s.notifier.NotifyAll: %s", err)
+	}
+	return a, nil
+}
+
+// tooManyAnnouncements tells when the oldest of the announcements counted against the limit
+// drops out of the window.
+func (s announcementService) tooManyAnnouncements(eventId uint64, now time.Time) error {
+	recent, err := s.announcementRepo.FindSince(eventId, now.Add(-announcementWindow))
+	if err != nil {
+		log.Printf("AnnouncementService -> tooManyAnnouncements -> s.announcementRepo.FindSince: %s", err)
+		return ErrTooManyAnnouncements
+	}
+	if len(recent) < maxAnnouncements {
+		return ErrTooManyAnnouncements
+	}
+	retry := recent[len(recent)-maxAnnouncements].CreatedDate.Add(announcementWindow).Sub(now)
+	return fmt.Errorf("%w, try again in %s", ErrTooManyAnnouncements, retry.Round(time.Minute))
+}
+
+func (s announcementService) FindByEvent(eventId uint64, p domain.Pagination) ([]domain.Announcement, uint64, error) {
+	announcements, total, err := s.announcementRepo.FindByEvent(eventId, p)
+	if err != nil {
+		log.Printf("AnnouncementService -> FindByEvent -> s.announcementRepo.FindByEvent: %s", err)
+		return nil, 0, err
+	}
+	return announcements, total, nil
+}
+
+// CanRead tells whether the user may see the announcements of the event, which is the case
+// for its owner, the admins and everyone subscribed, including the waitlist and those who declined.
+func (s announcementService) CanRead(event domain.Event, user domain.User) (bool, error) {
+	if event.UserId == user.Id || user.Role == domain.AdminRole {
+		return true, nil
+	}
+
+	subscribed, err := s.subscriptionRepo.IsSubscribed(event.Id, user.Id)
+	if err != nil {
+		log.Printf("AnnouncementService -> CanRead -> s.subscriptionRepo.IsSubscribed: %s", err)
+		return false, err
+	}
+	return subscribed, nil
+}
@@ -0,0 +1,143 @@
+package app
+
+import (
+	"errors"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/database"
+	"github.com/upper/db/v4"
+	"log"
+	"regexp"
+	"strings"
+)
+
+var (
+	ErrCategoryNotFound    = errors.New("category not found")
+	ErrInvalidCategorySlug = errors.New("category slug must contain letters or digits")
+	ErrCategorySlugTaken   = errors.New("category slug is already taken")
+	ErrCategoryCycle       = errors.New("category can't be moved under itself or its subcategories")
+	ErrCategoryHasChildren = errors.New("category has subcategories")
+)
+
+var slugSeparators = regexp.MustCompile(`[^\p{L}\p{N}]+`)
+
+type CategoryService interface {
+	Save(c domain.Category) (domain.Category, error)
+	Update(c domain.Category) (domain.Category, error)
+	Find(id uint64) (interface{}, error)
+	FindAll() ([]domain.Category, error)
+	Delete(id uint64) error
+}
+
+type categoryService struct {
+	categoryRepo database.CategoryRepository
+}
+
+func NewCategoryService(cr database.CategoryRepository) CategoryService {
+	return categoryService{
+		categoryRepo: cr,
+	}
+}
+
+func (s categoryService) Save(c domain.Category) (domain.Category, error) {
+	err := s.prepare(&c)
+	if err != nil {
+		return domain.Category{}, err
+	}
+
+	c, err = s.categoryRepo.Save(c)
+	if err != nil {
+		log.Printf("CategoryService -> Save -> s.categoryRepo.Save: %s", err)
+		return domain.Category{}, err
+	}
+	return c, nil
+}
+
+func (s categoryService) Update(c domain.Category) (domain.Category, error) {
+	err := s.prepare(&c)
+	if err != nil {
+		return domain.Category{}, err
+	}
+
+	c, err = s.categoryRepo.Update(c)
+	if err != nil {
+		log.Printf("CategoryService -> Update -> s.categoryRepo.Update: %s", err)
+		return domain.Category{}, err
+	}
+	return c, nil
+}
+
+func (s categoryService) Find(id uint64) (interface{}, error) {
+	c, err := s.categoryRepo.Find(id)
+	if err != nil {
+		log.Printf("CategoryService -> Find -> s.categoryRepo.Find: %s", err)
+		return nil, err
+	}
+	return c, nil
+}
+
+func (s categoryService) FindAll() ([]domain.Category, error) {
+	categories, err := s.categoryRepo.FindAll()
+	if err != nil {
+		log.Printf("CategoryService -> FindAll -> s.categoryRepo.FindAll: %s", err)
+		return nil, err
+	}
+	return categories, nil
+}
+
+// Delete removes a category without subcategories, its events are left uncategorized.
+func (s categoryService) Delete(id uint64) error {
+	children, err := s.categoryRepo.CountChildren(id)
+	if err != nil {
+		log.Printf("CategoryService -> Delete -> s.categoryRepo.CountChildren: %s", err)
+		return err
+	}
+	if children > 0 {
+		return ErrCategoryHasChildren
+	}
+
+	err = s.categoryRepo.Delete(id)
+	if err != nil {
+		log.Printf("CategoryService -> Delete -> s.categoryRepo.Delete: %s", err)
+		return err
+	}
+	return nil
+}
+
+// prepare fills in the slug and checks that it is unique and that the parent exists
+// and is not a subcategory of c.
+func (s categoryService) prepare(c *domain.Category) error {
+	if c.Slug == "" {
+		c.Slug = slugify(c.Name)
+	}
+	if c.Slug == "" {
+		return ErrInvalidCategorySlug
+	}
+
+	existing, err := s.categoryRepo.FindBySlug(c.Slug)
+	if err == nil && existing.Id != c.Id {
+		return ErrCategorySlugTaken
+	} else if err != nil && !errors.Is(err, db.ErrNoMoreRows) {
+		log.Printf("CategoryService -> prepare -> s.categoryRepo.FindBySlug: %s", err)
+		return err
+	}
+
+	for parentId := c.ParentId; parentId != nil; {
+		if c.Id != 0 && *parentId == c.Id {
+			return ErrCategoryCycle
+		}
+		parent, err := s.categoryRepo.Find(*parentId)
+		if errors.Is(err, db.ErrNoMoreRows) {
+			return ErrCategoryNotFound
+		} else if err != nil {
+			log.Printf("CategoryService -> prepare -> s.categoryRepo.Find: %s", err)
+			return err
+		}
+		parentId = parent.ParentId
+	}
+
+	return nil
+}
+
+func slugify(name string) string {
+	return strings.Trim(slugSeparators.ReplaceAllString(strings.ToLower(name), "-"), "-")
+}
@@ -0,0 +1,138 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/database"
+	"log"
+)
+
+var ErrInvalidCommentParent = errors.New("replies can be made only to the top-level comments of the same event")
+
+type CommentService interface {
+	Save(event domain.Event, c domain.Comment) (domain.Comment, error)
+	Find(id uint64) (interface{}, error)
+	FindByEvent(eventId uint64, p domain.Pagination) ([]domain.Comment, uint64, error)
+	Update(c domain.Comment) (domain.Comment, error)
+	Delete(id uint64) error
+	CanDelete(c domain.Comment, user domain.User) (bool, error)
+}
+
+type commentService struct {
+	commentRepo database.CommentRepository
+	eventRepo   database.EventRepository
+	notifier    NotificationService
+}
+
+func NewCommentService(cr database.CommentRepository, er database.EventRepository, n NotificationService) CommentService {
+	return commentService{
+		commentRepo: cr,
+		eventRepo:   er,
+		notifier:    n,
+	}
+}
+
+// Save adds the comment to the event and lets the owner know, unless it is their own.
+func (s commentService) Save(event domain.Event, c domain.Comment) (domain.Comment, error) {
+	if c.ParentId != nil {
+		parent, err := s.commentRepo.Find(*c.ParentId)
+		if err != nil {
+			// an unknown parent is a bad request as well
+			log.Printf("CommentService -> Save -> s.commentRepo.Find: %s", err)
+			return domain.Comment{}, ErrInvalidCommentParent
+		}
+		if parent.EventId != event.Id || parent.ParentId != nil {
+			return domain.Comment{}, ErrInvalidCommentParent
+		}
+	}
+
+	c.EventId = event.Id
+	c, err := s.commentRepo.Save(c)
+	if err != nil {
+		log.Printf("CommentService -> Save -> s.commentRepo.Save: %s", err)
+		return domain.Comment{}, err
+	}
+
+	if c.UserId != event.UserId {
+		err = s.notifier.Notify(domain.Notification{
+			UserId:  event.UserId,
+			EventId: event.Id,
+			Type:    domain.NewCommentNotification,
+			Title:   fmt.Sprintf("%s %s commented on \"%s\"", c.Author.FirstName, c.Author.SecondName, event.Title),
+			Body:    c.Body,
+		})
+		if err != nil {
+			log.Printf("CommentService -> Save -> s.notifier.Notify: %s", err)
+		}
+	}
+	return c, nil
+}
+
+func (s commentService) Find(id uint64) (interface{}, error) {
+	c, err := s.commentRepo.Find(id)
+	if err != nil {
+		log.Printf("CommentService -> Find -> s.commentRepo.Find: %s", err)
+		return nil, err
+	}
+	return c, nil
+}
+
+// FindByEvent returns a page of the top-level comments of the event with all their replies.
+func (s commentService) FindByEvent(eventId uint64, p domain.Pagination) ([]domain.Comment, uint64, error) {
+	comments, total, err := s.commentRepo.FindByEvent(eventId, p)
+	if err != nil {
+		log.Printf("CommentService -> FindByEvent -> s.commentRepo.FindByEvent: %s", err)
+		return nil, 0, err
+	}
+
+	ids := make([]uint64, len(comments))
+	byId := make(map[uint64]int, len(comments))
+	for i, c := range comments {
+		ids[i] = c.Id
+		byId[c.Id] = i
+	}
+	replies, err := s.commentRepo.FindReplies(ids)
+	if err != nil {
+		log.Printf("CommentService -> FindByEvent -> s.commentRepo.FindReplies: %s", err)
+		return nil, 0, err
+	}
+	for _, reply := range replies {
+		i := byId[*reply.ParentId]
+		comments[i].Replies = append(comments[i].Replies, reply)
+	}
+	return comments, total, nil
+}
+
+func (s commentService) Update(c domain.Comment) (domain.Comment, error) {
+	c, err := s.commentRepo.Update(c)
+	if err != nil {
+		log.Printf("CommentService -> Update -> s.commentRepo.Update: %s", err)
+		return domain.Comment{}, err
+	}
+	return c, nil
+}
+
+func (s commentService) Delete(id uint64) error {
+	err := s.commentRepo.Delete(id)
+	if err != nil {
+		log.Printf("CommentService -> Delete -> s.commentRepo.Delete: %s", err)
+		return err
+	}
+	return nil
+}
+
+// CanDelete tells whether the user may delete the comment, which is the case for its author,
+// the owner of the event and the admins.
+func (s commentService) CanDelete(c domain.Comment, user domain.User) (bool, error) {
+	if c.UserId == user.Id || user.Role == domain.AdminRole {
+		return true, nil
+	}
+
+	evn, err := s.eventRepo.Find(c.EventId)
+	if err != nil {
+		log.Printf("CommentService -> CanDelete -> s.eventRepo.Find: %s", err)
+		return false, err
+	}
+	return evn.(domain.Event).UserId == user.Id, nil
+}
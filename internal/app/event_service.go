@@ -1,54 +1,169 @@
 package app
 
 import (
+	"errors"
+	"fmt"
 	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
 	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/database"
+	"github.com/upper/db/v4"
 	"log"
+	"strings"
 	"time"
+	"unicode/utf8"
+)
+
+const (
+	maxTags = 10
+
+	// maxLocationMismatchKm is how far from the center of their city events may be
+	// before they are flagged
+	maxLocationMismatchKm = 50
+
+	// the imported events are held to the same limits as the ones created through the API
+	maxImportedTitleLength       = 80
+	maxImportedDescriptionLength = 200
+	maxImportedLocationLength    = 120
+
+	// MaxClusteredZoom is the last map zoom level where events are grouped into clusters
+	MaxClusteredZoom       = 13
+	mapClusterCellsPerTile = 4
+	// maxMapEvents limits the events returned at the zoom levels without clustering
+	maxMapEvents = 500
+)
+
+var (
+	ErrTooManyTags      = fmt.Errorf("an event can't have more than %d tags", maxTags)
+	ErrLocationNotFound = errors.New("location not found, send the lat and lon of the event")
+	ErrEventNotUpcoming = errors.New("only upcoming events can be cancelled")
 )
 
 type EventService interface {
 	Save(event domain.Event) (domain.Event, error)
 	Update(event domain.Event) (domain.Event, error)
 	Find(id uint64) (interface{}, error)
-	FindAll() ([]domain.Event, error)
 	Delete(id uint64) error
-	SubscribeToEvent(eventId, userId uint64) error
-	GetUserSubscriptions(userId uint64) ([]domain.Event, error)
+	Cancel(event domain.Event) (domain.Event, error)
+	SubscribeToEvent(eventId, userId uint64, rsvp domain.RsvpStatus) (domain.Subscription, error)
+	UnsubscribeFromEvent(eventId, userId uint64) error
+	FindAttendees(eventId uint64, p domain.Pagination) ([]domain.Attendee, uint64, domain.AttendeeCounts, error)
+	GetUserSubscriptions(userId uint64, filters database.UrlFilters, p domain.Pagination) ([]domain.Event, uint64, error)
+	GetUserCalendar(userId uint64) ([]domain.Event, error)
 	FindEventsByDate(date time.Time) ([]domain.Event, error)
-	FindEventsGroupByDate() (map[string][]domain.Event, error)
-	FindList(filters database.UrlFilters) ([]domain.Event, error)
+	FindCalendar(filters database.UrlFilters, loc *time.Location, perDay uint) ([]domain.CalendarDay, error)
+	FindList(filters database.UrlFilters, p domain.Pagination) ([]domain.Event, uint64, error)
+	FindFacets(filters database.UrlFilters) (domain.EventFacets, error)
+	FindMap(filters database.UrlFilters, zoom uint) ([]domain.EventCluster, []domain.Event, error)
+	Suggest(q string, limit uint) (domain.EventSuggestions, error)
+	ImportEvents(userId uint64, imports []domain.EventImport) []domain.EventImportResult
+	FindHistory(eventId uint64, p domain.Pagination) ([]domain.EventRevision, uint64, error)
+	RelocateVenueEvents(venue domain.Venue, userId uint64) ([]uint64, error)
 }
 
 type eventService struct {
 	subscriptionRepo database.SubscriptionRepository
 	eventRepo        database.EventRepository
+	categoryRepo     database.CategoryRepository
+	venueRepo        database.VenueRepository
+	revisionRepo     database.EventRevisionRepository
+	ticketService    TicketService
+	notifier         NotificationService
+	geocoder         Geocoder
+	webhooks         WebhookService
+	stream           StreamService
 }
 
-func NewEventService(ev database.EventRepository, sb database.SubscriptionRepository) EventService {
+func NewEventService(ev database.EventRepository, sb database.SubscriptionRepository, cr database.CategoryRepository, vr database.VenueRepository, rr database.EventRevisionRepository, ts TicketService, n NotificationService, g Geocoder, wh WebhookService, st StreamService) EventService {
 	return eventService{
 		subscriptionRepo: sb,
 		eventRepo:        ev,
+		categoryRepo:     cr,
+		venueRepo:        vr,
+		revisionRepo:     rr,
+		ticketService:    ts,
+		notifier:         n,
+		geocoder:         g,
+		webhooks:         wh,
+		stream:           st,
 	}
 }
 
 func (s eventService) Save(e domain.Event) (domain.Event, error) {
+	e, err := s.classify(e)
+	if err != nil {
+		return domain.Event{}, err
+	}
+	e, err = s.locate(e)
+	if err != nil {
+		return domain.Event{}, err
+	}
+
 	evn, err := s.eventRepo.Save(e)
 	if err != nil {
 		log.Printf("EventService -> Save -> s.eventRepo.Save: %s", err)
 		return domain.Event{}, err
 	}
+	s.webhooks.Dispatch(domain.EventCreatedWebhook, evn.UserId, eventWebhookData(evn))
 	return evn, nil
 }
 func (s eventService) Update(event domain.Event) (domain.Event, error) {
-	event, err := s.eventRepo.Update(event)
+	event, err := s.classify(event)
+	if err != nil {
+		return domain.Event{}, err
+	}
+	event, err = s.locate(event)
+	if err != nil {
+		return domain.Event{}, err
+	}
+
+	event, previous, err := s.eventRepo.Update(event)
 	if err != nil {
 		log.Printf("Event service -> Update -> s.eventRepo.Update(event): %s", err)
 		return domain.Event{}, err
 	}
+	s.onUpdated(previous, event)
+	s.webhooks.Dispatch(domain.EventUpdatedWebhook, event.UserId, eventWebhookData(event))
+
+	// capacity may have been raised
+	promoted, err := s.subscriptionRepo.FillFromWaitlist(event.Id)
+	if err != nil {
+		log.Printf("Event service -> Update -> s.subscriptionRepo.FillFromWaitlist(event.Id): %s", err)
+		return domain.Event{}, err
+	}
+	s.onPromoted(event, promoted)
+	if len(promoted) > 0 {
+		s.publishAttendees(event)
+	}
 
 	return event, nil
 }
+
+// RelocateVenueEvents moves the upcoming events of the venue to its current place. They
+// are updated one by one as if by the user, so each gets a revision and its subscribers
+// are told about the new place. Past events keep the place they took place at, and so do
+// the events of other organizers. An event that can't be moved doesn't stop the others,
+// the ids of such events are returned.
+func (s eventService) RelocateVenueEvents(venue domain.Venue, userId uint64) ([]uint64, error) {
+	events, err := s.eventRepo.FindUpcomingByVenue(venue.Id)
+	if err != nil {
+		log.Printf("EventService -> RelocateVenueEvents -> s.eventRepo.FindUpcomingByVenue: %s", err)
+		return nil, err
+	}
+
+	var failed []uint64
+	for _, e := range events {
+		if e.UserId != venue.UserId {
+			continue
+		}
+		e.UpdatedBy = userId
+		_, err = s.Update(e)
+		if err != nil {
+			log.Printf("EventService -> RelocateVenueEvents -> s.Update(%d): %s", e.Id, err)
+			failed = append(failed, e.Id)
+		}
+	}
+	return failed, nil
+}
+
 func (s eventService) Find(id uint64) (interface{}, error) {
 	event, err := s.eventRepo.Find(id)
 	if err != nil {
@@ -58,36 +173,137 @@ func (s eventService) Find(id uint64) (interface{}, error) {
 
 	return event, nil
 }
-func (s eventService) FindAll() ([]domain.Event, error) {
-	events, err := s.eventRepo.FindAll()
+func (s eventService) Delete(id uint64) error {
+	evn, err := s.eventRepo.Find(id)
 	if err != nil {
-		log.Printf("Event service -> FindAll -> events, s.eventRepo.FindAll(): %s", err)
-		return nil, err
+		log.Printf("EventService -> Delete -> s.eventRepo.Find(id): %s", err)
+		return err
 	}
-	return events, nil
-}
-func (s eventService) Delete(id uint64) error {
-	err := s.eventRepo.Delete(id)
+	event := evn.(domain.Event)
+
+	err = s.eventRepo.Delete(id)
 	if err != nil {
 		log.Printf("EventService -> Delete -> s.eventRepo.Delete(id): %s", err)
 		return err
 	}
+	s.webhooks.Dispatch(domain.EventDeletedWebhook, event.UserId, eventWebhookData(event))
 
 	return nil
 }
-func (s eventService) SubscribeToEvent(eventId, userId uint64) error {
+
+// Cancel keeps the event listed as cancelled, its pending reminders are not sent.
+func (s eventService) Cancel(event domain.Event) (domain.Event, error) {
+	if !event.IsUpcoming(time.Now()) {
+		return domain.Event{}, ErrEventNotUpcoming
+	}
+
+	event.Status = domain.CancelledEventStatus
+	event, _, err := s.eventRepo.Update(event)
+	if err != nil {
+		log.Printf("EventService -> Cancel -> s.eventRepo.Update: %s", err)
+		return domain.Event{}, err
+	}
+	s.notifySubscribers(event, domain.Notification{
+		Type:  domain.EventCancelledNotification,
+		Title: fmt.Sprintf("\"%s\" has been cancelled", event.Title),
+	})
+	s.publish(event, domain.EventCancelledStreamEvent, streamEventData(event, nil))
+	s.webhooks.Dispatch(domain.EventUpdatedWebhook, event.UserId, eventWebhookData(event))
+	return event, nil
+}
+
+func (s eventService) SubscribeToEvent(eventId, userId uint64, rsvp domain.RsvpStatus) (domain.Subscription, error) {
 	// Проверяем, существует ли событие
-	_, err := s.eventRepo.Find(eventId)
+	evn, err := s.eventRepo.Find(eventId)
 	if err != nil {
-		return err
+		return domain.Subscription{}, err
 	}
 
 	// Добавляем подписку
-	return s.subscriptionRepo.Subscribe(eventId, userId)
+	sub, promoted, err := s.subscriptionRepo.Subscribe(eventId, userId, rsvp)
+	if err != nil {
+		log.Printf("EventService -> SubscribeToEvent -> s.subscriptionRepo.Subscribe: %s", err)
+		return domain.Subscription{}, err
+	}
+
+	if sub.Status == domain.ActiveSubscriptionStatus && sub.Rsvp != domain.DeclinedRsvpStatus {
+		_, err = s.ticketService.Issue(eventId, userId)
+	} else {
+		err = s.ticketService.Revoke(eventId, userId)
+	}
+	if err != nil {
+		return domain.Subscription{}, err
+	}
+	s.onPromoted(evn.(domain.Event), promoted)
+	s.publishAttendees(evn.(domain.Event))
+	s.webhooks.Dispatch(domain.SubscriptionCreatedWebhook, evn.(domain.Event).UserId, webhookSubscription{
+		EventId: eventId,
+		UserId:  userId,
+		Status:  sub.Status,
+		Rsvp:    sub.Rsvp,
+	})
+
+	return sub, nil
+}
+
+func (s eventService) UnsubscribeFromEvent(eventId, userId uint64) error {
+	evn, err := s.eventRepo.Find(eventId)
+	if err != nil {
+		return err
+	}
+
+	promoted, err := s.subscriptionRepo.Unsubscribe(eventId, userId)
+	if err != nil {
+		log.Printf("EventService -> UnsubscribeFromEvent -> s.subscriptionRepo.Unsubscribe: %s", err)
+		return err
+	}
+
+	err = s.ticketService.Revoke(eventId, userId)
+	if err != nil {
+		return err
+	}
+	s.onPromoted(evn.(domain.Event), promoted)
+	s.publishAttendees(evn.(domain.Event))
+	s.webhooks.Dispatch(domain.SubscriptionDeletedWebhook, evn.(domain.Event).UserId, webhookSubscription{
+		EventId: eventId,
+		UserId:  userId,
+	})
+
+	return nil
+}
+
+func (s eventService) FindAttendees(eventId uint64, p domain.Pagination) ([]domain.Attendee, uint64, domain.AttendeeCounts, error) {
+	attendees, total, err := s.subscriptionRepo.FindAttendees(eventId, p)
+	if err != nil {
+		log.Printf("EventService -> FindAttendees -> s.subscriptionRepo.FindAttendees: %s", err)
+		return nil, 0, domain.AttendeeCounts{}, err
+	}
+
+	counts, err := s.subscriptionRepo.CountAttendees(eventId)
+	if err != nil {
+		log.Printf("EventService -> FindAttendees -> s.subscriptionRepo.CountAttendees: %s", err)
+		return nil, 0, domain.AttendeeCounts{}, err
+	}
+
+	return attendees, total, counts, nil
 }
 
-func (s eventService) GetUserSubscriptions(userId uint64) ([]domain.Event, error) {
-	return s.subscriptionRepo.FindUserSubscriptions(userId)
+func (s eventService) GetUserSubscriptions(userId uint64, filters database.UrlFilters, p domain.Pagination) ([]domain.Event, uint64, error) {
+	filters.SubscriberId = userId
+	events, total, err := s.eventRepo.FindList(filters, p)
+	if err != nil {
+		log.Printf("EventService -> GetUserSubscriptions -> s.eventRepo.FindList: %s", err)
+		return nil, 0, err
+	}
+	return events, total, nil
+}
+func (s eventService) GetUserCalendar(userId uint64) ([]domain.Event, error) {
+	events, err := s.subscriptionRepo.FindUserCalendar(userId)
+	if err != nil {
+		log.Printf("EventService -> GetUserCalendar -> s.subscriptionRepo.FindUserCalendar: %s", err)
+		return nil, err
+	}
+	return events, nil
 }
 func (s eventService) FindEventsByDate(date time.Time) ([]domain.Event, error) {
 	_, err := s.eventRepo.FindEventsByDate(date)
@@ -96,14 +312,441 @@ func (s eventService) FindEventsByDate(date time.Time) ([]domain.Event, error) {
 	}
 	return s.eventRepo.FindEventsByDate(date)
 }
-func (s eventService) FindEventsGroupByDate() (map[string][]domain.Event, error) {
-	return s.eventRepo.FindEventsGroupByDate()
-}
-func (s eventService) FindList(filters database.UrlFilters) ([]domain.Event, error) {
-	events, err := s.eventRepo.FindList(filters)
+
+func (s eventService) FindCalendar(filters database.UrlFilters, loc *time.Location, perDay uint) ([]domain.CalendarDay, error) {
+	days, err := s.eventRepo.FindCalendar(filters, loc, perDay)
 	if err != nil {
+		log.Printf("EventService -> FindCalendar -> s.eventRepo.FindCalendar: %s", err)
 		return nil, err
 	}
+	return days, nil
+}
+func (s eventService) FindList(filters database.UrlFilters, p domain.Pagination) ([]domain.Event, uint64, error) {
+	events, total, err := s.eventRepo.FindList(filters, p)
+	if err != nil {
+		return nil, 0, err
+	}
 
-	return events, nil
+	return events, total, nil
+}
+
+func (s eventService) FindFacets(filters database.UrlFilters) (domain.EventFacets, error) {
+	facets, err := s.eventRepo.FindFacets(filters)
+	if err != nil {
+		log.Printf("EventService -> FindFacets -> s.eventRepo.FindFacets: %s", err)
+		return domain.EventFacets{}, err
+	}
+	return facets, nil
+}
+
+// FindMap groups the events into grid clusters sized for the zoom level, a 256px map tile
+// holds mapClusterCellsPerTile cells in each direction. Zoomed in past MaxClusteredZoom,
+// the events themselves are returned.
+func (s eventService) FindMap(filters database.UrlFilters, zoom uint) ([]domain.EventCluster, []domain.Event, error) {
+	if zoom > MaxClusteredZoom {
+		events, _, err := s.eventRepo.FindList(filters, domain.Pagination{Page: 1, CountPerPage: maxMapEvents})
+		if err != nil {
+			log.Printf("EventService -> FindMap -> s.eventRepo.FindList: %s", err)
+			return nil, nil, err
+		}
+		return nil, events, nil
+	}
+
+	cellDeg := 360 / float64(uint64(1)<<zoom*mapClusterCellsPerTile)
+	clusters, err := s.eventRepo.FindClusters(filters, cellDeg)
+	if err != nil {
+		log.Printf("EventService -> FindMap -> s.eventRepo.FindClusters: %s", err)
+		return nil, nil, err
+	}
+	return clusters, nil, nil
+}
+
+func (s eventService) Suggest(q string, limit uint) (domain.EventSuggestions, error) {
+	suggestions, err := s.eventRepo.Suggest(q, limit)
+	if err != nil {
+		log.Printf("EventService -> Suggest -> s.eventRepo.Suggest: %s", err)
+		return domain.EventSuggestions{}, err
+	}
+	return suggestions, nil
+}
+
+// FindHistory returns a page of the revisions of the event, the newest first.
+func (s eventService) FindHistory(eventId uint64, p domain.Pagination) ([]domain.EventRevision, uint64, error) {
+	revisions, total, err := s.revisionRepo.FindByEvent(eventId, p)
+	if err != nil {
+		log.Printf("EventService -> FindHistory -> s.revisionRepo.FindByEvent: %s", err)
+		return nil, 0, err
+	}
+	return revisions, total, nil
+}
+
+// ImportEvents creates the imported events under the user. Events imported before
+// are matched by UID and updated, or skipped when nothing has changed.
+func (s eventService) ImportEvents(userId uint64, imports []domain.EventImport) []domain.EventImportResult {
+	results := make([]domain.EventImportResult, len(imports))
+	for i, imp := range imports {
+		results[i] = s.importEvent(userId, imp)
+	}
+	return results
+}
+
+func (s eventService) importEvent(userId uint64, imp domain.EventImport) domain.EventImportResult {
+	e := imp.Event
+	e.Description = domain.SanitizeText(e.Description)
+	result := domain.EventImportResult{Uid: e.ExternalUid, Title: e.Title, Status: domain.SkippedEventImportStatus}
+	if imp.Error == nil {
+		imp.Error = validateImportedEvent(e)
+	}
+	if imp.Error != nil {
+		result.Error = imp.Error.Error()
+		return result
+	}
+
+	existing, err := s.eventRepo.FindByExternalUid(userId, e.ExternalUid)
+	if err != nil && !errors.Is(err, db.ErrNoMoreRows) {
+		log.Printf("EventService -> importEvent -> s.eventRepo.FindByExternalUid: %s", err)
+		result.Error = err.Error()
+		return result
+	}
+
+	if errors.Is(err, db.ErrNoMoreRows) {
+		e.UserId = userId
+		if e.Status == "" {
+			e.Status = domain.NewEventStatus
+		}
+		e, err = s.Save(e)
+		if err != nil {
+			result.Error = err.Error()
+			return result
+		}
+		result.EventId, result.Status = e.Id, domain.CreatedEventImportStatus
+		return result
+	}
+
+	result.EventId = existing.Id
+	if existing.DeletedDate != nil {
+		result.Error = "event was deleted"
+		return result
+	}
+
+	updated := existing
+	updated.Title = e.Title
+	updated.Description = e.Description
+	updated.Location = e.Location
+	updated.Date = e.Date
+	updated.EndDate = e.EndDate
+	updated.Lat, updated.Lon = e.Lat, e.Lon
+	updated.UpdatedBy = userId
+	if e.Status == domain.CancelledEventStatus {
+		updated.Status = e.Status
+	}
+	if updated.Location != existing.Location || updated.Lat != existing.Lat || updated.Lon != existing.Lon {
+		// the imported place wins over the venue
+		updated.VenueId = nil
+	}
+	if sameImportedFields(existing, updated) {
+		return result
+	}
+
+	_, err = s.Update(updated)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.Status = domain.UpdatedEventImportStatus
+	return result
+}
+
+func validateImportedEvent(e domain.Event) error {
+	if e.Title == "" {
+		return errors.New("SUMMARY is missing")
+	}
+	if utf8.RuneCountInString(e.Title) > maxImportedTitleLength {
+		return fmt.Errorf("SUMMARY is longer than %d characters", maxImportedTitleLength)
+	}
+	if utf8.RuneCountInString(e.Description) > maxImportedDescriptionLength {
+		return fmt.Errorf("DESCRIPTION is longer than %d characters", maxImportedDescriptionLength)
+	}
+	if utf8.RuneCountInString(e.Location) > maxImportedLocationLength {
+		return fmt.Errorf("LOCATION is longer than %d characters", maxImportedLocationLength)
+	}
+	return nil
+}
+
+func sameImportedFields(a, b domain.Event) bool {
+	sameEnd := (a.EndDate == nil && b.EndDate == nil) ||
+		(a.EndDate != nil && b.EndDate != nil && a.EndDate.Equal(*b.EndDate))
+	return a.Title == b.Title &&
+		a.Description == b.Description &&
+		a.Location == b.Location &&
+		a.Date.Equal(b.Date) &&
+		sameEnd &&
+		a.Lat == b.Lat && a.Lon == b.Lon &&
+		a.Status == b.Status
+}
+
+// classify checks the category of the event and normalizes its tags.
+func (s eventService) classify(e domain.Event) (domain.Event, error) {
+	if e.CategoryId != nil {
+		_, err := s.categoryRepo.Find(*e.CategoryId)
+		if errors.Is(err, db.ErrNoMoreRows) {
+			return domain.Event{}, ErrCategoryNotFound
+		} else if err != nil {
+			log.Printf("EventService -> classify -> s.categoryRepo.Find: %s", err)
+			return domain.Event{}, err
+		}
+	}
+
+	e.Tags = NormalizeTags(e.Tags)
+	if len(e.Tags) > maxTags {
+		return domain.Event{}, ErrTooManyTags
+	}
+	return e, nil
+}
+
+// locate copies the place of the event's venue to the legacy city, location and
+// coordinates fields, which the filters and searches work on.
+func (s eventService) locate(e domain.Event) (domain.Event, error) {
+	if e.VenueId == nil {
+		return s.geocode(e)
+	}
+
+	venue, err := s.venueRepo.Find(*e.VenueId)
+	if errors.Is(err, db.ErrNoMoreRows) {
+		return domain.Event{}, ErrVenueNotFound
+	} else if err != nil {
+		log.Printf("EventService -> locate -> s.venueRepo.Find: %s", err)
+		return domain.Event{}, err
+	}
+
+	e.City, e.Location = venue.City, venue.Name
+	e.Lat, e.Lon = venue.Lat, venue.Lon
+	e.LocationMismatch = false
+	return e, nil
+}
+
+// geocode fills in the coordinates of events sent without them, and the city of events
+// sent without it. Events whose coordinates are far from their city are flagged.
+// Geocoder failures only matter when the coordinates are missing.
+func (s eventService) geocode(e domain.Event) (domain.Event, error) {
+	e.LocationMismatch = false
+	point := domain.GeoPoint{Lat: e.Lat, Lon: e.Lon}
+
+	if e.Lat == 0 && e.Lon == 0 {
+		query := strings.Trim(e.Location+", "+e.City, ", ")
+		if query == "" {
+			return e, nil
+		}
+		place, err := s.geocoder.Forward(query)
+		if errors.Is(err, domain.ErrPlaceNotFound) {
+			return domain.Event{}, ErrLocationNotFound
+		} else if err != nil {
+			log.Printf("EventService -> geocode -> s.geocoder.Forward: %s", err)
+			return domain.Event{}, err
+		}
+		e.Lat, e.Lon = place.Point.Lat, place.Point.Lon
+		if e.City == "" {
+			e.City = place.City
+		}
+		return e, nil
+	}
+
+	if e.City == "" {
+		place, err := s.geocoder.Reverse(point)
+		if err == nil {
+			e.City = place.City
+		} else if !errors.Is(err, domain.ErrPlaceNotFound) {
+			log.Printf("EventService -> geocode -> s.geocoder.Reverse: %s", err)
+		}
+		return e, nil
+	}
+
+	place, err := s.geocoder.Forward(e.City)
+	if err == nil {
+		e.LocationMismatch = place.Point.DistanceKm(point) > maxLocationMismatchKm
+	} else if !errors.Is(err, domain.ErrPlaceNotFound) {
+		log.Printf("EventService -> geocode -> s.geocoder.Forward: %s", err)
+	}
+	return e, nil
+}
+
+// NormalizeTags lowercases and trims the tags, dropping empty ones and duplicates.
+func NormalizeTags(tags []string) []string {
+	var result []string
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		result = append(result, tag)
+	}
+	return result
+}
+
+// onUpdated lets the subscribers know about the cancellation of the event or the changes
+// of its details. Changes of the image and other settings are not notified.
+func (s eventService) onUpdated(previous, event domain.Event) {
+	if event.Status == domain.CancelledEventStatus && previous.Status != domain.CancelledEventStatus {
+		s.notifySubscribers(event, domain.Notification{
+			Type:  domain.EventCancelledNotification,
+			Title: fmt.Sprintf("\"%s\" has been cancelled", event.Title),
+		})
+		s.publish(event, domain.EventCancelledStreamEvent, streamEventData(event, nil))
+		return
+	}
+
+	changes := domain.DiffEvents(previous, event)
+	if len(changes) > 0 {
+		s.publish(event, domain.EventUpdatedStreamEvent, streamEventData(event, changedFields(changes)))
+	}
+	body := changesBody(changes)
+	if body == "" {
+		return
+	}
+	s.notifySubscribers(event, domain.Notification{
+		Type:  domain.EventUpdatedNotification,
+		Title: fmt.Sprintf("\"%s\" has been updated", event.Title),
+		Body:  body,
+	})
+}
+
+// notifySubscribers sends the notification to the subscribers of the event except its owner.
+func (s eventService) notifySubscribers(event domain.Event, n domain.Notification) {
+	subscribers, err := s.subscriptionRepo.FindSubscriberIds(event.Id)
+	if err != nil {
+		log.Printf("EventService -> notifySubscribers -> s.subscriptionRepo.FindSubscriberIds: %s", err)
+		return
+	}
+
+	userIds := make([]uint64, 0, len(subscribers))
+	for _, userId := range subscribers {
+		if userId != event.UserId {
+			userIds = append(userIds, userId)
+		}
+	}
+
+	n.EventId = event.Id
+	err = s.notifier.NotifyAll(userIds, n)
+	if err != nil {
+		log.Printf("EventService -> notifySubscribers -> s.notifier.NotifyAll: %s", err)
+	}
+}
+
+// publish pushes the change to the streams of the owner and the subscribers of the event.
+func (s eventService) publish(event domain.Event, t domain.StreamEventType, data interface{}) {
+	subscribers, err := s.subscriptionRepo.FindSubscriberIds(event.Id)
+	if err != nil {
+		log.Printf("EventService -> publish -> s.subscriptionRepo.FindSubscriberIds: %s", err)
+		return
+	}
+
+	userIds := []uint64{event.UserId}
+	for _, userId := range subscribers {
+		if userId != event.UserId {
+			userIds = append(userIds, userId)
+		}
+	}
+	s.stream.Publish(userIds, t, data)
+}
+
+// publishAttendees pushes the new attendee counts of the event.
+func (s eventService) publishAttendees(event domain.Event) {
+	counts, err := s.subscriptionRepo.CountAttendees(event.Id)
+	if err != nil {
+		log.Printf("EventService -> publishAttendees -> s.subscriptionRepo.CountAttendees: %s", err)
+		return
+	}
+
+	s.publish(event, domain.AttendeesStreamEvent, streamAttendees{
+		EventId:    event.Id,
+		Going:      counts.Going,
+		Maybe:      counts.Maybe,
+		Declined:   counts.Declined,
+		Waitlisted: counts.Waitlisted,
+	})
+}
+
+func streamEventData(event domain.Event, changes []string) streamEvent {
+	return streamEvent{
+		EventId: event.Id,
+		Title:   event.Title,
+		Status:  event.Status,
+		Date:    event.Date,
+		EndDate: event.EndDate,
+		Changes: changes,
+	}
+}
+
+// changesBody tells the subscribers what has changed, the significant changes with their
+// old and new values. Changes of the status are not told, cancellations are notified
+// on their own, neither are those of the image and other settings.
+func changesBody(changes []domain.EventChange) string {
+	labels := map[string]string{
+		"date":     "The time",
+		"endDate":  "The end time",
+		"location": "The place",
+		"city":     "The city",
+	}
+
+	var lines, others []string
+	for _, c := range changes {
+		if label, ok := labels[c.Field]; ok && c.Significant {
+			lines = append(lines, fmt.Sprintf("%s changed from %s to %s", label, changeValue(c.From), changeValue(c.To)))
+			continue
+		}
+		switch c.Field {
+		case "title", "description", "coordinates":
+			others = append(others, c.Field)
+		}
+	}
+	if len(others) > 0 {
+		if len(lines) == 0 {
+			lines = append(lines, "Changed: "+strings.Join(others, ", "))
+		} else {
+			lines = append(lines, "Also changed: "+strings.Join(others, ", "))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// changeValue makes the recorded value readable, the dates are recorded in RFC 3339.
+func changeValue(v string) string {
+	if v == "" {
+		return "none"
+	}
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t.UTC().Format(notificationDateFormat)
+	}
+	return fmt.Sprintf("\"%s\"", v)
+}
+
+func changedFields(changes []domain.EventChange) []string {
+	fields := make([]string, len(changes))
+	for i, c := range changes {
+		fields[i] = c.Field
+	}
+	return fields
+}
+
+// onPromoted issues tickets to the users moved from the waitlist and lets them know.
+func (s eventService) onPromoted(event domain.Event, promoted []domain.Subscription) {
+	for _, sub := range promoted {
+		_, err := s.ticketService.Issue(sub.EventId, sub.UserId)
+		if err != nil {
+			log.Printf("EventService -> onPromoted -> s.ticketService.Issue: %s", err)
+		}
+
+		err = s.notifier.Notify(domain.Notification{
+			UserId:  sub.UserId,
+			EventId: event.Id,
+			Type:    domain.WaitlistPromotedNotification,
+			Title:   fmt.Sprintf("You are in! A place at \"%s\" has opened up", event.Title),
+			Body:    "You have been moved from the waitlist to the attendee list.",
+		})
+		if err != nil {
+			log.Printf("EventService -> onPromoted -> s.notifier.Notify: %s", err)
+		}
+	}
 }
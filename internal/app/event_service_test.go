@@ -0,0 +1,291 @@
+package app
+
+import (
+	"errors"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/database"
+	"github.com/upper/db/v4"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidateImportedEvent(t *testing.T) {
+	tests := []struct {
+		name  string
+		event domain.Event
+		valid bool
+	}{
+		{"valid", domain.Event{Title: "Meetup", Description: "Talks"}, true},
+		{"no title", domain.Event{Description: "Talks"}, false},
+		{"title at the limit", domain.Event{Title: strings.Repeat("й", maxImportedTitleLength)}, true},
+		{"long title", domain.Event{Title: strings.Repeat("a", maxImportedTitleLength+1)}, false},
+		{"long description", domain.Event{Title: "Meetup", Description: strings.Repeat("a", maxImportedDescriptionLength+1)}, false},
+		{"long location", domain.Event{Title: "Meetup", Location: strings.Repeat("a", maxImportedLocationLength+1)}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateImportedEvent(tt.event)
+			if (err == nil) != tt.valid {
+				t.Errorf("validateImportedEvent() = %v, want valid %t", err, tt.valid)
+			}
+		})
+	}
+}
+
+type fakeEventRepo struct {
+	database.EventRepository
+	events  map[uint64]domain.Event
+	failing map[uint64]bool
+	updated []domain.Event
+}
+
+func (r *fakeEventRepo) Find(id uint64) (interface{}, error) {
+	e, ok := r.events[id]
+	if !ok {
+		return nil, db.ErrNoMoreRows
+	}
+	return e, nil
+}
+
+func (r *fakeEventRepo) Update(e domain.Event) (domain.Event, domain.Event, error) {
+	previous, ok := r.events[e.Id]
+	if !ok {
+		return domain.Event{}, domain.Event{}, db.ErrNoMoreRows
+	}
+	if r.failing[e.Id] {
+		return domain.Event{}, domain.Event{}, errors.New("connection reset")
+	}
+	r.events[e.Id] = e
+	r.updated = append(r.updated, e)
+	return e, previous, nil
+}
+
+func (r *fakeEventRepo) FindUpcomingByVenue(venueId uint64) ([]domain.Event, error) {
+	var events []domain.Event
+	for _, e := range r.events {
+		if e.VenueId != nil && *e.VenueId == venueId {
+			events = append(events, e)
+		}
+	}
+	return events, nil
+}
+
+type fakeVenueRepo struct {
+	database.VenueRepository
+	venue domain.Venue
+}
+
+func (r fakeVenueRepo) Find(id uint64) (domain.Venue, error) {
+	if id != r.venue.Id {
+		return domain.Venue{}, db.ErrNoMoreRows
+	}
+	return r.venue, nil
+}
+
+type fakeSubscriptionRepo struct {
+	database.SubscriptionRepository
+	subscribers []uint64
+}
+
+func (r fakeSubscriptionRepo) FindSubscriberIds(eventId uint64) ([]uint64, error) {
+	return r.subscribers, nil
+}
+
+func (r fakeSubscriptionRepo) FillFromWaitlist(eventId uint64) ([]domain.Subscription, error) {
+	return nil, nil
+}
+
+type fakeNotifier struct {
+	NotificationService
+	sent []domain.Notification
+}
+
+func (n *fakeNotifier) NotifyAll(userIds []uint64, notification domain.Notification) error {
+	for _, userId := range userIds {
+		notification.UserId = userId
+		n.sent = append(n.sent, notification)
+	}
+	return nil
+}
+
+type fakeStream struct {
+	StreamService
+}
+
+func (fakeStream) Publish(userIds []uint64, t domain.StreamEventType, data interface{}) {}
+
+type fakeWebhooks struct {
+	WebhookService
+}
+
+func (fakeWebhooks) Dispatch(t domain.WebhookEventType, ownerId uint64, data interface{}) {}
+
+type fakeGeocoder struct {
+	Geocoder
+	places map[string]domain.GeoPlace
+}
+
+func (g fakeGeocoder) Forward(query string) (domain.GeoPlace, error) {
+	place, ok := g.places[query]
+	if !ok {
+		return domain.GeoPlace{}, domain.ErrPlaceNotFound
+	}
+	return place, nil
+}
+
+func TestEventService_RelocateVenueEvents(t *testing.T) {
+	venueId := uint64(7)
+	venue := domain.Venue{Id: venueId, UserId: 10, Name: "New Hall", City: "Lviv", Lat: 49.84, Lon: 24.03}
+	upcoming := func(id, userId uint64) domain.Event {
+		return domain.Event{
+			Id:       id,
+			UserId:   userId,
+			Title:    "Concert",
+			Status:   domain.NewEventStatus,
+			Date:     time.Now().Add(48 * time.Hour),
+			VenueId:  &venueId,
+			Location: "Old Hall",
+			City:     "Kyiv",
+			Lat:      50.45,
+			Lon:      30.52,
+		}
+	}
+	events := &fakeEventRepo{
+		events: map[uint64]domain.Event{
+			1: upcoming(1, 10),
+			2: upcoming(2, 11), // another organizer's event at the venue
+			3: upcoming(3, 10),
+		},
+		failing: map[uint64]bool{3: true},
+	}
+	notifier := &fakeNotifier{}
+	s := NewEventService(events, fakeSubscriptionRepo{subscribers: []uint64{20}}, nil, fakeVenueRepo{venue: venue}, nil, nil, notifier, nil, fakeWebhooks{}, fakeStream{})
+
+	failed, err := s.RelocateVenueEvents(venue, 30)
+	if err != nil {
+		t.Fatalf("RelocateVenueEvents: %s", err)
+	}
+	if len(failed) != 1 || failed[0] != 3 {
+		t.Errorf("not relocated = %v, want [3]", failed)
+	}
+
+	if len(events.updated) != 1 || events.updated[0].Id != 1 {
+		t.Fatalf("updated = %+v, want event 1 only", events.updated)
+	}
+	e := events.updated[0]
+	if e.Location != venue.Name || e.City != venue.City || e.Lat != venue.Lat || e.Lon != venue.Lon {
+		t.Errorf("event place = %s, %s (%g, %g), want the venue's", e.Location, e.City, e.Lat, e.Lon)
+	}
+	if e.UpdatedBy != 30 {
+		t.Errorf("UpdatedBy = %d, want 30", e.UpdatedBy)
+	}
+	if other := events.events[2]; other.Location != "Old Hall" {
+		t.Errorf("the other organizer's event moved to %s", other.Location)
+	}
+
+	if len(notifier.sent) != 1 || notifier.sent[0].UserId != 20 {
+		t.Fatalf("notifications = %+v, want one to the subscriber", notifier.sent)
+	}
+	body := notifier.sent[0].Body
+	if !strings.Contains(body, "Old Hall") || !strings.Contains(body, "New Hall") {
+		t.Errorf("notification body = %q, want the old and the new place", body)
+	}
+}
+
+func TestEventService_CancelOnlyUpcoming(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name  string
+		event domain.Event
+		ok    bool
+	}{
+		{"upcoming", domain.Event{Id: 1, Status: domain.NewEventStatus, Date: now.Add(time.Hour)}, true},
+		{"started", domain.Event{Id: 1, Status: domain.NewEventStatus, Date: now.Add(-time.Hour)}, false},
+		{"cancelled", domain.Event{Id: 1, Status: domain.CancelledEventStatus, Date: now.Add(time.Hour)}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events := &fakeEventRepo{events: map[uint64]domain.Event{1: tt.event}}
+			s := NewEventService(events, fakeSubscriptionRepo{}, nil, nil, nil, nil, &fakeNotifier{}, nil, fakeWebhooks{}, fakeStream{})
+
+			e, err := s.Cancel(tt.event)
+			if !tt.ok {
+				if !errors.Is(err, ErrEventNotUpcoming) {
+					t.Fatalf("Cancel() = %v, want %v", err, ErrEventNotUpcoming)
+				}
+				if len(events.updated) != 0 {
+					t.Errorf("the event was updated")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Cancel: %s", err)
+			}
+			if e.Status != domain.CancelledEventStatus {
+				t.Errorf("status = %s, want %s", e.Status, domain.CancelledEventStatus)
+			}
+		})
+	}
+}
+
+func TestEventService_UpdateDateAndPlace(t *testing.T) {
+	date := time.Date(2030, 5, 10, 18, 0, 0, 0, time.UTC)
+	stored := domain.Event{
+		Id:       1,
+		UserId:   10,
+		Title:    "Concert",
+		Status:   domain.NewEventStatus,
+		Date:     date,
+		Location: "Old Hall",
+		City:     "Kyiv",
+		Lat:      50.45,
+		Lon:      30.52,
+	}
+	events := &fakeEventRepo{events: map[uint64]domain.Event{1: stored}}
+	geocoder := fakeGeocoder{places: map[string]domain.GeoPlace{
+		"Arena, Lviv": {City: "Lviv", Point: domain.GeoPoint{Lat: 49.84, Lon: 24.03}},
+	}}
+	notifier := &fakeNotifier{}
+	s := NewEventService(events, fakeSubscriptionRepo{subscribers: []uint64{20}}, nil, nil, nil, nil, notifier, geocoder, fakeWebhooks{}, fakeStream{})
+
+	// sent the way the controller sends it, the coordinates are left to the geocoder
+	update := stored
+	update.Date = date.Add(24 * time.Hour)
+	update.Location, update.City, update.Lat, update.Lon = "Arena", "Lviv", 0, 0
+	e, err := s.Update(update)
+	if err != nil {
+		t.Fatalf("Update: %s", err)
+	}
+	if e.Lat != 49.84 || e.Lon != 24.03 {
+		t.Errorf("coordinates = (%g, %g), want the geocoded ones", e.Lat, e.Lon)
+	}
+
+	if len(notifier.sent) != 1 {
+		t.Fatalf("notifications = %+v, want one to the subscriber", notifier.sent)
+	}
+	body := notifier.sent[0].Body
+	for _, want := range []string{
+		"The time changed from 10 May 2030 18:00 UTC to 11 May 2030 18:00 UTC",
+		`The place changed from "Old Hall" to "Arena"`,
+		`The city changed from "Kyiv" to "Lviv"`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("notification body = %q, want %q", body, want)
+		}
+	}
+}
+
+func TestEventService_UpdateDeleted(t *testing.T) {
+	events := &fakeEventRepo{events: map[uint64]domain.Event{}}
+	notifier := &fakeNotifier{}
+	s := NewEventService(events, fakeSubscriptionRepo{subscribers: []uint64{20}}, nil, nil, nil, nil, notifier, fakeGeocoder{}, fakeWebhooks{}, fakeStream{})
+
+	_, err := s.Update(domain.Event{Id: 1, UserId: 10, Title: "Concert", City: "Kyiv", Lat: 50.45, Lon: 30.52})
+	if !errors.Is(err, db.ErrNoMoreRows) {
+		t.Errorf("Update() = %v, want %v", err, db.ErrNoMoreRows)
+	}
+	if len(notifier.sent) != 0 {
+		t.Errorf("notifications = %+v, want none", notifier.sent)
+	}
+}
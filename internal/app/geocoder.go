@@ -0,0 +1,13 @@
+package app
+
+import (
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+)
+
+// Geocoder turns an address or a city into coordinates (Forward) and coordinates into
+// the place they belong to (Reverse). Both return domain.ErrPlaceNotFound when there is
+// no match.
+type Geocoder interface {
+	Forward(query string) (domain.GeoPlace, error)
+	Reverse(point domain.GeoPoint) (domain.GeoPlace, error)
+}
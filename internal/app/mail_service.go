@@ -0,0 +1,130 @@
+package app
+
+import (
+	"context"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/database"
+	"log"
+	"time"
+)
+
+const (
+	// mailInterval is how long a queued email may wait before it is sent
+	mailInterval  = 10 * time.Second
+	mailBatchSize = 20
+	// mailLease is how long a claimed email is hidden from the other workers
+	mailLease       = 5 * time.Minute
+	maxMailAttempts = 5
+)
+
+type MailService interface {
+	Enqueue(to, locale, template string, data interface{}) error
+}
+
+type mailService struct {
+	mailRepo  database.MailRepository
+	templates MailTemplates
+}
+
+func NewMailService(mr database.MailRepository, t MailTemplates) MailService {
+	return mailService{
+		mailRepo:  mr,
+		templates: t,
+	}
+}
+
+// Enqueue renders the template in the recipient's locale and puts the email into the queue,
+// it is sent by the MailQueue.
+func (s mailService) Enqueue(to, locale, template string, data interface{}) error {
+	subject, html, text, err := s.templates.Render(locale, template, data)
+	if err != nil {
+		log.Printf("MailService -> Enqueue -> s.templates.Render: %s", err)
+		return err
+	}
+
+	_, err = s.mailRepo.Save(domain.Mail{
+		To:      to,
+		Subject: subject,
+		Html:    html,
+		Text:    text,
+	})
+	if err != nil {
+		log.Printf("MailService -> Enqueue -> s.mailRepo.Save: %s", err)
+		return err
+	}
+	return nil
+}
+
+// MailQueue sends the queued emails in the background.
+type MailQueue interface {
+	Run(ctx context.Context)
+}
+
+type mailQueue struct {
+	mailRepo database.MailRepository
+	mailer   Mailer
+}
+
+func NewMailQueue(mr database.MailRepository, m Mailer) MailQueue {
+	return mailQueue{
+		mailRepo: mr,
+		mailer:   m,
+	}
+}
+
+// Run sends the due emails every mailInterval, until the context is done. A failed email
+// is retried with an exponential backoff, after maxMailAttempts it is left in the queue as dead.
+func (q mailQueue) Run(ctx context.Context) {
+	ticker := time.NewTicker(mailInterval)
+	defer ticker.Stop()
+
+	for {
+		q.sendDue()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (q mailQueue) sendDue() {
+	for {
+		mails, err := q.mailRepo.ClaimDue(mailBatchSize, mailLease)
+		if err != nil {
+			return
+		}
+
+		for _, m := range mails {
+			q.send(m)
+		}
+		if len(mails) < mailBatchSize {
+			return
+		}
+	}
+}
+
+func (q mailQueue) send(m domain.Mail) {
+	sendErr := q.mailer.Send(m)
+	if sendErr == nil {
+		err := q.mailRepo.MarkSent(m.Id)
+		if err != nil {
+			log.Printf("MailQueue -> send -> q.mailRepo.MarkSent: %s", err)
+		}
+		return
+	}
+
+	m.Attempts++
+	if m.Attempts >= maxMailAttempts {
+		m.Status = domain.DeadMailStatus
+		log.Printf("MailQueue -> send: giving up on mail %d to %s: %s", m.Id, m.To, sendErr)
+	} else {
+		m.NextAttemptDate = time.Now().Add(time.Minute << m.Attempts)
+	}
+
+	err := q.mailRepo.MarkFailed(m, sendErr)
+	if err != nil {
+		log.Printf("MailQueue -> send -> q.mailRepo.MarkFailed: %s", err)
+	}
+}
@@ -0,0 +1,83 @@
+package app
+
+import (
+	"errors"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/database"
+	"testing"
+	"time"
+)
+
+type fakeMailRepo struct {
+	database.MailRepository
+	sent   []uint64
+	failed []domain.Mail
+}
+
+func (r *fakeMailRepo) MarkSent(id uint64) error {
+	r.sent = append(r.sent, id)
+	return nil
+}
+
+func (r *fakeMailRepo) MarkFailed(m domain.Mail, sendErr error) error {
+	r.failed = append(r.failed, m)
+	return nil
+}
+
+type fakeMailer struct {
+	err error
+}
+
+func (m fakeMailer) Send(domain.Mail) error {
+	return m.err
+}
+
+func TestMailQueue_SendSucceeds(t *testing.T) {
+	repo := &fakeMailRepo{}
+	q := NewMailQueue(repo, fakeMailer{}).(mailQueue)
+
+	q.send(domain.Mail{Id: 1, Status: domain.PendingMailStatus})
+
+	if len(repo.sent) != 1 || repo.sent[0] != 1 || len(repo.failed) != 0 {
+		t.Errorf("sent = %v, failed = %v, want mail 1 sent", repo.sent, repo.failed)
+	}
+}
+
+func TestMailQueue_SendRetries(t *testing.T) {
+	tests := []struct {
+		attempts uint
+		backoff  time.Duration
+		status   domain.MailStatus
+	}{
+		{0, 2 * time.Minute, domain.PendingMailStatus},
+		{1, 4 * time.Minute, domain.PendingMailStatus},
+		{2, 8 * time.Minute, domain.PendingMailStatus},
+		{3, 16 * time.Minute, domain.PendingMailStatus},
+		{maxMailAttempts - 1, 0, domain.DeadMailStatus},
+	}
+	for _, tt := range tests {
+		repo := &fakeMailRepo{}
+		q := NewMailQueue(repo, fakeMailer{err: errors.New("connection refused")}).(mailQueue)
+
+		before := time.Now()
+		q.send(domain.Mail{Id: 1, Status: domain.PendingMailStatus, Attempts: tt.attempts, NextAttemptDate: before})
+
+		if len(repo.sent) != 0 || len(repo.failed) != 1 {
+			t.Fatalf("attempts %d: sent = %v, failed = %v, want one failure", tt.attempts, repo.sent, repo.failed)
+		}
+		m := repo.failed[0]
+		if m.Attempts != tt.attempts+1 {
+			t.Errorf("attempts %d: Attempts = %d, want %d", tt.attempts, m.Attempts, tt.attempts+1)
+		}
+		if m.Status != tt.status {
+			t.Errorf("attempts %d: Status = %s, want %s", tt.attempts, m.Status, tt.status)
+		}
+		if tt.status == domain.DeadMailStatus {
+			continue
+		}
+		backoff := m.NextAttemptDate.Sub(before)
+		if backoff < tt.backoff || backoff > tt.backoff+time.Second {
+			t.Errorf("attempts %d: retried in %s, want %s", tt.attempts, backoff, tt.backoff)
+		}
+	}
+}
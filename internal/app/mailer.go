@@ -0,0 +1,13 @@
+package app
+
+import "github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+
+// Mailer delivers a single email, see the mail package for the drivers.
+type Mailer interface {
+	Send(m domain.Mail) error
+}
+
+// MailTemplates renders the subject, HTML and text bodies of the named email in the locale.
+type MailTemplates interface {
+	Render(locale, name string, data interface{}) (subject, html, text string, err error)
+}
@@ -0,0 +1,212 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/database"
+	"log"
+	"strconv"
+)
+
+var ErrUnknownNotificationType = errors.New("unknown notification type")
+
+type NotificationService interface {
+	Notify(n domain.Notification) error
+	NotifyAll(userIds []uint64, n domain.Notification) error
+	Find(id uint64) (interface{}, error)
+	FindList(filters database.NotificationFilters, p domain.Pagination) ([]domain.Notification, uint64, error)
+	CountUnread(userId uint64) (uint64, error)
+	MarkRead(userId, id uint64) error
+	MarkAllRead(userId uint64) error
+	Delete(id uint64) error
+	FindPreferences(userId uint64) ([]domain.NotificationPreference, error)
+	UpdatePreferences(userId uint64, prefs []domain.NotificationPreference) ([]domain.NotificationPreference, error)
+}
+
+type notificationService struct {
+	notificationRepo database.NotificationRepository
+	userRepo         database.UserRepository
+	mailService      MailService
+	pushService      PushService
+	stream           StreamService
+}
+
+func NewNotificationService(nr database.NotificationRepository, ur database.UserRepository, ms MailService, ps PushService, st StreamService) NotificationService {
+	return notificationService{
+		notificationRepo: nr,
+		userRepo:         ur,
+		mailService:      ms,
+		pushService:      ps,
+		stream:           st,
+	}
+}
+
+func (s notificationService) Notify(n domain.Notification) error {
+	return s.NotifyAll([]uint64{n.UserId}, n)
+}
+
+// NotifyAll puts a copy of the notification into the inbox of each of the users,
+// except for those who have turned its type off. It is pushed to their devices and open
+// streams too and email notifications are mailed as well.
+func (s notificationService) NotifyAll(userIds []uint64, n domain.Notification) error {
+	disabled, err := s.notificationRepo.FindDisabled(n.Type, userIds)
+	if err != nil {
+		log.Printf("NotificationService -> NotifyAll -> s.notificationRepo.FindDisabled: %s", err)
+		return err
+	}
+
+	notifications := make([]domain.Notification, 0, len(userIds))
+	for _, userId := range userIds {
+		if disabled[userId] {
+			continue
+		}
+		n.UserId = userId
+		notifications = append(notifications, n)
+	}
+
+	err = s.notificationRepo.SaveMany(notifications)
+	if err != nil {
+		log.Printf("NotificationService -> NotifyAll -> s.notificationRepo.SaveMany: %s", err)
+		return err
+	}
+
+	recipients := make([]uint64, len(notifications))
+	for i, notification := range notifications {
+		recipients[i] = notification.UserId
+	}
+	s.pushService.PushAll(recipients, pushMessage(n))
+	s.stream.Publish(recipients, domain.NotificationStreamEvent, streamNotification{
+		Type:    n.Type,
+		EventId: n.EventId,
+		Title:   n.Title,
+		Body:    n.Body,
+	})
+
+	if n.Channel == domain.EmailNotificationChannel {
+		for _, notification := range notifications {
+			s.mail(notification)
+		}
+	}
+	return nil
+}
+
+// mail queues the email copy of the notification, the notification stays in the inbox
+// even if the email fails.
+func (s notificationService) mail(n domain.Notification) {
+	user, err := s.userRepo.FindById(n.UserId)
+	if err != nil {
+		log.Printf("NotificationService -> mail -> s.userRepo.FindById: %s", err)
+		return
+	}
+
+	err = s.mailService.Enqueue(user.Email, user.Locale, "notification", map[string]string{
+		"Name":  user.FirstName,
+		"Title": n.Title,
+		"Body":  n.Body,
+	})
+	if err != nil {
+		log.Printf("NotificationService -> mail -> s.mailService.Enqueue: %s", err)
+	}
+}
+
+func pushMessage(n domain.Notification) domain.PushMessage {
+	data := map[string]string{"type": string(n.Type)}
+	if n.EventId != 0 {
+		data["eventId"] = strconv.FormatUint(n.EventId, 10)
+	}
+	return domain.PushMessage{Title: n.Title, Body: n.Body, Data: data}
+}
+
+func (s notificationService) Find(id uint64) (interface{}, error) {
+	n, err := s.notificationRepo.Find(id)
+	if err != nil {
+		log.Printf("NotificationService -> Find -> s.notificationRepo.Find: %s", err)
+		return nil, err
+	}
+	return n, nil
+}
+
+func (s notificationService) FindList(filters database.NotificationFilters, p domain.Pagination) ([]domain.Notification, uint64, error) {
+	notifications, total, err := s.notificationRepo.FindList(filters, p)
+	if err != nil {
+		log.Printf("NotificationService -> FindList -> s.notificationRepo.FindList: %s", err)
+		return nil, 0, err
+	}
+	return notifications, total, nil
+}
+
+func (s notificationService) CountUnread(userId uint64) (uint64, error) {
+	count, err := s.notificationRepo.CountUnread(userId)
+	if err != nil {
+		log.Printf("NotificationService -> CountUnread -> s.notificationRepo.CountUnread: %s", err)
+		return 0, err
+	}
+	return count, nil
+}
+
+func (s notificationService) MarkRead(userId, id uint64) error {
+	err := s.notificationRepo.MarkRead(userId, []uint64{id})
+	if err != nil {
+		log.Printf("NotificationService -> MarkRead -> s.notificationRepo.MarkRead: %s", err)
+		return err
+	}
+	return nil
+}
+
+func (s notificationService) MarkAllRead(userId uint64) error {
+	err := s.notificationRepo.MarkAllRead(userId)
+	if err != nil {
+		log.Printf("NotificationService -> MarkAllRead -> s.notificationRepo.MarkAllRead: %s", err)
+		return err
+	}
+	return nil
+}
+
+func (s notificationService) Delete(id uint64) error {
+	err := s.notificationRepo.Delete(id)
+	if err != nil {
+		log.Printf("NotificationService -> Delete -> s.notificationRepo.Delete: %s", err)
+		return err
+	}
+	return nil
+}
+
+// FindPreferences returns a preference for every notification type.
+func (s notificationService) FindPreferences(userId uint64) ([]domain.NotificationPreference, error) {
+	stored, err := s.notificationRepo.FindPreferences(userId)
+	if err != nil {
+		log.Printf("NotificationService -> FindPreferences -> s.notificationRepo.FindPreferences: %s", err)
+		return nil, err
+	}
+
+	enabled := make(map[domain.NotificationType]bool, len(stored))
+	for _, p := range stored {
+		enabled[p.Type] = p.Enabled
+	}
+
+	prefs := make([]domain.NotificationPreference, len(domain.NotificationTypes))
+	for i, t := range domain.NotificationTypes {
+		prefs[i] = domain.NotificationPreference{Type: t, Enabled: true}
+		if value, ok := enabled[t]; ok {
+			prefs[i].Enabled = value
+		}
+	}
+	return prefs, nil
+}
+
+// UpdatePreferences changes the given types only, the updated preferences of all types are returned.
+func (s notificationService) UpdatePreferences(userId uint64, prefs []domain.NotificationPreference) ([]domain.NotificationPreference, error) {
+	for _, p := range prefs {
+		if !domain.IsNotificationType(p.Type) {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownNotificationType, p.Type)
+		}
+	}
+
+	err := s.notificationRepo.SavePreferences(userId, prefs)
+	if err != nil {
+		log.Printf("NotificationService -> UpdatePreferences -> s.notificationRepo.SavePreferences: %s", err)
+		return nil, err
+	}
+	return s.FindPreferences(userId)
+}
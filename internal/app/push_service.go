@@ -0,0 +1,115 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/database"
+	"log"
+)
+
+var ErrPushPlatformNotSupported = errors.New("push notifications are not configured for the platform")
+
+// PushSender delivers a push message to a device of its platform, see the push package for
+// the adapters. It returns domain.ErrInvalidPushToken when the provider rejects the token for good.
+type PushSender interface {
+	Send(d domain.PushDevice, m domain.PushMessage) error
+}
+
+type PushService interface {
+	Register(d domain.PushDevice) (domain.PushDevice, error)
+	Find(id uint64) (interface{}, error)
+	FindBySession(sess domain.Session) ([]domain.PushDevice, error)
+	Unregister(id uint64) error
+	PushAll(userIds []uint64, m domain.PushMessage)
+}
+
+type pushService struct {
+	pushDeviceRepo database.PushDeviceRepository
+	senders        map[domain.PushPlatform]PushSender
+}
+
+// NewPushService takes a sender per supported platform, the devices of other platforms
+// can't be registered.
+func NewPushService(pr database.PushDeviceRepository, senders map[domain.PushPlatform]PushSender) PushService {
+	return pushService{
+		pushDeviceRepo: pr,
+		senders:        senders,
+	}
+}
+
+func (s pushService) Register(d domain.PushDevice) (domain.PushDevice, error) {
+	if s.senders[d.Platform] == nil {
+		return domain.PushDevice{}, fmt.Errorf("%w: %s", ErrPushPlatformNotSupported, d.Platform)
+	}
+
+	d, err := s.pushDeviceRepo.Save(d)
+	if err != nil {
+		log.Printf("PushService -> Register -> s.pushDeviceRepo.Save: %s", err)
+		return domain.PushDevice{}, err
+	}
+	return d, nil
+}
+
+func (s pushService) Find(id uint64) (interface{}, error) {
+	d, err := s.pushDeviceRepo.Find(id)
+	if err != nil {
+		log.Printf("PushService -> Find -> s.pushDeviceRepo.Find: %s", err)
+		return nil, err
+	}
+	return d, nil
+}
+
+func (s pushService) FindBySession(sess domain.Session) ([]domain.PushDevice, error) {
+	devices, err := s.pushDeviceRepo.FindBySession(sess)
+	if err != nil {
+		log.Printf("PushService -> FindBySession -> s.pushDeviceRepo.FindBySession: %s", err)
+		return nil, err
+	}
+	return devices, nil
+}
+
+func (s pushService) Unregister(id uint64) error {
+	err := s.pushDeviceRepo.Delete(id)
+	if err != nil {
+		log.Printf("PushService -> Unregister -> s.pushDeviceRepo.Delete: %s", err)
+		return err
+	}
+	return nil
+}
+
+// PushAll sends the message to all devices of the users in the background, so the providers
+// don't slow the caller down. The devices whose token was rejected are removed.
+func (s pushService) PushAll(userIds []uint64, m domain.PushMessage) {
+	devices, err := s.pushDeviceRepo.FindByUsers(userIds)
+	if err != nil {
+		log.Printf("PushService -> PushAll -> s.pushDeviceRepo.FindByUsers: %s", err)
+		return
+	}
+	if len(devices) == 0 {
+		return
+	}
+
+	go func() {
+		for _, d := range devices {
+			s.push(d, m)
+		}
+	}()
+}
+
+func (s pushService) push(d domain.PushDevice, m domain.PushMessage) {
+	sender := s.senders[d.Platform]
+	if sender == nil {
+		return
+	}
+
+	err := sender.Send(d, m)
+	if errors.Is(err, domain.ErrInvalidPushToken) {
+		err = s.pushDeviceRepo.DeleteByToken(d.Token)
+		if err != nil {
+			log.Printf("PushService -> push -> s.pushDeviceRepo.DeleteByToken: %s", err)
+		}
+	} else if err != nil {
+		log.Printf("PushService -> push -> sender.Send(%d): %s", d.Id, err)
+	}
+}
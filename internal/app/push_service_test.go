@@ -0,0 +1,44 @@
+package app
+
+import (
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/database"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/push"
+	"testing"
+)
+
+type fakePushDeviceRepo struct {
+	database.PushDeviceRepository
+	devices []domain.PushDevice
+}
+
+func (r *fakePushDeviceRepo) DeleteByToken(token string) error {
+	kept := r.devices[:0]
+	for _, d := range r.devices {
+		if d.Token != token {
+			kept = append(kept, d)
+		}
+	}
+	r.devices = kept
+	return nil
+}
+
+func TestPushService_PushRemovesInvalidToken(t *testing.T) {
+	good := domain.PushDevice{Id: 1, UserId: 10, Platform: domain.AndroidPushPlatform, Token: "good"}
+	bad := domain.PushDevice{Id: 2, UserId: 10, Platform: domain.AndroidPushPlatform, Token: "bad"}
+	repo := &fakePushDeviceRepo{devices: []domain.PushDevice{good, bad}}
+	sender := push.NewFakeSender("bad")
+	s := NewPushService(repo, map[domain.PushPlatform]PushSender{domain.AndroidPushPlatform: sender}).(pushService)
+
+	m := domain.PushMessage{Title: "Meetup starts in 1 hour"}
+	s.push(good, m)
+	s.push(bad, m)
+
+	if len(repo.devices) != 1 || repo.devices[0].Token != "good" {
+		t.Errorf("devices = %+v, want the bad token removed", repo.devices)
+	}
+	sent := sender.Sent()
+	if len(sent) != 1 || sent[0].Device.Token != "good" {
+		t.Errorf("sent = %+v, want one push to the good token", sent)
+	}
+}
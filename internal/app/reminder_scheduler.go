@@ -0,0 +1,108 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/database"
+	"log"
+	"sort"
+	"strings"
+	"time"
+)
+
+// reminderInterval is how late a reminder may be sent
+const reminderInterval = time.Minute
+
+// ReminderScheduler reminds the subscribers about their events in the background.
+type ReminderScheduler interface {
+	Run(ctx context.Context)
+}
+
+type reminderScheduler struct {
+	reminderRepo database.ReminderRepository
+	notifier     NotificationService
+	offsets      []time.Duration
+}
+
+// NewReminderScheduler sends a reminder offset before the start of the event, for each
+// of the offsets.
+func NewReminderScheduler(rr database.ReminderRepository, n NotificationService, offsets []time.Duration) ReminderScheduler {
+	sorted := append([]time.Duration(nil), offsets...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] > sorted[j] })
+
+	return reminderScheduler{
+		reminderRepo: rr,
+		notifier:     n,
+		offsets:      sorted,
+	}
+}
+
+// Run sends the due reminders every reminderInterval, until the context is done. The sent
+// reminders are kept in the database, changes of the event date and cancellations are
+// picked up from the events themselves.
+func (s reminderScheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(reminderInterval)
+	defer ticker.Stop()
+
+	for {
+		s.sendDue(time.Now())
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s reminderScheduler) sendDue(now time.Time) {
+	for i, offset := range s.offsets {
+		var next time.Duration
+		if i+1 < len(s.offsets) {
+			next = s.offsets[i+1]
+		}
+
+		reminders, err := s.reminderRepo.ClaimDue(offset, next, now)
+		if err != nil {
+			continue
+		}
+		for _, reminder := range reminders {
+			s.remind(reminder, now)
+		}
+	}
+}
+
+func (s reminderScheduler) remind(reminder domain.Reminder, now time.Time) {
+	body := reminder.OccurrenceDate.UTC().Format(notificationDateFormat)
+	if place := strings.Trim(reminder.Location+", "+reminder.City, ", "); place != "" {
+		body += ", " + place
+	}
+
+	err := s.notifier.Notify(domain.Notification{
+		UserId:  reminder.UserId,
+		EventId: reminder.EventId,
+		Type:    domain.EventReminderNotification,
+		Title:   fmt.Sprintf("\"%s\" starts in %s", reminder.EventTitle, startsIn(reminder.OccurrenceDate.Sub(now))),
+		Body:    body,
+	})
+	if err != nil {
+		log.Printf("ReminderScheduler -> remind -> s.notifier.Notify: %s", err)
+	}
+}
+
+// startsIn rounds the time left to a readable amount.
+func startsIn(d time.Duration) string {
+	switch {
+	case d >= 36*time.Hour:
+		return fmt.Sprintf("%d days", d.Round(24*time.Hour)/(24*time.Hour))
+	case d >= 90*time.Minute:
+		return fmt.Sprintf("%d hours", d.Round(time.Hour)/time.Hour)
+	case d >= 55*time.Minute:
+		return "1 hour"
+	case d >= 90*time.Second:
+		return fmt.Sprintf("%d minutes", d.Round(time.Minute)/time.Minute)
+	default:
+		return "a minute"
+	}
+}
@@ -0,0 +1,155 @@
+package app
+
+import (
+	"errors"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/database"
+	"log"
+	"time"
+)
+
+// reviewEditWindow is how long after posting a review can be edited
+const reviewEditWindow = 7 * 24 * time.Hour
+
+var (
+	ErrEventNotDone         = errors.New("the event can be reviewed only once it is over")
+	ErrNotAttendee          = errors.New("only the attendees can review the event")
+	ErrOwnEventReview       = errors.New("organizers can't review their own events")
+	ErrAlreadyReviewed      = errors.New("the event has been reviewed by the user already")
+	ErrReviewEditClosed     = errors.New("the review can't be edited anymore")
+	ErrReviewAlreadyReplied = errors.New("the review has been replied to already")
+)
+
+type ReviewService interface {
+	Save(event domain.Event, rv domain.Review) (domain.Review, error)
+	Find(id uint64) (interface{}, error)
+	FindByEvent(eventId uint64, p domain.Pagination) ([]domain.Review, uint64, error)
+	Update(rv domain.Review) (domain.Review, error)
+	Reply(rv domain.Review, reply string) (domain.Review, error)
+	IsOrganizer(rv domain.Review, user domain.User) (bool, error)
+	FindOrganizerRating(userId uint64) (domain.Rating, error)
+}
+
+type reviewService struct {
+	reviewRepo       database.ReviewRepository
+	subscriptionRepo database.SubscriptionRepository
+	eventRepo        database.EventRepository
+}
+
+func NewReviewService(rr database.ReviewRepository, sr database.SubscriptionRepository, er database.EventRepository) ReviewService {
+	return reviewService{
+		reviewRepo:       rr,
+		subscriptionRepo: sr,
+		eventRepo:        er,
+	}
+}
+
+// Save adds the user's review of the event, which has to be over. Only the attendees
+// who went and haven't reviewed it yet can do it.
+func (s reviewService) Save(event domain.Event, rv domain.Review) (domain.Review, error) {
+	if !event.IsDone(time.Now()) {
+		return domain.Review{}, ErrEventNotDone
+	}
+	if event.UserId == rv.UserId {
+		return domain.Review{}, ErrOwnEventReview
+	}
+
+	attendee, err := s.subscriptionRepo.IsAttendee(event.Id, rv.UserId)
+	if err != nil {
+		log.Printf("ReviewService -> Save -> s.subscriptionRepo.IsAttendee: %s", err)
+		return domain.Review{}, err
+	}
+	if !attendee {
+		return domain.Review{}, ErrNotAttendee
+	}
+
+	reviewed, err := s.reviewRepo.Exists(event.Id, rv.UserId)
+	if err != nil {
+		log.Printf("ReviewService -> Save -> s.reviewRepo.Exists: %s", err)
+		return domain.Review{}, err
+	}
+	if reviewed {
+		return domain.Review{}, ErrAlreadyReviewed
+	}
+
+	// Exists can't see a review being saved at the same time, the unique index can
+	rv.EventId = event.Id
+	rv, err = s.reviewRepo.Save(rv)
+	if errors.Is(err, database.ErrReviewExists) {
+		return domain.Review{}, ErrAlreadyReviewed
+	} else if err != nil {
+		log.Printf("ReviewService -> Save -> s.reviewRepo.Save: %s", err)
+		return domain.Review{}, err
+	}
+	return rv, nil
+}
+
+func (s reviewService) Find(id uint64) (interface{}, error) {
+	rv, err := s.reviewRepo.Find(id)
+	if err != nil {
+		log.Printf("ReviewService -> Find -> s.reviewRepo.Find: %s", err)
+		return nil, err
+	}
+	return rv, nil
+}
+
+func (s reviewService) FindByEvent(eventId uint64, p domain.Pagination) ([]domain.Review, uint64, error) {
+	reviews, total, err := s.reviewRepo.FindByEvent(eventId, p)
+	if err != nil {
+		log.Printf("ReviewService -> FindByEvent -> s.reviewRepo.FindByEvent: %s", err)
+		return nil, 0, err
+	}
+	return reviews, total, nil
+}
+
+// Update changes the review within reviewEditWindow of posting it.
+func (s reviewService) Update(rv domain.Review) (domain.Review, error) {
+	if time.Since(rv.CreatedDate) > reviewEditWindow {
+		return domain.Review{}, ErrReviewEditClosed
+	}
+
+	rv, err := s.reviewRepo.Update(rv)
+	if err != nil {
+		log.Printf("ReviewService -> Update -> s.reviewRepo.Update: %s", err)
+		return domain.Review{}, err
+	}
+	return rv, nil
+}
+
+// Reply sets the organizer's reply, which can't be changed afterwards.
+func (s reviewService) Reply(rv domain.Review, reply string) (domain.Review, error) {
+	replied, err := s.reviewRepo.Reply(rv.Id, reply)
+	if err != nil {
+		log.Printf("ReviewService -> Reply -> s.reviewRepo.Reply: %s", err)
+		return domain.Review{}, err
+	}
+	if !replied {
+		return domain.Review{}, ErrReviewAlreadyReplied
+	}
+
+	rv, err = s.reviewRepo.Find(rv.Id)
+	if err != nil {
+		log.Printf("ReviewService -> Reply -> s.reviewRepo.Find: %s", err)
+		return domain.Review{}, err
+	}
+	return rv, nil
+}
+
+// IsOrganizer tells whether the user owns the reviewed event.
+func (s reviewService) IsOrganizer(rv domain.Review, user domain.User) (bool, error) {
+	evn, err := s.eventRepo.Find(rv.EventId)
+	if err != nil {
+		log.Printf("ReviewService -> IsOrganizer -> s.eventRepo.Find: %s", err)
+		return false, err
+	}
+	return evn.(domain.Event).UserId == user.Id, nil
+}
+
+func (s reviewService) FindOrganizerRating(userId uint64) (domain.Rating, error) {
+	rating, err := s.reviewRepo.FindOrganizerRating(userId)
+	if err != nil {
+		log.Printf("ReviewService -> FindOrganizerRating -> s.reviewRepo.FindOrganizerRating: %s", err)
+		return domain.Rating{}, err
+	}
+	return rating, nil
+}
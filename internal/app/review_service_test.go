@@ -0,0 +1,55 @@
+package app
+
+import (
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/database"
+	"testing"
+	"time"
+)
+
+type fakeReviewRepo struct {
+	database.ReviewRepository
+	exists bool
+	err    error
+}
+
+func (r fakeReviewRepo) Exists(eventId, userId uint64) (bool, error) {
+	return r.exists, nil
+}
+
+func (r fakeReviewRepo) Save(rv domain.Review) (domain.Review, error) {
+	return rv, r.err
+}
+
+type fakeAttendeeRepo struct {
+	database.SubscriptionRepository
+	attendee bool
+}
+
+func (r fakeAttendeeRepo) IsAttendee(eventId, userId uint64) (bool, error) {
+	return r.attendee, nil
+}
+
+func TestReviewService_Save(t *testing.T) {
+	evn := domain.Event{Id: 1, UserId: 1, Status: domain.DoneEventStatus, Date: time.Now().Add(-24 * time.Hour)}
+	tests := []struct {
+		name     string
+		attendee bool
+		reviews  fakeReviewRepo
+		want     error
+	}{
+		{"saved", true, fakeReviewRepo{}, nil},
+		{"not attendee", false, fakeReviewRepo{}, ErrNotAttendee},
+		{"reviewed", true, fakeReviewRepo{exists: true}, ErrAlreadyReviewed},
+		// a review saved at the same time gets past Exists, not past the unique index
+		{"reviewed concurrently", true, fakeReviewRepo{err: database.ErrReviewExists}, ErrAlreadyReviewed},
+	}
+	for _, tt := range tests {
+		s := NewReviewService(tt.reviews, fakeAttendeeRepo{attendee: tt.attendee}, nil)
+
+		_, err := s.Save(evn, domain.Review{UserId: 2, Rating: 5, Body: "Great"})
+		if err != tt.want {
+			t.Errorf("%s: Save error = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
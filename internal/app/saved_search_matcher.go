@@ -0,0 +1,179 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/database"
+	"log"
+	"strings"
+	"time"
+)
+
+const (
+	// matchInterval is how long a new event may wait before it is run against the saved searches
+	matchInterval  = 30 * time.Second
+	matchBatchSize = 100
+	// maxMatchAttempts is how many times an event failing to match is retried before it is skipped
+	maxMatchAttempts = 5
+
+	digestPeriod    = 24 * time.Hour
+	maxDigestEvents = 10
+
+	notificationDateFormat = "2 Jan 2006 15:04 MST"
+)
+
+// SavedSearchMatcher runs the new events against the saved searches in the background.
+type SavedSearchMatcher interface {
+	Run(ctx context.Context)
+}
+
+type savedSearchMatcher struct {
+	eventRepo       database.EventRepository
+	savedSearchRepo database.SavedSearchRepository
+	notifier        NotificationService
+}
+
+func NewSavedSearchMatcher(ev database.EventRepository, sr database.SavedSearchRepository, n NotificationService) SavedSearchMatcher {
+	return savedSearchMatcher{
+		eventRepo:       ev,
+		savedSearchRepo: sr,
+		notifier:        n,
+	}
+}
+
+// Run matches the new events and sends the due daily digests every matchInterval, until
+// the context is done. The progress is kept in the database, so nothing is lost on restarts.
+func (m savedSearchMatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(matchInterval)
+	defer ticker.Stop()
+
+	for {
+		m.matchNewEvents()
+		m.sendDigests()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (m savedSearchMatcher) matchNewEvents() {
+	for {
+		events, err := m.eventRepo.FindUnmatched(matchBatchSize)
+		if err != nil {
+			return
+		}
+
+		matched := make([]uint64, 0, len(events))
+		for _, e := range events {
+			err = m.match(e)
+			if err != nil {
+				// retried on the next run, the recorded matches are not notified twice
+				log.Printf("SavedSearchMatcher -> matchNewEvents -> m.match(%d): %s", e.Id, err)
+				err = m.eventRepo.MarkMatchFailed(e.Id, maxMatchAttempts)
+				if err != nil {
+					log.Printf("SavedSearchMatcher -> matchNewEvents -> m.eventRepo.MarkMatchFailed: %s", err)
+				}
+				continue
+			}
+			matched = append(matched, e.Id)
+		}
+
+		err = m.eventRepo.MarkMatched(matched)
+		if err != nil {
+			log.Printf("SavedSearchMatcher -> matchNewEvents -> m.eventRepo.MarkMatched: %s", err)
+			return
+		}
+		// the failed events are in the next batch again, they wait for the next run
+		if len(matched) < matchBatchSize {
+			return
+		}
+	}
+}
+
+// match records the event for the saved searches it matches, the instant ones are notified at once.
+// Only upcoming events are matched.
+func (m savedSearchMatcher) match(e domain.Event) error {
+	if e.DeletedDate != nil || e.Status != domain.NewEventStatus || e.Date.Before(time.Now()) {
+		return nil
+	}
+
+	searches, err := m.savedSearchRepo.FindMatching(e.Id)
+	if err != nil {
+		return err
+	}
+
+	for _, s := range searches {
+		instant := s.Frequency == domain.InstantSavedSearchFrequency
+		added, err := m.savedSearchRepo.AddMatch(s.Id, e.Id, instant)
+		if err != nil {
+			return err
+		}
+		if !added || !instant {
+			continue
+		}
+
+		err = m.notifier.Notify(domain.Notification{
+			UserId:  s.UserId,
+			EventId: e.Id,
+			Type:    domain.SavedSearchMatchNotification,
+			Channel: s.Channel,
+			Title:   fmt.Sprintf("New event for \"%s\": %s", s.Name, e.Title),
+			Body:    fmt.Sprintf("%s, %s", e.Date.UTC().Format(notificationDateFormat), strings.Trim(e.Location+", "+e.City, ", ")),
+		})
+		if err != nil {
+			log.Printf("SavedSearchMatcher -> match -> m.notifier.Notify: %s", err)
+		}
+	}
+	return nil
+}
+
+// sendDigests sends a single notification per daily search listing the events found since
+// the previous digest.
+func (m savedSearchMatcher) sendDigests() {
+	now := time.Now()
+	searches, err := m.savedSearchRepo.FindDueDigests(now.Add(-digestPeriod))
+	if err != nil {
+		return
+	}
+
+	for _, s := range searches {
+		matches, err := m.savedSearchRepo.FindPendingMatches(s.Id)
+		if err != nil {
+			continue
+		}
+
+		if len(matches) > 0 {
+			err = m.notifier.Notify(domain.Notification{
+				UserId:  s.UserId,
+				Type:    domain.SavedSearchDigestNotification,
+				Channel: s.Channel,
+				Title:   fmt.Sprintf("%d new events for \"%s\"", len(matches), s.Name),
+				Body:    digestBody(matches),
+			})
+			if err != nil {
+				log.Printf("SavedSearchMatcher -> sendDigests -> m.notifier.Notify: %s", err)
+			}
+		}
+
+		err = m.savedSearchRepo.MarkDigested(s.Id, now)
+		if err != nil {
+			log.Printf("SavedSearchMatcher -> sendDigests -> m.savedSearchRepo.MarkDigested: %s", err)
+		}
+	}
+}
+
+func digestBody(matches []domain.SavedSearchMatch) string {
+	var b strings.Builder
+	for i, match := range matches {
+		if i == maxDigestEvents {
+			fmt.Fprintf(&b, "and %d more", len(matches)-maxDigestEvents)
+			break
+		}
+		fmt.Fprintf(&b, "%s, %s\n", match.EventTitle, match.EventDate.UTC().Format(notificationDateFormat))
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
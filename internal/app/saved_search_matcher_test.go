@@ -0,0 +1,59 @@
+package app
+
+import (
+	"errors"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/database"
+	"testing"
+	"time"
+)
+
+type fakeUnmatchedRepo struct {
+	database.EventRepository
+	events  []domain.Event
+	matched []uint64
+	failed  []uint64
+}
+
+func (r *fakeUnmatchedRepo) FindUnmatched(limit uint) ([]domain.Event, error) {
+	return r.events, nil
+}
+
+func (r *fakeUnmatchedRepo) MarkMatched(ids []uint64) error {
+	r.matched = append(r.matched, ids...)
+	return nil
+}
+
+func (r *fakeUnmatchedRepo) MarkMatchFailed(id uint64, maxAttempts uint) error {
+	r.failed = append(r.failed, id)
+	return nil
+}
+
+type fakeSavedSearchRepo struct {
+	database.SavedSearchRepository
+	failing uint64
+}
+
+func (r fakeSavedSearchRepo) FindMatching(eventId uint64) ([]domain.SavedSearch, error) {
+	if eventId == r.failing {
+		return nil, errors.New("invalid search text")
+	}
+	return nil, nil
+}
+
+func TestSavedSearchMatcher_SkipsFailingEvents(t *testing.T) {
+	upcoming := func(id uint64) domain.Event {
+		return domain.Event{Id: id, Status: domain.NewEventStatus, Date: time.Now().Add(time.Hour)}
+	}
+	events := &fakeUnmatchedRepo{events: []domain.Event{upcoming(1), upcoming(2), upcoming(3)}}
+	m := NewSavedSearchMatcher(events, fakeSavedSearchRepo{failing: 2}, &fakeNotifier{}).(savedSearchMatcher)
+
+	m.matchNewEvents()
+
+	if len(events.matched) != 2 || events.matched[0] != 1 || events.matched[1] != 3 {
+		t.Errorf("matched = %v, want [1 3]", events.matched)
+	}
+	if len(events.failed) != 1 || events.failed[0] != 2 {
+		t.Errorf("failed = %v, want [2]", events.failed)
+	}
+}
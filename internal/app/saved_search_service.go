@@ -0,0 +1,155 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/database"
+	"github.com/upper/db/v4"
+	"log"
+	"strings"
+)
+
+const (
+	maxSavedSearches           = 20
+	defaultSavedSearchRadiusKm = 10
+)
+
+var (
+	ErrTooManySavedSearches = fmt.Errorf("a user can't have more than %d saved searches", maxSavedSearches)
+	ErrEmptySavedSearch     = errors.New("a saved search needs the search text, city, lat and lon or category")
+	ErrRadiusWithoutPoint   = errors.New("radiusKm requires lat and lon")
+)
+
+type SavedSearchService interface {
+	Save(s domain.SavedSearch) (domain.SavedSearch, error)
+	Update(s domain.SavedSearch) (domain.SavedSearch, error)
+	Find(id uint64) (interface{}, error)
+	FindByUser(userId uint64) ([]domain.SavedSearch, error)
+	Delete(id uint64) error
+}
+
+type savedSearchService struct {
+	savedSearchRepo database.SavedSearchRepository
+	categoryRepo    database.CategoryRepository
+}
+
+func NewSavedSearchService(sr database.SavedSearchRepository, cr database.CategoryRepository) SavedSearchService {
+	return savedSearchService{
+		savedSearchRepo: sr,
+		categoryRepo:    cr,
+	}
+}
+
+func (s savedSearchService) Save(ss domain.SavedSearch) (domain.SavedSearch, error) {
+	ss, err := s.prepare(ss)
+	if err != nil {
+		return domain.SavedSearch{}, err
+	}
+
+	count, err := s.savedSearchRepo.CountByUser(ss.UserId)
+	if err != nil {
+		log.Printf("SavedSearchService -> Save -> s.savedSearchRepo.CountByUser: %s", err)
+		return domain.SavedSearch{}, err
+	}
+	if count >= maxSavedSearches {
+		return domain.SavedSearch{}, ErrTooManySavedSearches
+	}
+
+	ss, err = s.savedSearchRepo.Save(ss)
+	if err != nil {
+		log.Printf("SavedSearchService -> Save -> s.savedSearchRepo.Save: %s", err)
+		return domain.SavedSearch{}, err
+	}
+	return ss, nil
+}
+
+func (s savedSearchService) Update(ss domain.SavedSearch) (domain.SavedSearch, error) {
+	ss, err := s.prepare(ss)
+	if err != nil {
+		return domain.SavedSearch{}, err
+	}
+
+	ss, err = s.savedSearchRepo.Update(ss)
+	if err != nil {
+		log.Printf("SavedSearchService -> Update -> s.savedSearchRepo.Update: %s", err)
+		return domain.SavedSearch{}, err
+	}
+	return ss, nil
+}
+
+func (s savedSearchService) Find(id uint64) (interface{}, error) {
+	ss, err := s.savedSearchRepo.Find(id)
+	if err != nil {
+		log.Printf("SavedSearchService -> Find -> s.savedSearchRepo.Find: %s", err)
+		return nil, err
+	}
+	return ss, nil
+}
+
+func (s savedSearchService) FindByUser(userId uint64) ([]domain.SavedSearch, error) {
+	searches, err := s.savedSearchRepo.FindByUser(userId)
+	if err != nil {
+		log.Printf("SavedSearchService -> FindByUser -> s.savedSearchRepo.FindByUser: %s", err)
+		return nil, err
+	}
+	return searches, nil
+}
+
+func (s savedSearchService) Delete(id uint64) error {
+	err := s.savedSearchRepo.Delete(id)
+	if err != nil {
+		log.Printf("SavedSearchService -> Delete -> s.savedSearchRepo.Delete: %s", err)
+		return err
+	}
+	return nil
+}
+
+// prepare trims the criteria, fills in the defaults and checks the category.
+func (s savedSearchService) prepare(ss domain.SavedSearch) (domain.SavedSearch, error) {
+	ss.Name = strings.TrimSpace(ss.Name)
+	ss.Search = strings.TrimSpace(ss.Search)
+	ss.City = strings.TrimSpace(ss.City)
+	if ss.Search == "" && ss.City == "" && ss.Point == nil && ss.CategoryId == nil {
+		return domain.SavedSearch{}, ErrEmptySavedSearch
+	}
+
+	if ss.Point == nil && ss.RadiusKm != 0 {
+		return domain.SavedSearch{}, ErrRadiusWithoutPoint
+	}
+	if ss.Point != nil && ss.RadiusKm == 0 {
+		ss.RadiusKm = defaultSavedSearchRadiusKm
+	}
+
+	if ss.Channel == "" {
+		ss.Channel = domain.InAppNotificationChannel
+	}
+	if ss.Frequency == "" {
+		ss.Frequency = domain.InstantSavedSearchFrequency
+	}
+
+	if ss.CategoryId != nil {
+		_, err := s.categoryRepo.Find(*ss.CategoryId)
+		if errors.Is(err, db.ErrNoMoreRows) {
+			return domain.SavedSearch{}, ErrCategoryNotFound
+		} else if err != nil {
+			log.Printf("SavedSearchService -> prepare -> s.categoryRepo.Find: %s", err)
+			return domain.SavedSearch{}, err
+		}
+	}
+	return ss, nil
+}
+
+// SavedSearchFilters returns the event list filters equivalent to the saved search.
+func SavedSearchFilters(ss domain.SavedSearch) database.UrlFilters {
+	filters := database.UrlFilters{
+		Search:   ss.Search,
+		City:     ss.City,
+		Point:    ss.Point,
+		RadiusKm: ss.RadiusKm,
+	}
+	if ss.CategoryId != nil {
+		filters.CategoryId = *ss.CategoryId
+	}
+	return filters
+}
@@ -0,0 +1,233 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/database"
+	"log"
+	"sync"
+	"time"
+)
+
+const (
+	// streamRetention is how long a client may stay away and still resume from its last event
+	streamRetention     = 24 * time.Hour
+	streamPurgeInterval = time.Hour
+	streamBatchSize     = 100
+	// streamRelistenDelay is the pause before listening again when the listener could not start
+	streamRelistenDelay = 10 * time.Second
+)
+
+// StreamListener tells which users have new stream events, whichever server instance has
+// saved them. 0 stands for all the users. See database.StreamListener.
+type StreamListener interface {
+	Listen(ctx context.Context) (<-chan uint64, error)
+}
+
+type StreamService interface {
+	Publish(userIds []uint64, t domain.StreamEventType, data interface{})
+	FindAfter(userId, afterId uint64) ([]domain.StreamEvent, error)
+	FindLastId(userId uint64) (uint64, error)
+}
+
+type streamService struct {
+	streamRepo database.StreamRepository
+}
+
+func NewStreamService(sr database.StreamRepository) StreamService {
+	return streamService{
+		streamRepo: sr,
+	}
+}
+
+// Publish saves the event for each of the users, their open streams are woken through
+// the database. A failure is only logged, the change itself has been made already.
+func (s streamService) Publish(userIds []uint64, t domain.StreamEventType, data interface{}) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		log.Printf("StreamService -> Publish -> json.Marshal: %s", err)
+		return
+	}
+
+	err = s.streamRepo.SaveMany(userIds, t, string(payload))
+	if err != nil {
+		log.Printf("StreamService -> Publish -> s.streamRepo.SaveMany: %s", err)
+	}
+}
+
+// FindAfter returns the next batch of the user's events following the given one.
+func (s streamService) FindAfter(userId, afterId uint64) ([]domain.StreamEvent, error) {
+	events, err := s.streamRepo.FindAfter(userId, afterId, streamBatchSize)
+	if err != nil {
+		log.Printf("StreamService -> FindAfter -> s.streamRepo.FindAfter: %s", err)
+		return nil, err
+	}
+	return events, nil
+}
+
+func (s streamService) FindLastId(userId uint64) (uint64, error) {
+	id, err := s.streamRepo.FindLastId(userId)
+	if err != nil {
+		log.Printf("StreamService -> FindLastId -> s.streamRepo.FindLastId: %s", err)
+		return 0, err
+	}
+	return id, nil
+}
+
+// StreamHub wakes the streams open on this server instance when their users have new events.
+type StreamHub interface {
+	Run(ctx context.Context)
+	// Subscribe returns a channel which receives a value whenever the user may have new events.
+	// It is closed when the hub stops, the returned func has to be called once the stream ends.
+	Subscribe(userId uint64) (<-chan struct{}, func())
+}
+
+type streamHub struct {
+	streamRepo database.StreamRepository
+	listener   StreamListener
+
+	mu      *sync.Mutex
+	streams map[uint64]map[chan struct{}]struct{}
+	stopped bool
+}
+
+func NewStreamHub(sr database.StreamRepository, l StreamListener) StreamHub {
+	return &streamHub{
+		streamRepo: sr,
+		listener:   l,
+		mu:         &sync.Mutex{},
+		streams:    make(map[uint64]map[chan struct{}]struct{}),
+	}
+}
+
+// Run relays the notifications of the listener to the streams and purges the old events
+// every streamPurgeInterval, until the context is done. Then all the streams are closed,
+// so the server can shut down.
+func (h *streamHub) Run(ctx context.Context) {
+	defer h.stop()
+
+	purge := time.NewTicker(streamPurgeInterval)
+	defer purge.Stop()
+
+	for {
+		users, err := h.listener.Listen(ctx)
+		if err != nil {
+			log.Printf("StreamHub -> Run -> h.listener.Listen: %s", err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(streamRelistenDelay):
+				continue
+			}
+		}
+
+		for users != nil {
+			select {
+			case userId, ok := <-users:
+				if !ok {
+					users = nil
+					break
+				}
+				h.wake(userId)
+			case <-purge.C:
+				h.purge()
+			}
+		}
+
+		if ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+func (h *streamHub) Subscribe(userId uint64) (<-chan struct{}, func()) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	// a single pending wake-up is enough, the stream reads all the new events at once
+	ch := make(chan struct{}, 1)
+	if h.stopped {
+		close(ch)
+		return ch, func() {}
+	}
+
+	if h.streams[userId] == nil {
+		h.streams[userId] = make(map[chan struct{}]struct{})
+	}
+	h.streams[userId][ch] = struct{}{}
+
+	return ch, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+
+		if _, ok := h.streams[userId][ch]; !ok {
+			return
+		}
+		delete(h.streams[userId], ch)
+		if len(h.streams[userId]) == 0 {
+			delete(h.streams, userId)
+		}
+	}
+}
+
+func (h *streamHub) wake(userId uint64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for id, streams := range h.streams {
+		if userId != 0 && id != userId {
+			continue
+		}
+		for ch := range streams {
+			select {
+			case ch <- struct{}{}:
+			default:
+			}
+		}
+	}
+}
+
+func (h *streamHub) stop() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.stopped = true
+	for _, streams := range h.streams {
+		for ch := range streams {
+			close(ch)
+		}
+	}
+	h.streams = make(map[uint64]map[chan struct{}]struct{})
+}
+
+func (h *streamHub) purge() {
+	err := h.streamRepo.DeleteBefore(time.Now().Add(-streamRetention))
+	if err != nil {
+		log.Printf("StreamHub -> purge -> h.streamRepo.DeleteBefore: %s", err)
+	}
+}
+
+type streamEvent struct {
+	EventId uint64             `json:"eventId"`
+	Title   string             `json:"title"`
+	Status  domain.EventStatus `json:"status"`
+	Date    time.Time          `json:"date"`
+	EndDate *time.Time         `json:"endDate,omitempty"`
+	Changes []string           `json:"changes,omitempty"`
+}
+
+type streamAttendees struct {
+	EventId    uint64 `json:"eventId"`
+	Going      uint64 `json:"going"`
+	Maybe      uint64 `json:"maybe"`
+	Declined   uint64 `json:"declined"`
+	Waitlisted uint64 `json:"waitlisted"`
+}
+
+type streamNotification struct {
+	Type    domain.NotificationType `json:"type"`
+	EventId uint64                  `json:"eventId,omitempty"`
+	Title   string                  `json:"title"`
+	Body    string                  `json:"body,omitempty"`
+}
@@ -0,0 +1,184 @@
+package app
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/database"
+	"github.com/google/uuid"
+	"github.com/upper/db/v4"
+	"log"
+	"strconv"
+	"strings"
+)
+
+const ticketCodePrefix = "EVT1"
+
+var (
+	ErrInvalidTicket          = errors.New("invalid ticket code")
+	ErrTicketAlreadyCheckedIn = errors.New("ticket has already been checked in")
+)
+
+type TicketService interface {
+	Issue(eventId, userId uint64) (domain.Ticket, error)
+	Revoke(eventId, userId uint64) error
+	FindForUser(eventId, userId uint64) (domain.Ticket, error)
+	CheckIn(eventId uint64, code string, checkedInBy uint64) (domain.Ticket, error)
+	Stats(eventId uint64) (domain.CheckInStats, error)
+}
+
+type ticketService struct {
+	ticketRepo       database.TicketRepository
+	subscriptionRepo database.SubscriptionRepository
+	secret           []byte
+}
+
+func NewTicketService(tr database.TicketRepository, sb database.SubscriptionRepository, secret string) TicketService {
+	return ticketService{
+		ticketRepo:       tr,
+		subscriptionRepo: sb,
+		secret:           []byte(secret),
+	}
+}
+
+// Issue returns the user's ticket for the event, creating it on first call.
+func (s ticketService) Issue(eventId, userId uint64) (domain.Ticket, error) {
+	t, err := s.ticketRepo.Save(domain.Ticket{
+		EventId: eventId,
+		UserId:  userId,
+		Nonce:   uuid.NewString(),
+	})
+	if err != nil {
+		log.Printf("TicketService -> Issue -> s.ticketRepo.Save: %s", err)
+		return domain.Ticket{}, err
+	}
+
+	return s.withCode(t), nil
+}
+
+func (s ticketService) Revoke(eventId, userId uint64) error {
+	err := s.ticketRepo.Delete(eventId, userId)
+	if err != nil {
+		log.Printf("TicketService -> Revoke -> s.ticketRepo.Delete: %s", err)
+		return err
+	}
+	return nil
+}
+
+// FindForUser returns the user's ticket for the event. Users who hold a place but have no
+// ticket, e.g. those subscribed before tickets were introduced, get one issued now.
+func (s ticketService) FindForUser(eventId, userId uint64) (domain.Ticket, error) {
+	t, err := s.ticketRepo.FindByEventAndUser(eventId, userId)
+	if err == nil {
+		return s.withCode(t), nil
+	} else if !errors.Is(err, db.ErrNoMoreRows) {
+		log.Printf("TicketService -> FindForUser -> s.ticketRepo.FindByEventAndUser: %s", err)
+		return domain.Ticket{}, err
+	}
+
+	holds, err := s.subscriptionRepo.HoldsPlace(eventId, userId)
+	if err != nil {
+		log.Printf("TicketService -> FindForUser -> s.subscriptionRepo.HoldsPlace: %s", err)
+		return domain.Ticket{}, err
+	}
+	if !holds {
+		return domain.Ticket{}, db.ErrNoMoreRows
+	}
+
+	return s.Issue(eventId, userId)
+}
+
+func (s ticketService) CheckIn(eventId uint64, code string, checkedInBy uint64) (domain.Ticket, error) {
+	ticketId, codeEventId, err := s.verify(code)
+	if err != nil {
+		return domain.Ticket{}, err
+	}
+	if codeEventId != eventId {
+		return domain.Ticket{}, ErrInvalidTicket
+	}
+
+	t, err := s.ticketRepo.Find(ticketId)
+	if err != nil {
+		if errors.Is(err, db.ErrNoMoreRows) {
+			// the ticket was revoked
+			return domain.Ticket{}, ErrInvalidTicket
+		}
+		log.Printf("TicketService -> CheckIn -> s.ticketRepo.Find: %s", err)
+		return domain.Ticket{}, err
+	}
+	if s.sign(t) != code {
+		return domain.Ticket{}, ErrInvalidTicket
+	}
+
+	ok, err := s.ticketRepo.CheckIn(t.Id, checkedInBy)
+	if err != nil {
+		log.Printf("TicketService -> CheckIn -> s.ticketRepo.CheckIn: %s", err)
+		return domain.Ticket{}, err
+	}
+	if !ok {
+		return domain.Ticket{}, ErrTicketAlreadyCheckedIn
+	}
+
+	t, err = s.ticketRepo.Find(t.Id)
+	if err != nil {
+		log.Printf("TicketService -> CheckIn -> s.ticketRepo.Find: %s", err)
+		return domain.Ticket{}, err
+	}
+	return s.withCode(t), nil
+}
+
+func (s ticketService) Stats(eventId uint64) (domain.CheckInStats, error) {
+	stats, err := s.ticketRepo.Stats(eventId)
+	if err != nil {
+		log.Printf("TicketService -> Stats -> s.ticketRepo.Stats: %s", err)
+		return domain.CheckInStats{}, err
+	}
+	return stats, nil
+}
+
+func (s ticketService) withCode(t domain.Ticket) domain.Ticket {
+	t.Code = s.sign(t)
+	return t
+}
+
+// sign builds a ticket code: EVT1.<ticketId>.<eventId>.<nonce>.<signature>,
+// the signature is HMAC-SHA256 over everything before it.
+func (s ticketService) sign(t domain.Ticket) string {
+	payload := fmt.Sprintf("%s.%d.%d.%s", ticketCodePrefix, t.Id, t.EventId, t.Nonce)
+	return payload + "." + s.signature(payload)
+}
+
+func (s ticketService) signature(payload string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func (s ticketService) verify(code string) (uint64, uint64, error) {
+	i := strings.LastIndex(code, ".")
+	if i < 0 {
+		return 0, 0, ErrInvalidTicket
+	}
+	payload, sig := code[:i], code[i+1:]
+	if !hmac.Equal([]byte(sig), []byte(s.signature(payload))) {
+		return 0, 0, ErrInvalidTicket
+	}
+
+	parts := strings.Split(payload, ".")
+	if len(parts) != 4 || parts[0] != ticketCodePrefix {
+		return 0, 0, ErrInvalidTicket
+	}
+	ticketId, err := strconv.ParseUint(parts[1], 10, 64)
+	if err != nil {
+		return 0, 0, ErrInvalidTicket
+	}
+	eventId, err := strconv.ParseUint(parts[2], 10, 64)
+	if err != nil {
+		return 0, 0, ErrInvalidTicket
+	}
+
+	return ticketId, eventId, nil
+}
@@ -1,6 +1,8 @@
 package app
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
 	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/database"
 	"log"
@@ -9,6 +11,9 @@ import (
 type UserService interface {
 	FindByEmail(email string) (domain.User, error)
 	FindById(id uint64) (domain.User, error)
+	FindByCalendarToken(token string) (domain.User, error)
+	EnsureCalendarToken(user domain.User) (domain.User, error)
+	RotateCalendarToken(user domain.User) (domain.User, error)
 	Find(id uint64) (interface{}, error)
 	Update(user domain.User) (domain.User, error)
 	Delete(id uint64) error
@@ -43,6 +48,36 @@ func (s userService) FindById(id uint64) (domain.User, error) {
 	return user, err
 }
 
+func (s userService) FindByCalendarToken(token string) (domain.User, error) {
+	user, err := s.userRepo.FindByCalendarToken(token)
+	if err != nil {
+		log.Printf("UserService: %s", err)
+		return domain.User{}, err
+	}
+	return user, nil
+}
+
+// EnsureCalendarToken generates the calendar feed token for users who don't have one yet.
+func (s userService) EnsureCalendarToken(user domain.User) (domain.User, error) {
+	if user.CalendarToken != "" {
+		return user, nil
+	}
+	return s.RotateCalendarToken(user)
+}
+
+// RotateCalendarToken replaces the calendar feed token, the old feed URL stops working.
+func (s userService) RotateCalendarToken(user domain.User) (domain.User, error) {
+	b := make([]byte, 24)
+	_, err := rand.Read(b)
+	if err != nil {
+		log.Printf("UserService: %s", err)
+		return domain.User{}, err
+	}
+
+	user.CalendarToken = hex.EncodeToString(b)
+	return s.Update(user)
+}
+
 func (s userService) Find(id uint64) (interface{}, error) {
 	user, err := s.userRepo.FindById(id)
 	if err != nil {
@@ -0,0 +1,98 @@
+package app
+
+import (
+	"errors"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/database"
+	"log"
+)
+
+var ErrVenueNotFound = errors.New("venue not found")
+
+type VenueService interface {
+	Save(v domain.Venue) (domain.Venue, error)
+	Update(v domain.Venue) (domain.Venue, error)
+	Find(id uint64) (interface{}, error)
+	FindList(filters database.VenueFilters, p domain.Pagination) ([]domain.Venue, uint64, error)
+	Delete(id uint64) error
+	AddPhoto(venueId uint64, image string) (domain.VenuePhoto, error)
+	FindPhoto(venueId, id uint64) (domain.VenuePhoto, error)
+	DeletePhoto(id uint64) error
+}
+
+type venueService struct {
+	venueRepo database.VenueRepository
+}
+
+func NewVenueService(vr database.VenueRepository) VenueService {
+	return venueService{
+		venueRepo: vr,
+	}
+}
+
+func (s venueService) Save(v domain.Venue) (domain.Venue, error) {
+	v, err := s.venueRepo.Save(v)
+	if err != nil {
+		log.Printf("VenueService -> Save -> s.venueRepo.Save: %s", err)
+		return domain.Venue{}, err
+	}
+	return v, nil
+}
+
+func (s venueService) Update(v domain.Venue) (domain.Venue, error) {
+	v, err := s.venueRepo.Update(v)
+	if err != nil {
+		log.Printf("VenueService -> Update -> s.venueRepo.Update: %s", err)
+		return domain.Venue{}, err
+	}
+	return v, nil
+}
+
+func (s venueService) Find(id uint64) (interface{}, error) {
+	v, err := s.venueRepo.Find(id)
+	if err != nil {
+		log.Printf("VenueService -> Find -> s.venueRepo.Find: %s", err)
+		return nil, err
+	}
+	return v, nil
+}
+
+func (s venueService) FindList(filters database.VenueFilters, p domain.Pagination) ([]domain.Venue, uint64, error) {
+	venues, total, err := s.venueRepo.FindList(filters, p)
+	if err != nil {
+		log.Printf("VenueService -> FindList -> s.venueRepo.FindList: %s", err)
+		return nil, 0, err
+	}
+	return venues, total, nil
+}
+
+func (s venueService) Delete(id uint64) error {
+	err := s.venueRepo.Delete(id)
+	if err != nil {
+		log.Printf("VenueService -> Delete -> s.venueRepo.Delete: %s", err)
+		return err
+	}
+	return nil
+}
+
+func (s venueService) AddPhoto(venueId uint64, image string) (domain.VenuePhoto, error) {
+	photo, err := s.venueRepo.SavePhoto(domain.VenuePhoto{VenueId: venueId, Image: image})
+	if err != nil {
+		log.Printf("VenueService -> AddPhoto -> s.venueRepo.SavePhoto: %s", err)
+		return domain.VenuePhoto{}, err
+	}
+	return photo, nil
+}
+
+func (s venueService) FindPhoto(venueId, id uint64) (domain.VenuePhoto, error) {
+	return s.venueRepo.FindPhoto(venueId, id)
+}
+
+func (s venueService) DeletePhoto(id uint64) error {
+	err := s.venueRepo.DeletePhoto(id)
+	if err != nil {
+		log.Printf("VenueService -> DeletePhoto -> s.venueRepo.DeletePhoto: %s", err)
+		return err
+	}
+	return nil
+}
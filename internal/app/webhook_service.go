@@ -0,0 +1,423 @@
+package app
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/database"
+	"log"
+	"net"
+	"net/url"
+	"time"
+)
+
+const (
+	maxWebhooks = 10
+
+	// webhookInterval is how long a queued delivery may wait before it is sent
+	webhookInterval    = 10 * time.Second
+	webhookBatchSize   = 20
+	webhookLease       = 5 * time.Minute
+	maxWebhookAttempts = 8
+	// webhookLookupTimeout limits resolving the host of a webhook when it is saved
+	webhookLookupTimeout = 5 * time.Second
+)
+
+var (
+	ErrTooManyWebhooks         = fmt.Errorf("a user can't have more than %d webhooks", maxWebhooks)
+	ErrUnknownWebhookEventType = errors.New("unknown webhook event type")
+	ErrInvalidWebhookUrl       = errors.New("webhook url must be an absolute http or https url")
+	ErrWebhookUrlNotAllowed    = errors.New("webhook url must point to a public address")
+	ErrWebhookDeliveryNotFound = errors.New("webhook delivery not found")
+)
+
+// WebhookSender posts a delivery to the webhook, see the webhook package.
+type WebhookSender interface {
+	Send(w domain.Webhook, d domain.WebhookDelivery) (int, error)
+}
+
+type WebhookService interface {
+	Save(w domain.Webhook) (domain.Webhook, error)
+	Update(w domain.Webhook) (domain.Webhook, error)
+	Find(id uint64) (interface{}, error)
+	FindByUser(userId uint64) ([]domain.Webhook, error)
+	Delete(id uint64) error
+	FindDeliveries(webhookId uint64, p domain.Pagination) ([]domain.WebhookDelivery, uint64, error)
+	Redeliver(w domain.Webhook, deliveryId uint64) (domain.WebhookDelivery, error)
+	Ping(w domain.Webhook) (domain.WebhookDelivery, error)
+	Dispatch(t domain.WebhookEventType, ownerId uint64, data interface{})
+}
+
+type webhookService struct {
+	webhookRepo database.WebhookRepository
+	sender      WebhookSender
+}
+
+func NewWebhookService(wr database.WebhookRepository, ws WebhookSender) WebhookService {
+	return webhookService{
+		webhookRepo: wr,
+		sender:      ws,
+	}
+}
+
+// webhookPayload is the body of every delivery.
+type webhookPayload struct {
+	Type        domain.WebhookEventType `json:"type"`
+	CreatedDate time.Time               `json:"createdDate"`
+	Data        interface{}             `json:"data"`
+}
+
+// Save generates the secret of the webhook unless one is given.
+func (s webhookService) Save(w domain.Webhook) (domain.Webhook, error) {
+	err := validateWebhook(w)
+	if err != nil {
+		return domain.Webhook{}, err
+	}
+
+	count, err := s.webhookRepo.CountByUser(w.UserId)
+	if err != nil {
+		log.Printf("WebhookService -> Save -> s.webhookRepo.CountByUser: %s", err)
+		return domain.Webhook{}, err
+	}
+	if count >= maxWebhooks {
+		return domain.Webhook{}, ErrTooManyWebhooks
+	}
+
+	if w.Secret == "" {
+		w.Secret, err = webhookSecret()
+		if err != nil {
+			return domain.Webhook{}, err
+		}
+	}
+	w, err = s.webhookRepo.Save(w)
+	if err != nil {
+		log.Printf("WebhookService -> Save -> s.webhookRepo.Save: %s", err)
+		return domain.Webhook{}, err
+	}
+	return w, nil
+}
+
+func (s webhookService) Update(w domain.Webhook) (domain.Webhook, error) {
+	err := validateWebhook(w)
+	if err != nil {
+		return domain.Webhook{}, err
+	}
+
+	w, err = s.webhookRepo.Update(w)
+	if err != nil {
+		log.Printf("WebhookService -> Update -> s.webhookRepo.Update: %s", err)
+		return domain.Webhook{}, err
+	}
+	return w, nil
+}
+
+func (s webhookService) Find(id uint64) (interface{}, error) {
+	w, err := s.webhookRepo.Find(id)
+	if err != nil {
+		log.Printf("WebhookService -> Find -> s.webhookRepo.Find: %s", err)
+		return nil, err
+	}
+	return w, nil
+}
+
+func (s webhookService) FindByUser(userId uint64) ([]domain.Webhook, error) {
+	webhooks, err := s.webhookRepo.FindByUser(userId)
+	if err != nil {
+		log.Printf("WebhookService -> FindByUser -> s.webhookRepo.FindByUser: %s", err)
+		return nil, err
+	}
+	return webhooks, nil
+}
+
+func (s webhookService) Delete(id uint64) error {
+	err := s.webhookRepo.Delete(id)
+	if err != nil {
+		log.Printf("WebhookService -> Delete -> s.webhookRepo.Delete: %s", err)
+		return err
+	}
+	return nil
+}
+
+func (s webhookService) FindDeliveries(webhookId uint64, p domain.Pagination) ([]domain.WebhookDelivery, uint64, error) {
+	deliveries, total, err := s.webhookRepo.FindDeliveries(webhookId, p)
+	if err != nil {
+		log.Printf("WebhookService -> FindDeliveries -> s.webhookRepo.FindDeliveries: %s", err)
+		return nil, 0, err
+	}
+	return deliveries, total, nil
+}
+
+// Redeliver queues a new delivery of the same payload, the original one is kept as it was.
+func (s webhookService) Redeliver(w domain.Webhook, deliveryId uint64) (domain.WebhookDelivery, error) {
+	d, err := s.webhookRepo.FindDelivery(deliveryId)
+	if err != nil || d.WebhookId != w.Id {
+		return domain.WebhookDelivery{}, ErrWebhookDeliveryNotFound
+	}
+
+	d, err = s.webhookRepo.SaveDelivery(domain.WebhookDelivery{
+		WebhookId: w.Id,
+		EventType: d.EventType,
+		Payload:   d.Payload,
+	})
+	if err != nil {
+		log.Printf("WebhookService -> Redeliver -> s.webhookRepo.SaveDelivery: %s", err)
+		return domain.WebhookDelivery{}, err
+	}
+	return d, nil
+}
+
+// Ping sends a test delivery at once, even to an inactive webhook, and returns its result.
+// A failed ping is not retried.
+func (s webhookService) Ping(w domain.Webhook) (domain.WebhookDelivery, error) {
+	payload, err := json.Marshal(webhookPayload{
+		Type:        domain.PingWebhook,
+		CreatedDate: time.Now(),
+		Data:        map[string]uint64{"webhookId": w.Id},
+	})
+	if err != nil {
+		return domain.WebhookDelivery{}, err
+	}
+
+	d, err := s.webhookRepo.SaveDelivery(domain.WebhookDelivery{
+		WebhookId: w.Id,
+		EventType: domain.PingWebhook,
+		Payload:   string(payload),
+	})
+	if err != nil {
+		log.Printf("WebhookService -> Ping -> s.webhookRepo.SaveDelivery: %s", err)
+		return domain.WebhookDelivery{}, err
+	}
+
+	d = attemptDelivery(s.sender, w, d)
+	if d.Status == domain.PendingWebhookDeliveryStatus {
+		d.Status = domain.FailedWebhookDeliveryStatus
+	}
+	err = s.webhookRepo.UpdateDelivery(d)
+	if err != nil {
+		log.Printf("WebhookService -> Ping -> s.webhookRepo.UpdateDelivery: %s", err)
+		return domain.WebhookDelivery{}, err
+	}
+	return d, nil
+}
+
+// Dispatch queues a delivery of the change for every webhook subscribed to it, they are
+// sent by the WebhookDispatcher. Failures are only logged, so they never fail the change itself.
+func (s webhookService) Dispatch(t domain.WebhookEventType, ownerId uint64, data interface{}) {
+	webhooks, err := s.webhookRepo.FindSubscribed(t, ownerId)
+	if err != nil || len(webhooks) == 0 {
+		return
+	}
+
+	payload, err := json.Marshal(webhookPayload{Type: t, CreatedDate: time.Now(), Data: data})
+	if err != nil {
+		log.Printf("WebhookService -> Dispatch -> json.Marshal: %s", err)
+		return
+	}
+	for _, w := range webhooks {
+		_, err = s.webhookRepo.SaveDelivery(domain.WebhookDelivery{
+			WebhookId: w.Id,
+			EventType: t,
+			Payload:   string(payload),
+		})
+		if err != nil {
+			log.Printf("WebhookService -> Dispatch -> s.webhookRepo.SaveDelivery: %s", err)
+		}
+	}
+}
+
+// validateWebhook also resolves the host of the url, webhooks pointing to the internal
+// network are refused. The sender checks the address again on every delivery, as the
+// host may be resolved differently later.
+func validateWebhook(w domain.Webhook) error {
+	u, err := url.Parse(w.Url)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return ErrInvalidWebhookUrl
+	}
+	err = checkWebhookHost(u.Hostname())
+	if err != nil {
+		return err
+	}
+	for _, t := range w.Events {
+		if !domain.IsWebhookEventType(t) {
+			return fmt.Errorf("%w: %s", ErrUnknownWebhookEventType, t)
+		}
+	}
+	return nil
+}
+
+func checkWebhookHost(host string) error {
+	if ip := net.ParseIP(host); ip != nil {
+		if !domain.IsPublicAddress(ip) {
+			return ErrWebhookUrlNotAllowed
+		}
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), webhookLookupTimeout)
+	defer cancel()
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil || len(addrs) == 0 {
+		return fmt.Errorf("%w: the host can't be resolved", ErrInvalidWebhookUrl)
+	}
+	for _, addr := range addrs {
+		if !domain.IsPublicAddress(addr.IP) {
+			return ErrWebhookUrlNotAllowed
+		}
+	}
+	return nil
+}
+
+func webhookSecret() (string, error) {
+	b := make([]byte, 24)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
+
+// attemptDelivery sends the delivery once and records the result. A failed delivery is
+// retried with an exponential backoff, after maxWebhookAttempts it has failed for good.
+func attemptDelivery(sender WebhookSender, w domain.Webhook, d domain.WebhookDelivery) domain.WebhookDelivery {
+	code, err := sender.Send(w, d)
+	d.Attempts++
+	d.ResponseCode = code
+	if err == nil {
+		now := time.Now()
+		d.Status, d.Error, d.DeliveredDate = domain.DeliveredWebhookDeliveryStatus, "", &now
+		return d
+	}
+
+	d.Error = err.Error()
+	if d.Attempts >= maxWebhookAttempts {
+		d.Status = domain.FailedWebhookDeliveryStatus
+	} else {
+		d.NextAttemptDate = time.Now().Add(time.Minute << d.Attempts)
+	}
+	return d
+}
+
+// WebhookDispatcher sends the queued webhook deliveries in the background.
+type WebhookDispatcher interface {
+	Run(ctx context.Context)
+}
+
+type webhookDispatcher struct {
+	webhookRepo database.WebhookRepository
+	sender      WebhookSender
+}
+
+func NewWebhookDispatcher(wr database.WebhookRepository, ws WebhookSender) WebhookDispatcher {
+	return webhookDispatcher{
+		webhookRepo: wr,
+		sender:      ws,
+	}
+}
+
+// Run sends the due deliveries every webhookInterval, until the context is done.
+func (d webhookDispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(webhookInterval)
+	defer ticker.Stop()
+
+	for {
+		d.sendDue()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (d webhookDispatcher) sendDue() {
+	for {
+		deliveries, err := d.webhookRepo.ClaimDueDeliveries(webhookBatchSize, webhookLease)
+		if err != nil {
+			return
+		}
+
+		webhooks := make(map[uint64]domain.Webhook)
+		for _, delivery := range deliveries {
+			w, ok := webhooks[delivery.WebhookId]
+			if !ok {
+				w, err = d.webhookRepo.Find(delivery.WebhookId)
+				if err != nil {
+					// deleted meanwhile, the deliveries went with it
+					continue
+				}
+				webhooks[w.Id] = w
+			}
+			if !w.Active {
+				delivery.Status, delivery.Error = domain.FailedWebhookDeliveryStatus, "the webhook is inactive"
+			} else {
+				delivery = attemptDelivery(d.sender, w, delivery)
+			}
+
+			err = d.webhookRepo.UpdateDelivery(delivery)
+			if err != nil {
+				log.Printf("WebhookDispatcher -> sendDue -> d.webhookRepo.UpdateDelivery: %s", err)
+			}
+		}
+		if len(deliveries) < webhookBatchSize {
+			return
+		}
+	}
+}
+
+// webhookEvent is the data of the event.* deliveries.
+type webhookEvent struct {
+	Id              uint64             `json:"id"`
+	UserId          uint64             `json:"userId"`
+	Title           string             `json:"title"`
+	Description     string             `json:"description"`
+	Status          domain.EventStatus `json:"status"`
+	City            string             `json:"city"`
+	Location        string             `json:"location"`
+	VenueId         *uint64            `json:"venueId"`
+	Date            time.Time          `json:"date"`
+	EndDate         *time.Time         `json:"endDate"`
+	Lat             float64            `json:"lat"`
+	Lon             float64            `json:"lon"`
+	Capacity        *uint64            `json:"capacity"`
+	AttendeesPublic bool               `json:"attendeesPublic"`
+	CategoryId      *uint64            `json:"categoryId"`
+	Tags            []string           `json:"tags"`
+	CreatedDate     time.Time          `json:"createdDate"`
+	UpdatedDate     time.Time          `json:"updatedDate"`
+}
+
+// webhookSubscription is the data of the subscription.* deliveries.
+type webhookSubscription struct {
+	EventId uint64                    `json:"eventId"`
+	UserId  uint64                    `json:"userId"`
+	Status  domain.SubscriptionStatus `json:"status,omitempty"`
+	Rsvp    domain.RsvpStatus         `json:"rsvp,omitempty"`
+}
+
+func eventWebhookData(e domain.Event) webhookEvent {
+	return webhookEvent{
+		Id:              e.Id,
+		UserId:          e.UserId,
+		Title:           e.Title,
+		Description:     e.Description,
+		Status:          e.Status,
+		City:            e.City,
+		Location:        e.Location,
+		VenueId:         e.VenueId,
+		Date:            e.Date,
+		EndDate:         e.EndDate,
+		Lat:             e.Lat,
+		Lon:             e.Lon,
+		Capacity:        e.Capacity,
+		AttendeesPublic: e.AttendeesPublic,
+		CategoryId:      e.CategoryId,
+		Tags:            e.Tags,
+		CreatedDate:     e.CreatedDate,
+		UpdatedDate:     e.UpdatedDate,
+	}
+}
@@ -0,0 +1,34 @@
+package app
+
+import (
+	"errors"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"testing"
+)
+
+func TestValidateWebhook(t *testing.T) {
+	tests := []struct {
+		url  string
+		want error
+	}{
+		{"https://93.184.216.34/hooks", nil},
+		{"http://127.0.0.1:8080/hooks", ErrWebhookUrlNotAllowed},
+		{"http://10.0.0.5/hooks", ErrWebhookUrlNotAllowed},
+		{"http://192.168.1.1/hooks", ErrWebhookUrlNotAllowed},
+		{"http://100.64.0.1/hooks", ErrWebhookUrlNotAllowed},
+		{"http://169.254.169.254/latest/meta-data", ErrWebhookUrlNotAllowed},
+		{"http://0.0.0.0/hooks", ErrWebhookUrlNotAllowed},
+		{"http://[::1]/hooks", ErrWebhookUrlNotAllowed},
+		{"http://[fd00::1]/hooks", ErrWebhookUrlNotAllowed},
+		{"http://[::ffff:127.0.0.1]/hooks", ErrWebhookUrlNotAllowed},
+		{"http://localhost/hooks", ErrWebhookUrlNotAllowed},
+		{"ftp://93.184.216.34/hooks", ErrInvalidWebhookUrl},
+		{"https:///hooks", ErrInvalidWebhookUrl},
+	}
+	for _, tt := range tests {
+		err := validateWebhook(domain.Webhook{Url: tt.url, Events: []domain.WebhookEventType{domain.EventUpdatedWebhook}})
+		if !errors.Is(err, tt.want) {
+			t.Errorf("validateWebhook(%s) = %v, want %v", tt.url, err, tt.want)
+		}
+	}
+}
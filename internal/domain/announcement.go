@@ -0,0 +1,15 @@
+package domain
+
+import "time"
+
+// Announcement is a message of the organizer to the subscribers of the event.
+type Announcement struct {
+	Id          uint64
+	EventId     uint64
+	UserId      uint64
+	Title       string
+	Body        string
+	Channel     NotificationChannel
+	Recipients  uint64 // how many subscribers it was sent to
+	CreatedDate time.Time
+}
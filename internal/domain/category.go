@@ -0,0 +1,29 @@
+package domain
+
+import "time"
+
+type Category struct {
+	Id          uint64
+	ParentId    *uint64
+	Name        string
+	Slug        string
+	CreatedDate time.Time
+	UpdatedDate time.Time
+}
+
+type CategoryFacet struct {
+	CategoryId uint64
+	Name       string
+	Count      uint64
+}
+
+type TagFacet struct {
+	Tag   string
+	Count uint64
+}
+
+// EventFacets are the numbers of events per category and tag for a set of filters.
+type EventFacets struct {
+	Categories []CategoryFacet
+	Tags       []TagFacet
+}
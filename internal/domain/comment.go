@@ -0,0 +1,17 @@
+package domain
+
+import "time"
+
+// Comment is made on an event, a reply has the top-level comment it answers as its parent.
+// Replies can't be replied to.
+type Comment struct {
+	Id          uint64
+	EventId     uint64
+	UserId      uint64
+	ParentId    *uint64
+	Body        string
+	Author      User      // filled when read
+	Replies     []Comment // filled for the top-level comments of a list
+	CreatedDate time.Time
+	UpdatedDate *time.Time // set once the comment has been edited
+}
@@ -5,29 +5,100 @@ import (
 )
 
 type Event struct {
-	Id          uint64
-	UserId      uint64
-	Title       string
-	Description string
-	Status      EventStatus
-	Image       string
-	City        string
-	Location    string
-	Date        time.Time
-	Lat         float64
-	Lon         float64
-	CreatedDate time.Time
-	UpdatedDate time.Time
-	DeletedDate *time.Time
+	Id               uint64
+	UserId           uint64
+	Title            string
+	Description      string
+	Status           EventStatus
+	Image            string
+	City             string
+	Location         string
+	VenueId          *uint64
+	Date             time.Time
+	EndDate          *time.Time
+	Lat              float64
+	Lon              float64
+	LocationMismatch bool // the coordinates are far from the city
+	Capacity         *uint64
+	AttendeesPublic  bool
+	CategoryId       *uint64
+	Tags             []string
+	ExternalUid      string
+	CreatedDate      time.Time
+	UpdatedDate      time.Time
+	UpdatedBy        uint64 // the user making an update, recorded in its revision
+	DeletedDate      *time.Time
+	DistanceKm       *float64 // set by geo searches only
+	SearchRank       *float64 // set by full-text searches only
+	Snippet          string   // matched fragment of the HTML-escaped description with <mark> highlighting
+	RatingAverage    float64  // of the reviews, see Review
+	RatingCount      uint64
+}
+
+// IsUpcoming tells whether the event is still to take place: not cancelled, done or started.
+func (e Event) IsUpcoming(now time.Time) bool {
+	return e.Status == NewEventStatus && e.Date.After(now)
+}
+
+// IsDone tells whether the event is over. Events still NEW are done once they have ended.
+func (e Event) IsDone(now time.Time) bool {
+	if e.Status != NewEventStatus {
+		return e.Status == DoneEventStatus
+	}
+	end := e.Date
+	if e.EndDate != nil {
+		end = *e.EndDate
+	}
+	return end.Before(now)
 }
 
 type EventStatus string
 
 const (
-	NewEventStatus  EventStatus = "NEW"
-	DoneEventStatus EventStatus = "DONE"
+	NewEventStatus       EventStatus = "NEW"
+	DoneEventStatus      EventStatus = "DONE"
+	CancelledEventStatus EventStatus = "CANCELLED"
 )
 
 func (e Event) GetEventId() uint64 {
 	return e.Id
 }
+
+type EventImportStatus string
+
+const (
+	CreatedEventImportStatus EventImportStatus = "CREATED"
+	UpdatedEventImportStatus EventImportStatus = "UPDATED"
+	SkippedEventImportStatus EventImportStatus = "SKIPPED"
+)
+
+type EventImport struct {
+	Event Event
+	Error error // set when the item could not be parsed
+}
+
+type EventImportResult struct {
+	Uid     string
+	Title   string
+	EventId uint64
+	Status  EventImportStatus
+	Error   string
+}
+
+type Suggestion struct {
+	Value string
+	Count uint64
+}
+
+type EventSuggestions struct {
+	Cities    []Suggestion
+	Locations []Suggestion
+	Titles    []Suggestion
+}
+
+// CalendarDay holds the number of events on a day and, in the expanded mode, the first of them.
+type CalendarDay struct {
+	Date   string
+	Count  uint64
+	Events []Event
+}
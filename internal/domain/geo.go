@@ -0,0 +1,52 @@
+package domain
+
+import (
+	"errors"
+	"math"
+)
+
+const earthRadiusKm = 6371.0
+
+var ErrPlaceNotFound = errors.New("place not found")
+
+type GeoPoint struct {
+	Lat float64
+	Lon float64
+}
+
+// DistanceKm is the great-circle distance between the points.
+func (p GeoPoint) DistanceKm(o GeoPoint) float64 {
+	lat1, lat2 := p.Lat*math.Pi/180, o.Lat*math.Pi/180
+	dLat, dLon := lat2-lat1, (o.Lon-p.Lon)*math.Pi/180
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKm * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+// GeoPlace is a result of geocoding.
+type GeoPlace struct {
+	Name    string
+	City    string
+	Country string
+	Point   GeoPoint
+}
+
+type BoundingBox struct {
+	MinLat float64
+	MinLon float64
+	MaxLat float64
+	MaxLon float64
+}
+
+func (b BoundingBox) Center() GeoPoint {
+	return GeoPoint{
+		Lat: (b.MinLat + b.MaxLat) / 2,
+		Lon: (b.MinLon + b.MaxLon) / 2,
+	}
+}
+
+type EventCluster struct {
+	Center  GeoPoint
+	Bounds  BoundingBox
+	Count   uint64
+	EventId uint64 // set for single event clusters
+}
@@ -0,0 +1,28 @@
+package domain
+
+import "time"
+
+type MailStatus string
+
+const (
+	PendingMailStatus MailStatus = "PENDING"
+	SentMailStatus    MailStatus = "SENT"
+	DeadMailStatus    MailStatus = "DEAD" // gave up after too many failed attempts
+)
+
+const DefaultLocale = "en"
+
+// Mail is a rendered email waiting in the outgoing queue.
+type Mail struct {
+	Id              uint64
+	To              string
+	Subject         string
+	Html            string
+	Text            string
+	Status          MailStatus
+	Attempts        uint
+	NextAttemptDate time.Time
+	LastError       string
+	CreatedDate     time.Time
+	SentDate        *time.Time
+}
@@ -0,0 +1,62 @@
+package domain
+
+import "time"
+
+type NotificationType string
+
+const (
+	EventUpdatedNotification      NotificationType = "EVENT_UPDATED"
+	EventCancelledNotification    NotificationType = "EVENT_CANCELLED"
+	NewCommentNotification        NotificationType = "NEW_COMMENT"
+	WaitlistPromotedNotification  NotificationType = "WAITLIST_PROMOTED"
+	SavedSearchMatchNotification  NotificationType = "SAVED_SEARCH_MATCH"
+	SavedSearchDigestNotification NotificationType = "SAVED_SEARCH_DIGEST"
+	EventReminderNotification     NotificationType = "EVENT_REMINDER"
+	EventAnnouncementNotification NotificationType = "EVENT_ANNOUNCEMENT"
+)
+
+// NotificationTypes are the types users can turn off in their preferences.
+var NotificationTypes = []NotificationType{
+	EventUpdatedNotification,
+	EventCancelledNotification,
+	NewCommentNotification,
+	WaitlistPromotedNotification,
+	SavedSearchMatchNotification,
+	SavedSearchDigestNotification,
+	EventReminderNotification,
+	EventAnnouncementNotification,
+}
+
+func IsNotificationType(t NotificationType) bool {
+	for _, known := range NotificationTypes {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+type NotificationChannel string
+
+const (
+	InAppNotificationChannel NotificationChannel = "IN_APP"
+	EmailNotificationChannel NotificationChannel = "EMAIL"
+)
+
+// Notification is kept in the user's inbox whatever the channel, EMAIL ones are mailed as well.
+type Notification struct {
+	Id          uint64
+	UserId      uint64
+	EventId     uint64
+	Type        NotificationType
+	Channel     NotificationChannel // in-app when empty
+	Title       string
+	Body        string
+	ReadDate    *time.Time
+	CreatedDate time.Time
+}
+
+type NotificationPreference struct {
+	Type    NotificationType
+	Enabled bool
+}
@@ -0,0 +1,40 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrInvalidPushToken is returned by the push senders when the provider has rejected
+// the device token for good, e.g. the app was uninstalled.
+var ErrInvalidPushToken = errors.New("invalid push token")
+
+type PushPlatform string
+
+const (
+	WebPushPlatform     PushPlatform = "WEB"
+	AndroidPushPlatform PushPlatform = "ANDROID"
+	IosPushPlatform     PushPlatform = "IOS"
+)
+
+// PushDevice is registered for the session it was logged in with and goes away on logout.
+type PushDevice struct {
+	Id          uint64
+	UserId      uint64
+	SessionUUID uuid.UUID
+	Platform    PushPlatform
+	Token       string // FCM or APNs device token, the subscription endpoint for Web Push
+	P256dh      string // Web Push only
+	Auth        string // Web Push only
+	Locale      string
+	CreatedDate time.Time
+	UpdatedDate time.Time
+}
+
+type PushMessage struct {
+	Title string
+	Body  string
+	Data  map[string]string
+}
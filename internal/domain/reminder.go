@@ -0,0 +1,16 @@
+package domain
+
+import "time"
+
+// Reminder is sent to a subscriber Offset before the event starts. OccurrenceDate is the
+// event date the reminder was sent for, so moving the event schedules the reminders again.
+type Reminder struct {
+	EventId        uint64
+	UserId         uint64
+	Offset         time.Duration
+	OccurrenceDate time.Time
+	EventTitle     string
+	Location       string
+	City           string
+	SentDate       time.Time
+}
@@ -0,0 +1,24 @@
+package domain
+
+import "time"
+
+// Review is left by an attendee once the event is done, one per attendee. The organizer
+// may reply to it once.
+type Review struct {
+	Id          uint64
+	EventId     uint64
+	UserId      uint64
+	Rating      uint8 // 1-5
+	Body        string
+	Author      User // filled when read
+	Reply       *string
+	ReplyDate   *time.Time
+	CreatedDate time.Time
+	UpdatedDate *time.Time // set once the review has been edited
+}
+
+// Rating aggregates the reviews of an event or of all the events of an organizer.
+type Rating struct {
+	Average float64
+	Count   uint64
+}
@@ -0,0 +1,77 @@
+package domain
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EventRevision records the changes of a single update of an event. Revisions are never changed.
+type EventRevision struct {
+	Id          uint64
+	EventId     uint64
+	UserId      *uint64 // the user who made the update, none for the system
+	Changes     []EventChange
+	CreatedDate time.Time
+}
+
+// EventChange is a field changed by the update, with its previous and new values as text.
+// Dates are in RFC 3339, missing values are empty.
+type EventChange struct {
+	Field       string
+	From        string
+	To          string
+	Significant bool // the time, the place or the status, worth telling the subscribers in detail
+}
+
+// DiffEvents lists the fields of the event changed from a to b, in a fixed order.
+func DiffEvents(a, b Event) []EventChange {
+	var changes []EventChange
+	add := func(field, from, to string, significant bool) {
+		if from != to {
+			changes = append(changes, EventChange{Field: field, From: from, To: to, Significant: significant})
+		}
+	}
+
+	add("title", a.Title, b.Title, false)
+	add("description", a.Description, b.Description, false)
+	add("status", string(a.Status), string(b.Status), true)
+	add("date", formatRevisionTime(&a.Date), formatRevisionTime(&b.Date), true)
+	add("endDate", formatRevisionTime(a.EndDate), formatRevisionTime(b.EndDate), true)
+	add("venueId", formatRevisionId(a.VenueId), formatRevisionId(b.VenueId), false)
+	add("location", a.Location, b.Location, true)
+	add("city", a.City, b.City, true)
+	add("coordinates", formatRevisionCoordinates(a), formatRevisionCoordinates(b), false)
+	add("capacity", formatRevisionId(a.Capacity), formatRevisionId(b.Capacity), false)
+	add("attendeesPublic", strconv.FormatBool(a.AttendeesPublic), strconv.FormatBool(b.AttendeesPublic), false)
+	add("categoryId", formatRevisionId(a.CategoryId), formatRevisionId(b.CategoryId), false)
+	add("tags", formatRevisionTags(a.Tags), formatRevisionTags(b.Tags), false)
+	add("image", a.Image, b.Image, false)
+	return changes
+}
+
+func formatRevisionTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func formatRevisionId(id *uint64) string {
+	if id == nil {
+		return ""
+	}
+	return strconv.FormatUint(*id, 10)
+}
+
+func formatRevisionCoordinates(e Event) string {
+	return fmt.Sprintf("%g,%g", e.Lat, e.Lon)
+}
+
+func formatRevisionTags(tags []string) string {
+	sorted := append([]string(nil), tags...)
+	sort.Strings(sorted)
+	return strings.Join(sorted, ", ")
+}
@@ -0,0 +1,37 @@
+package domain
+
+import "time"
+
+type SavedSearchFrequency string
+
+const (
+	InstantSavedSearchFrequency SavedSearchFrequency = "INSTANT"
+	DailySavedSearchFrequency   SavedSearchFrequency = "DAILY"
+)
+
+// SavedSearch is a query of the event list kept under the user's account, the user
+// is notified about the new events matching it.
+type SavedSearch struct {
+	Id             uint64
+	UserId         uint64
+	Name           string
+	Search         string
+	City           string
+	Point          *GeoPoint
+	RadiusKm       float64 // set together with Point
+	CategoryId     *uint64
+	Channel        NotificationChannel
+	Frequency      SavedSearchFrequency
+	LastDigestDate *time.Time
+	CreatedDate    time.Time
+	UpdatedDate    time.Time
+}
+
+// SavedSearchMatch is a new event found by a saved search, waiting for the daily digest.
+type SavedSearchMatch struct {
+	SavedSearchId uint64
+	EventId       uint64
+	EventTitle    string
+	EventDate     time.Time
+	CreatedDate   time.Time
+}
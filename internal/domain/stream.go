@@ -0,0 +1,22 @@
+package domain
+
+import "time"
+
+type StreamEventType string
+
+const (
+	EventUpdatedStreamEvent   StreamEventType = "event.updated"
+	EventCancelledStreamEvent StreamEventType = "event.cancelled"
+	AttendeesStreamEvent      StreamEventType = "event.attendees"
+	NotificationStreamEvent   StreamEventType = "notification"
+)
+
+// StreamEvent is a change pushed to a user over the stream. The events are kept for a while,
+// so the clients can resume from the last one they have received.
+type StreamEvent struct {
+	Id          uint64
+	UserId      uint64
+	Type        StreamEventType
+	Data        string // JSON
+	CreatedDate time.Time
+}
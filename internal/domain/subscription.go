@@ -0,0 +1,42 @@
+package domain
+
+import (
+	"time"
+)
+
+type SubscriptionStatus string
+
+const (
+	ActiveSubscriptionStatus     SubscriptionStatus = "ACTIVE"
+	WaitlistedSubscriptionStatus SubscriptionStatus = "WAITLISTED"
+)
+
+type RsvpStatus string
+
+const (
+	GoingRsvpStatus    RsvpStatus = "GOING"
+	MaybeRsvpStatus    RsvpStatus = "MAYBE"
+	DeclinedRsvpStatus RsvpStatus = "DECLINED"
+)
+
+type Subscription struct {
+	EventId     uint64
+	UserId      uint64
+	Status      SubscriptionStatus
+	Rsvp        RsvpStatus
+	Position    uint64 // position in the waitlist, 0 for active subscriptions
+	CreatedDate time.Time
+}
+
+type Attendee struct {
+	User           User
+	Rsvp           RsvpStatus
+	SubscribedDate time.Time
+}
+
+type AttendeeCounts struct {
+	Going      uint64
+	Maybe      uint64
+	Declined   uint64
+	Waitlisted uint64
+}
@@ -0,0 +1,19 @@
+package domain
+
+import (
+	"strings"
+	"unicode"
+)
+
+// SanitizeText trims the free text entered by users and drops the control characters,
+// except for the line breaks and tabs.
+func SanitizeText(s string) string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	s = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) && r != '\n' && r != '\t' {
+			return -1
+		}
+		return r
+	}, s)
+	return strings.TrimSpace(s)
+}
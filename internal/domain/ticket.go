@@ -0,0 +1,21 @@
+package domain
+
+import (
+	"time"
+)
+
+type Ticket struct {
+	Id            uint64
+	EventId       uint64
+	UserId        uint64
+	Nonce         string
+	Code          string
+	CheckedInDate *time.Time
+	CheckedInBy   *uint64
+	CreatedDate   time.Time
+}
+
+type CheckInStats struct {
+	Issued    uint64
+	CheckedIn uint64
+}
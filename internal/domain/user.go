@@ -5,16 +5,18 @@ import (
 )
 
 type User struct {
-	Id          uint64
-	Email       string
-	Password    string
-	FirstName   string
-	SecondName  string
-	Image       string
-	Role        Role
-	CreatedDate time.Time
-	UpdatedDate time.Time
-	DeletedDate *time.Time
+	Id            uint64
+	Email         string
+	Password      string
+	FirstName     string
+	SecondName    string
+	Image         string
+	Role          Role
+	CalendarToken string
+	Locale        string // language of the emails
+	CreatedDate   time.Time
+	UpdatedDate   time.Time
+	DeletedDate   *time.Time
 }
 
 type Role string
@@ -0,0 +1,25 @@
+package domain
+
+import "time"
+
+type Venue struct {
+	Id          uint64
+	UserId      uint64
+	Name        string
+	Address     string
+	City        string
+	Lat         float64
+	Lon         float64
+	Capacity    *uint64
+	Photos      []VenuePhoto
+	CreatedDate time.Time
+	UpdatedDate time.Time
+	DeletedDate *time.Time
+}
+
+type VenuePhoto struct {
+	Id          uint64
+	VenueId     uint64
+	Image       string
+	CreatedDate time.Time
+}
@@ -0,0 +1,90 @@
+package domain
+
+import (
+	"net"
+	"time"
+)
+
+type WebhookEventType string
+
+const (
+	EventCreatedWebhook        WebhookEventType = "event.created"
+	EventUpdatedWebhook        WebhookEventType = "event.updated"
+	EventDeletedWebhook        WebhookEventType = "event.deleted"
+	SubscriptionCreatedWebhook WebhookEventType = "subscription.created"
+	SubscriptionDeletedWebhook WebhookEventType = "subscription.deleted"
+	PingWebhook                WebhookEventType = "ping" // sent on request only, can't be subscribed to
+)
+
+// WebhookEventTypes are the types webhooks can be subscribed to.
+var WebhookEventTypes = []WebhookEventType{
+	EventCreatedWebhook,
+	EventUpdatedWebhook,
+	EventDeletedWebhook,
+	SubscriptionCreatedWebhook,
+	SubscriptionDeletedWebhook,
+}
+
+func IsWebhookEventType(t WebhookEventType) bool {
+	for _, known := range WebhookEventTypes {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+// Webhook receives the changes of the events of its owner, the webhooks of admins
+// receive the changes of all events.
+type Webhook struct {
+	Id          uint64
+	UserId      uint64
+	Url         string
+	Secret      string // signs the deliveries
+	Events      []WebhookEventType
+	Active      bool
+	CreatedDate time.Time
+	UpdatedDate time.Time
+}
+
+func (w Webhook) Subscribed(t WebhookEventType) bool {
+	for _, e := range w.Events {
+		if e == t {
+			return true
+		}
+	}
+	return false
+}
+
+// IsPublicAddress tells whether webhooks may be delivered to the address. Private, loopback,
+// link-local and other non-routable addresses are refused, so webhooks can't be used to
+// reach the internal network.
+func IsPublicAddress(ip net.IP) bool {
+	return ip.IsGlobalUnicast() && !ip.IsPrivate() && !ip.IsLoopback() && !ip.IsLinkLocalUnicast() &&
+		!sharedAddressSpace.Contains(ip)
+}
+
+// sharedAddressSpace is the carrier-grade NAT range (RFC 6598), not covered by net.IP.IsPrivate.
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+type WebhookDeliveryStatus string
+
+const (
+	PendingWebhookDeliveryStatus   WebhookDeliveryStatus = "PENDING"
+	DeliveredWebhookDeliveryStatus WebhookDeliveryStatus = "DELIVERED"
+	FailedWebhookDeliveryStatus    WebhookDeliveryStatus = "FAILED" // gave up after too many attempts
+)
+
+type WebhookDelivery struct {
+	Id              uint64
+	WebhookId       uint64
+	EventType       WebhookEventType
+	Payload         string
+	Status          WebhookDeliveryStatus
+	Attempts        uint
+	NextAttemptDate time.Time
+	ResponseCode    int // of the last attempt, 0 when there was no response
+	Error           string
+	CreatedDate     time.Time
+	DeliveredDate   *time.Time
+}
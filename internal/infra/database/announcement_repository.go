@@ -0,0 +1,146 @@
+package database
+
+import (
+	"log"
+	"time"
+
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"github.com/upper/db/v4"
+)
+
+const AnnouncementsTableName = "announcements"
+
+type announcement struct {
+	Id          uint64                     `db:"id,omitempty"`
+	EventId     uint64                     `db:"event_id"`
+	UserId      uint64                     `db:"user_id"`
+	Title       string                     `db:"title"`
+	Body        string                     `db:"body"`
+	Channel     domain.NotificationChannel `db:"channel"`
+	Recipients  uint64                     `db:"recipients"`
+	CreatedDate time.Time                  `db:"created_date"`
+}
+
+type AnnouncementRepository interface {
+	SaveLimited(a domain.Announcement, since time.Time, limit uint64) (domain.Announcement, bool, error)
+	FindByEvent(eventId uint64, p domain.Pagination) ([]domain.Announcement, uint64, error)
+	FindSince(eventId uint64, since time.Time) ([]domain.Announcement, error)
+}
+
+type announcementRepository struct {
+	coll db.Collection
+	sess db.Session
+}
+
+func NewAnnouncementRepository(dbSession db.Session) AnnouncementRepository {
+	return announcementRepository{
+		coll: dbSession.Collection(AnnouncementsTableName),
+		sess: dbSession,
+	}
+}
+
+// SaveLimited stores the announcement unless the event has had limit announcements since
+// the date already, it reports whether the announcement was stored. The event row is locked
+// meanwhile, so concurrent announcements are counted one after another.
+func (r announcementRepository) SaveLimited(a domain.Announcement, since time.Time, limit uint64) (domain.Announcement, bool, error) {
+	m := r.mapDomainToModel(a)
+	saved := false
+	err := r.sess.Tx(func(tx db.Session) error {
+		var evn struct {
+			Id uint64 `db:"id"`
+		}
+		err := tx.SQL().
+			Iterator("SELECT id FROM events WHERE id = ? FOR UPDATE", a.EventId).
+			One(&evn)
+		if err != nil {
+			return err
+		}
+
+		count, err := tx.Collection(AnnouncementsTableName).
+			Find(db.Cond{"event_id": a.EventId, "created_date >": since}).
+			Count()
+		if err != nil || count >= limit {
+			return err
+		}
+
+		m.CreatedDate = time.Now()
+		err = tx.Collection(AnnouncementsTableName).InsertReturning(&m)
+		saved = err == nil
+		return err
+	})
+	if err != nil {
+		log.Printf("AnnouncementRepository -> SaveLimited -> r.sess.Tx: %s", err)
+		return domain.Announcement{}, false, err
+	}
+	if !saved {
+		return domain.Announcement{}, false, nil
+	}
+	return r.mapModelToDomain(m), true, nil
+}
+
+// FindByEvent returns a page of the announcements of the event, the newest first.
+func (r announcementRepository) FindByEvent(eventId uint64, p domain.Pagination) ([]domain.Announcement, uint64, error) {
+	query := r.coll.Find(db.Cond{"event_id": eventId})
+	total, err := query.Count()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var announcements []announcement
+	err = query.
+		OrderBy("-created_date", "-id").
+		Paginate(uint(p.CountPerPage)).
+		Page(uint(p.Page)).
+		All(&announcements)
+	if err != nil {
+		return nil, 0, err
+	}
+	return r.mapModelToDomainCollection(announcements), total, nil
+}
+
+// FindSince returns the announcements of the event made after the date, the oldest first.
+func (r announcementRepository) FindSince(eventId uint64, since time.Time) ([]domain.Announcement, error) {
+	var announcements []announcement
+	err := r.coll.
+		Find(db.Cond{"event_id": eventId, "created_date >": since}).
+		OrderBy("created_date").
+		All(&announcements)
+	if err != nil {
+		return nil, err
+	}
+	return r.mapModelToDomainCollection(announcements), nil
+}
+
+func (r announcementRepository) mapDomainToModel(d domain.Announcement) announcement {
+	return announcement{
+		Id:          d.Id,
+		EventId:     d.EventId,
+		UserId:      d.UserId,
+		Title:       d.Title,
+		Body:        d.Body,
+		Channel:     d.Channel,
+		Recipients:  d.Recipients,
+		CreatedDate: d.CreatedDate,
+	}
+}
+
+func (r announcementRepository) mapModelToDomain(m announcement) domain.Announcement {
+	return domain.Announcement{
+		Id:          m.Id,
+		EventId:     m.EventId,
+		UserId:      m.UserId,
+		Title:       m.Title,
+		Body:        m.Body,
+		Channel:     m.Channel,
+		Recipients:  m.Recipients,
+		CreatedDate: m.CreatedDate,
+	}
+}
+
+func (r announcementRepository) mapModelToDomainCollection(announcements []announcement) []domain.Announcement {
+	result := make([]domain.Announcement, len(announcements))
+	for i, a := range announcements {
+		result[i] = r.mapModelToDomain(a)
+	}
+	return result
+}
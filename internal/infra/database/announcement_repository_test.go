@@ -0,0 +1,56 @@
+package database
+
+import (
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAnnouncementRepository_ConcurrentSaveLimited(t *testing.T) {
+	sess := testSession(t)
+	repo := NewAnnouncementRepository(sess)
+
+	owner := createTestUser(t, sess)
+	evn := createTestEvent(t, sess, owner.Id, nil)
+
+	const (
+		limit = 3
+		calls = 10
+	)
+	since := time.Now().Add(-time.Hour)
+	var saved atomic.Uint64
+	var wg sync.WaitGroup
+	for i := 0; i < calls; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, ok, err := repo.SaveLimited(domain.Announcement{
+				EventId: evn.Id,
+				UserId:  owner.Id,
+				Title:   "Doors open earlier",
+				Body:    "Doors open at 18:00",
+				Channel: domain.EmailNotificationChannel,
+			}, since, limit)
+			if err != nil {
+				t.Errorf("SaveLimited: %s", err)
+			}
+			if ok {
+				saved.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if saved.Load() != limit {
+		t.Errorf("saved = %d, want %d", saved.Load(), limit)
+	}
+	recent, err := repo.FindSince(evn.Id, since)
+	if err != nil {
+		t.Fatalf("FindSince: %s", err)
+	}
+	if len(recent) != limit {
+		t.Errorf("stored = %d, want %d", len(recent), limit)
+	}
+}
@@ -0,0 +1,122 @@
+package database
+
+import (
+	"time"
+
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"github.com/upper/db/v4"
+)
+
+const CategoriesTableName = "categories"
+
+type category struct {
+	Id          uint64    `db:"id,omitempty"`
+	ParentId    *uint64   `db:"parent_id"`
+	Name        string    `db:"name"`
+	Slug        string    `db:"slug"`
+	CreatedDate time.Time `db:"created_date,omitempty"`
+	UpdatedDate time.Time `db:"updated_date,omitempty"`
+}
+
+type CategoryRepository interface {
+	Save(c domain.Category) (domain.Category, error)
+	Update(c domain.Category) (domain.Category, error)
+	Find(id uint64) (domain.Category, error)
+	FindBySlug(slug string) (domain.Category, error)
+	FindAll() ([]domain.Category, error)
+	CountChildren(id uint64) (uint64, error)
+	Delete(id uint64) error
+}
+
+type categoryRepository struct {
+	coll db.Collection
+}
+
+func NewCategoryRepository(dbSession db.Session) CategoryRepository {
+	return categoryRepository{
+		coll: dbSession.Collection(CategoriesTableName),
+	}
+}
+
+func (r categoryRepository) Save(c domain.Category) (domain.Category, error) {
+	cat := r.mapDomainToModel(c)
+	cat.CreatedDate, cat.UpdatedDate = time.Now(), time.Now()
+	err := r.coll.InsertReturning(&cat)
+	if err != nil {
+		return domain.Category{}, err
+	}
+	return r.mapModelToDomain(cat), nil
+}
+
+func (r categoryRepository) Update(c domain.Category) (domain.Category, error) {
+	cat := r.mapDomainToModel(c)
+	cat.UpdatedDate = time.Now()
+	err := r.coll.Find(db.Cond{"id": cat.Id}).Update(&cat)
+	if err != nil {
+		return domain.Category{}, err
+	}
+	return r.mapModelToDomain(cat), nil
+}
+
+func (r categoryRepository) Find(id uint64) (domain.Category, error) {
+	var cat category
+	err := r.coll.Find(db.Cond{"id": id}).One(&cat)
+	if err != nil {
+		return domain.Category{}, err
+	}
+	return r.mapModelToDomain(cat), nil
+}
+
+func (r categoryRepository) FindBySlug(slug string) (domain.Category, error) {
+	var cat category
+	err := r.coll.Find(db.Cond{"slug": slug}).One(&cat)
+	if err != nil {
+		return domain.Category{}, err
+	}
+	return r.mapModelToDomain(cat), nil
+}
+
+func (r categoryRepository) FindAll() ([]domain.Category, error) {
+	var cats []category
+	err := r.coll.Find().OrderBy("name").All(&cats)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]domain.Category, len(cats))
+	for i, c := range cats {
+		result[i] = r.mapModelToDomain(c)
+	}
+	return result, nil
+}
+
+func (r categoryRepository) CountChildren(id uint64) (uint64, error) {
+	return r.coll.Find(db.Cond{"parent_id": id}).Count()
+}
+
+// Delete removes the category, its events are left without a category.
+func (r categoryRepository) Delete(id uint64) error {
+	return r.coll.Find(db.Cond{"id": id}).Delete()
+}
+
+func (r categoryRepository) mapDomainToModel(d domain.Category) category {
+	return category{
+		Id:          d.Id,
+		ParentId:    d.ParentId,
+		Name:        d.Name,
+		Slug:        d.Slug,
+		CreatedDate: d.CreatedDate,
+		UpdatedDate: d.UpdatedDate,
+	}
+}
+
+func (r categoryRepository) mapModelToDomain(m category) domain.Category {
+	return domain.Category{
+		Id:          m.Id,
+		ParentId:    m.ParentId,
+		Name:        m.Name,
+		Slug:        m.Slug,
+		CreatedDate: m.CreatedDate,
+		UpdatedDate: m.UpdatedDate,
+	}
+}
@@ -0,0 +1,179 @@
+package database
+
+import (
+	"log"
+	"time"
+
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"github.com/upper/db/v4"
+)
+
+const CommentsTableName = "comments"
+
+type comment struct {
+	Id          uint64     `db:"id,omitempty"`
+	EventId     uint64     `db:"event_id"`
+	UserId      uint64     `db:"user_id"`
+	ParentId    *uint64    `db:"parent_id"`
+	Body        string     `db:"body"`
+	CreatedDate time.Time  `db:"created_date"`
+	UpdatedDate *time.Time `db:"updated_date"`
+}
+
+// authoredComment is a comment read along with the public details of its author.
+type authoredComment struct {
+	comment          `db:",inline"`
+	AuthorFirstName  string `db:"author_first_name"`
+	AuthorSecondName string `db:"author_second_name"`
+	AuthorImage      string `db:"author_image"`
+}
+
+type CommentRepository interface {
+	Save(c domain.Comment) (domain.Comment, error)
+	Find(id uint64) (domain.Comment, error)
+	FindByEvent(eventId uint64, p domain.Pagination) ([]domain.Comment, uint64, error)
+	FindReplies(parentIds []uint64) ([]domain.Comment, error)
+	Update(c domain.Comment) (domain.Comment, error)
+	Delete(id uint64) error
+}
+
+type commentRepository struct {
+	coll db.Collection
+	sess db.Session
+}
+
+func NewCommentRepository(dbSession db.Session) CommentRepository {
+	return commentRepository{
+		coll: dbSession.Collection(CommentsTableName),
+		sess: dbSession,
+	}
+}
+
+func (r commentRepository) Save(c domain.Comment) (domain.Comment, error) {
+	m := r.mapDomainToModel(c)
+	m.CreatedDate = time.Now()
+	m.UpdatedDate = nil
+	err := r.coll.InsertReturning(&m)
+	if err != nil {
+		log.Printf("CommentRepository -> Save -> r.coll.InsertReturning: %s", err)
+		return domain.Comment{}, err
+	}
+	return r.Find(m.Id)
+}
+
+func (r commentRepository) Find(id uint64) (domain.Comment, error) {
+	var c authoredComment
+	err := r.query().Where("c.id = ?", id).One(&c)
+	if err != nil {
+		return domain.Comment{}, err
+	}
+	return r.mapModelToDomain(c), nil
+}
+
+// FindByEvent returns a page of the top-level comments of the event, the newest first.
+func (r commentRepository) FindByEvent(eventId uint64, p domain.Pagination) ([]domain.Comment, uint64, error) {
+	var comments []authoredComment
+	paginator := r.query().
+		Where("c.event_id = ? AND c.parent_id IS NULL", eventId).
+		OrderBy("-c.created_date", "-c.id").
+		Paginate(uint(p.CountPerPage))
+
+	err := paginator.Page(uint(p.Page)).All(&comments)
+	if err != nil {
+		log.Printf("CommentRepository -> FindByEvent -> paginator.All: %s", err)
+		return nil, 0, err
+	}
+
+	total, err := paginator.TotalEntries()
+	if err != nil {
+		log.Printf("CommentRepository -> FindByEvent -> paginator.TotalEntries: %s", err)
+		return nil, 0, err
+	}
+	return r.mapModelToDomainCollection(comments), total, nil
+}
+
+// FindReplies returns the replies to the comments, the oldest first.
+func (r commentRepository) FindReplies(parentIds []uint64) ([]domain.Comment, error) {
+	if len(parentIds) == 0 {
+		return nil, nil
+	}
+
+	var comments []authoredComment
+	err := r.query().
+		Where("c.parent_id IN ?", parentIds).
+		OrderBy("c.created_date", "c.id").
+		All(&comments)
+	if err != nil {
+		log.Printf("CommentRepository -> FindReplies -> r.query().All: %s", err)
+		return nil, err
+	}
+	return r.mapModelToDomainCollection(comments), nil
+}
+
+// Update changes the body of the comment and marks it as edited.
+func (r commentRepository) Update(c domain.Comment) (domain.Comment, error) {
+	err := r.coll.Find(db.Cond{"id": c.Id}).Update(map[string]interface{}{
+		"body":         c.Body,
+		"updated_date": time.Now(),
+	})
+	if err != nil {
+		log.Printf("CommentRepository -> Update -> r.coll.Update: %s", err)
+		return domain.Comment{}, err
+	}
+	return r.Find(c.Id)
+}
+
+// Delete removes the comment along with its replies.
+func (r commentRepository) Delete(id uint64) error {
+	return r.coll.Find(db.Cond{"id": id}).Delete()
+}
+
+func (r commentRepository) query() db.Selector {
+	return r.sess.SQL().
+		Select(
+			"c.*",
+			"u.first_name AS author_first_name",
+			"u.second_name AS author_second_name",
+			"u.image AS author_image",
+		).
+		From("comments AS c").
+		Join("users AS u").On("c.user_id = u.id")
+}
+
+func (r commentRepository) mapDomainToModel(d domain.Comment) comment {
+	return comment{
+		Id:          d.Id,
+		EventId:     d.EventId,
+		UserId:      d.UserId,
+		ParentId:    d.ParentId,
+		Body:        d.Body,
+		CreatedDate: d.CreatedDate,
+		UpdatedDate: d.UpdatedDate,
+	}
+}
+
+func (r commentRepository) mapModelToDomain(m authoredComment) domain.Comment {
+	return domain.Comment{
+		Id:       m.Id,
+		EventId:  m.EventId,
+		UserId:   m.UserId,
+		ParentId: m.ParentId,
+		Body:     m.Body,
+		Author: domain.User{
+			Id:         m.UserId,
+			FirstName:  m.AuthorFirstName,
+			SecondName: m.AuthorSecondName,
+			Image:      m.AuthorImage,
+		},
+		CreatedDate: m.CreatedDate,
+		UpdatedDate: m.UpdatedDate,
+	}
+}
+
+func (r commentRepository) mapModelToDomainCollection(comments []authoredComment) []domain.Comment {
+	result := make([]domain.Comment, len(comments))
+	for i, c := range comments {
+		result[i] = r.mapModelToDomain(c)
+	}
+	return result
+}
@@ -0,0 +1,83 @@
+package database
+
+import (
+	"fmt"
+	"github.com/BohdanBoriak/boilerplate-go-back/config"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"github.com/upper/db/v4"
+	"github.com/upper/db/v4/adapter/postgresql"
+	"os"
+	"testing"
+	"time"
+)
+
+// testSession connects to the database named by TEST_DB_NAME and migrates it to the latest
+// version. The other connection settings are the usual DB_* variables. The tests that need
+// a database are skipped when TEST_DB_NAME is not set, it must never point to a database
+// with data worth keeping.
+func testSession(t *testing.T) db.Session {
+	t.Helper()
+
+	name, set := os.LookupEnv("TEST_DB_NAME")
+	if !set {
+		t.Skip("TEST_DB_NAME is not set")
+	}
+
+	conf := config.GetConfiguration()
+	conf.DatabaseName = name
+	conf.MigrateToVersion = "latest"
+	conf.MigrationLocation = "migrations"
+
+	err := Migrate(conf)
+	if err != nil {
+		t.Fatalf("Migrate: %s", err)
+	}
+
+	sess, err := postgresql.Open(postgresql.ConnectionURL{
+		User:     conf.DatabaseUser,
+		Host:     conf.DatabaseHost,
+		Password: conf.DatabasePassword,
+		Database: conf.DatabaseName,
+	})
+	if err != nil {
+		t.Fatalf("postgresql.Open: %s", err)
+	}
+	t.Cleanup(func() { _ = sess.Close() })
+
+	return sess
+}
+
+func createTestUser(t *testing.T, sess db.Session) domain.User {
+	t.Helper()
+
+	u, err := NewUserRepository(sess).Save(domain.User{
+		FirstName:  "Test",
+		SecondName: "User",
+		Email:      fmt.Sprintf("test-%d@eventio.local", time.Now().UnixNano()),
+		Password:   "password",
+		Role:       domain.CustomerRole,
+	})
+	if err != nil {
+		t.Fatalf("UserRepository.Save: %s", err)
+	}
+	return u
+}
+
+func createTestEvent(t *testing.T, sess db.Session, userId uint64, capacity *uint64) domain.Event {
+	t.Helper()
+
+	e, err := NewEventRepository(sess).Save(domain.Event{
+		UserId:      userId,
+		Title:       "Test event",
+		Description: "Test event",
+		Status:      domain.NewEventStatus,
+		Date:        time.Now().Add(24 * time.Hour),
+		Location:    "Test location",
+		City:        "Kyiv",
+		Capacity:    capacity,
+	})
+	if err != nil {
+		t.Fatalf("EventRepository.Save: %s", err)
+	}
+	return e
+}
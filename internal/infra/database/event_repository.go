@@ -1,6 +1,7 @@
 package database
 
 import (
+	"fmt"
 	"github.com/upper/db/v4"
 	"log"
 	"strings"
@@ -9,33 +10,68 @@ import (
 	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
 )
 
-const EventTableName = "events"
+const (
+	EventTableName     = "events"
+	TagsTableName      = "tags"
+	EventTagsTableName = "event_tags"
+
+	maxTagFacets = 50
+)
+
+// searchQuery matches both stemmed english and verbatim (e.g. ukrainian) words, see the search_vector column
+const searchQuery = `(websearch_to_tsquery('english', ?) || websearch_to_tsquery('simple', ?))`
+
+const snippetOptions = "StartSel=<mark>, StopSel=</mark>, MaxWords=30, MinWords=10, MaxFragments=2"
+
+// escapedDescription is the description with its HTML escaped, the snippets are made of it
+// so that the only markup they contain is the <mark> highlighting
+const escapedDescription = `replace(replace(replace(description, '&', '&amp;'), '<', '&lt;'), '>', '&gt;')`
+
+// suggestSimilarityThreshold is lower than the pg_trgm default of 0.3, so short typos like "Kyv" still match "Kyiv"
+const suggestSimilarityThreshold = 0.2
 
 type event struct {
-	Id          uint64             `db:"id,omitempty"`
-	UserId      uint64             `db:"user_id,omitempty"`
-	Title       string             `db:"title"`
-	Description string             `db:"description"`
-	Status      domain.EventStatus `db:"status"`
-	Image       string             `db:"image"`
-	City        string             `db:"city"`
-	Location    string             `db:"location"`
-	Lat         float64            `db:"lat"`
-	Lon         float64            `db:"lon"`
-	Date        time.Time          `db:"date"`
-	CreatedDate time.Time          `db:"created_date,omitempty"`
-	UpdatedDate time.Time          `db:"updated_date,omitempty"`
-	DeletedDate *time.Time         `db:"deleted_date,omitempty"`
+	Id               uint64             `db:"id,omitempty"`
+	UserId           uint64             `db:"user_id,omitempty"`
+	Title            string             `db:"title"`
+	Description      string             `db:"description"`
+	Status           domain.EventStatus `db:"status"`
+	Image            string             `db:"image"`
+	City             string             `db:"city"`
+	Location         string             `db:"location"`
+	VenueId          *uint64            `db:"venue_id"`
+	Lat              float64            `db:"lat"`
+	Lon              float64            `db:"lon"`
+	LocationMismatch bool               `db:"location_mismatch"`
+	Capacity         *uint64            `db:"capacity"`
+	AttendeesPublic  bool               `db:"attendees_public"`
+	CategoryId       *uint64            `db:"category_id"`
+	Date             time.Time          `db:"date"`
+	EndDate          *time.Time         `db:"end_date"`
+	ExternalUid      *string            `db:"external_uid"`
+	CreatedDate      time.Time          `db:"created_date,omitempty"`
+	UpdatedDate      time.Time          `db:"updated_date,omitempty"`
+	DeletedDate      *time.Time         `db:"deleted_date,omitempty"`
+	DistanceKm       *float64           `db:"distance_km,omitempty"`
+	SearchRank       *float64           `db:"search_rank,omitempty"`
+	Snippet          *string            `db:"snippet,omitempty"`
 }
 type EventRepository interface {
 	Save(event domain.Event) (domain.Event, error)
-	Update(event domain.Event) (domain.Event, error)
+	Update(event domain.Event) (domain.Event, domain.Event, error)
 	Find(id uint64) (interface{}, error)
 	Delete(id uint64) error
-	FindAll() ([]domain.Event, error)
 	FindEventsByDate(date time.Time) ([]domain.Event, error)
-	FindEventsGroupByDate() (map[string][]domain.Event, error)
-	FindList(filters UrlFilters) ([]domain.Event, error)
+	FindList(filters UrlFilters, p domain.Pagination) ([]domain.Event, uint64, error)
+	FindCalendar(filters UrlFilters, loc *time.Location, perDay uint) ([]domain.CalendarDay, error)
+	FindFacets(filters UrlFilters) (domain.EventFacets, error)
+	FindClusters(filters UrlFilters, cellDeg float64) ([]domain.EventCluster, error)
+	Suggest(q string, limit uint) (domain.EventSuggestions, error)
+	FindByExternalUid(userId uint64, uid string) (domain.Event, error)
+	FindUnmatched(limit uint) ([]domain.Event, error)
+	MarkMatched(ids []uint64) error
+	MarkMatchFailed(id uint64, maxAttempts uint) error
+	FindUpcomingByVenue(venueId uint64) ([]domain.Event, error)
 }
 
 type eventRepository struct {
@@ -44,10 +80,37 @@ type eventRepository struct {
 }
 
 type UrlFilters struct {
-	Search   string
-	Date     *time.Time
-	Location string
-	City     string
+	Search       string
+	Date         *time.Time
+	From         *time.Time
+	To           *time.Time
+	Location     string
+	City         string
+	Status       domain.EventStatus
+	CategoryId   uint64
+	VenueId      uint64
+	Tags         []string
+	OwnerId      uint64
+	SubscriberId uint64
+	Point        *domain.GeoPoint
+	RadiusKm     float64
+	Bbox         *domain.BoundingBox
+	Sort         string
+}
+
+// eventSortColumns maps the public sort keys to columns, "-" means descending
+var eventSortColumns = map[string]string{
+	"date":     "date",
+	"-date":    "-date",
+	"created":  "created_date",
+	"-created": "-created_date",
+	"title":    "title",
+	"-title":   "-title",
+}
+
+func IsValidEventSort(sort string) bool {
+	_, ok := eventSortColumns[sort]
+	return ok
 }
 
 func NewEventRepository(dbSession db.Session) eventRepository {
@@ -60,23 +123,55 @@ func NewEventRepository(dbSession db.Session) eventRepository {
 func (r eventRepository) Save(event domain.Event) (domain.Event, error) {
 	evn := r.mapDomainToModel(event)
 	evn.CreatedDate, evn.UpdatedDate = time.Now(), time.Now()
-	err := r.coll.InsertReturning(&evn)
+	err := r.sess.Tx(func(tx db.Session) error {
+		err := tx.Collection(EventTableName).InsertReturning(&evn)
+		if err != nil {
+			return err
+		}
+		return r.setTags(tx, evn.Id, event.Tags)
+	})
 	if err != nil {
-		log.Printf("EventRepository -> Save -> r.coll.InsertReturning(&evn) %s", err)
+		log.Printf("EventRepository -> Save -> r.sess.Tx: %s", err)
 		return domain.Event{}, err
 	}
-	return r.mapModelToDomain(evn), nil
+
+	result := r.mapModelToDomain(evn)
+	result.Tags = event.Tags
+	return result, nil
 }
 
-func (r eventRepository) Update(event domain.Event) (domain.Event, error) {
+// Update saves the event and records the changes in a new revision, see EventRevisionRepository.
+// It returns the event as it was right before the update too. Deleted events can't be updated,
+// db.ErrNoMoreRows is returned for them.
+func (r eventRepository) Update(event domain.Event) (domain.Event, domain.Event, error) {
 	e := r.mapDomainToModel(event)
 	e.UpdatedDate = time.Now()
-	err := r.coll.Find(db.Cond{"id": e.Id, "deleted_date": nil}).Update(&e)
+	var previous domain.Event
+	err := r.sess.Tx(func(tx db.Session) error {
+		var err error
+		previous, err = r.lockForUpdate(tx, e.Id)
+		if err != nil {
+			return err
+		}
+
+		err = tx.Collection(EventTableName).Find(db.Cond{"id": e.Id}).Update(&e)
+		if err != nil {
+			return err
+		}
+		err = r.setTags(tx, e.Id, event.Tags)
+		if err != nil {
+			return err
+		}
+		return saveRevision(tx, event.Id, event.UpdatedBy, domain.DiffEvents(previous, event))
+	})
 	if err != nil {
-		log.Printf("EventRepository -> Update -> r.coll.Find(db.Cond{\"id\": e.Id, \"deleted_date\": nil}).Update(&e): %s", err)
-		return domain.Event{}, err
+		log.Printf("EventRepository -> Update -> r.sess.Tx: %s", err)
+		return domain.Event{}, domain.Event{}, err
 	}
-	return r.mapModelToDomain(e), nil
+
+	result := r.mapModelToDomain(e)
+	result.Tags = event.Tags
+	return result, previous, nil
 }
 
 func (r eventRepository) Find(id uint64) (interface{}, error) {
@@ -87,16 +182,12 @@ func (r eventRepository) Find(id uint64) (interface{}, error) {
 		return nil, err
 	}
 
-	return r.mapModelToDomain(evn), nil
-}
-func (r eventRepository) FindAll() ([]domain.Event, error) {
-	var events []event
-	err := r.coll.Find(db.Cond{"deleted_date": nil}).All(&events)
+	events, err := r.withDetails([]domain.Event{r.mapModelToDomain(evn)})
 	if err != nil {
-		log.Printf("EventRepository -> FindAll -> r.coll.Find(db.Cond{\"deleted_date\": nil}).All(&events) %s", err)
-		return []domain.Event{}, err
+		log.Printf("EventRepository -> Find -> r.withDetails: %s", err)
+		return nil, err
 	}
-	return r.mapModelToDomainCollection(events), nil
+	return events[0], nil
 }
 func (r eventRepository) FindEventsByDate(date time.Time) ([]domain.Event, error) {
 	var events []event
@@ -114,57 +205,632 @@ func (r eventRepository) FindEventsByDate(date time.Time) ([]domain.Event, error
 		return nil, err
 	}
 
-	return r.mapModelToDomainCollection(events), nil
+	return r.withDetails(r.mapModelToDomainCollection(events))
 }
-func (r eventRepository) FindEventsGroupByDate() (map[string][]domain.Event, error) {
-	groupedEvents := make(map[string][]domain.Event)
+
+// FindList returns a page of the events matching the filters and the total number of matches.
+// Without an explicit sort, the nearest and the most relevant events go first.
+func (r eventRepository) FindList(filters UrlFilters, p domain.Pagination) ([]domain.Event, uint64, error) {
+	query := r.coll.Find(r.filterConditions(filters))
+	total, err := query.Count()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	columns := []interface{}{"*"}
+	var orderBy []interface{}
+
+	point := filters.Point
+	if point == nil && filters.Bbox != nil {
+		center := filters.Bbox.Center()
+		point = &center
+	}
+	if point != nil {
+		columns = append(columns, db.Raw(`earth_distance(ll_to_earth(?, ?), ll_to_earth(lat, lon)) / 1000 AS distance_km`, point.Lat, point.Lon))
+		orderBy = append(orderBy, "distance_km")
+	}
+
+	if filters.Search != "" {
+		columns = append(columns,
+			db.Raw(`ts_rank_cd(search_vector, `+searchQuery+`) AS search_rank`, filters.Search, filters.Search),
+			db.Raw(`ts_headline('english', `+escapedDescription+`, `+searchQuery+`, ?) AS snippet`, filters.Search, filters.Search, snippetOptions),
+		)
+		orderBy = append(orderBy, "-search_rank")
+	}
+
+	if column, ok := eventSortColumns[filters.Sort]; ok {
+		orderBy = []interface{}{column}
+	}
+	// id keeps the order of equal rows stable between pages
+	orderBy = append(orderBy, "-date", "-id")
 
 	var events []event
-	err := r.coll.Find(db.Cond{"deleted_date": nil}).All(&events)
+	err = query.
+		Select(columns...).
+		OrderBy(orderBy...).
+		Paginate(uint(p.CountPerPage)).
+		Page(uint(p.Page)).
+		All(&events)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	result, err := r.withDetails(r.mapModelToDomainCollection(events))
+	if err != nil {
+		return nil, 0, err
+	}
+	return result, total, nil
+}
+
+// FindCalendar counts the events matching the filters per day of the loc time zone. With perDay
+// above zero, the first perDay events of each day are loaded too.
+func (r eventRepository) FindCalendar(filters UrlFilters, loc *time.Location, perDay uint) ([]domain.CalendarDay, error) {
+	tz := loc.String()
+	conds := r.filterConditions(filters)
+
+	var counts []struct {
+		Day   string `db:"day"`
+		Count uint64 `db:"count"`
+	}
+	err := r.sess.SQL().
+		Select(
+			db.Raw("to_char(date AT TIME ZONE ?, 'YYYY-MM-DD') AS day", tz),
+			db.Raw("COUNT(*) AS count"),
+		).
+		From(EventTableName).
+		Where(conds).
+		GroupBy("day").
+		OrderBy("day").
+		All(&counts)
 	if err != nil {
-		log.Printf("EventRepository -> FindEventsGroupByDate -> r.coll.Find(db.Cond{\"deleted_date\": nil}).All(&events) %s", err)
+		log.Printf("EventRepository -> FindCalendar -> r.sess.SQL(): %s", err)
 		return nil, err
 	}
 
-	for _, e := range events {
-		dateKey := e.Date.Format("2006-01-02")
-		groupedEvents[dateKey] = append(groupedEvents[dateKey], r.mapModelToDomain(e))
+	days := make([]domain.CalendarDay, len(counts))
+	dayIndex := make(map[string]int, len(counts))
+	for i, c := range counts {
+		days[i] = domain.CalendarDay{Date: c.Day, Count: c.Count}
+		dayIndex[c.Day] = i
+	}
+	if perDay == 0 || len(days) == 0 {
+		return days, nil
+	}
+
+	ranked := r.sess.SQL().
+		Select(
+			"*",
+			db.Raw("to_char(date AT TIME ZONE ?, 'YYYY-MM-DD') AS day", tz),
+			db.Raw("ROW_NUMBER() OVER (PARTITION BY (date AT TIME ZONE ?)::date ORDER BY date, id) AS day_rank", tz),
+		).
+		From(EventTableName).
+		Where(conds)
+
+	var events []struct {
+		event `db:",inline"`
+		Day   string `db:"day"`
+	}
+	err = r.sess.SQL().
+		Select("*").
+		From(db.Raw("? AS ranked_events", ranked)).
+		Where("day_rank <= ?", perDay).
+		OrderBy("date", "id").
+		All(&events)
+	if err != nil {
+		log.Printf("EventRepository -> FindCalendar -> ranked events: %s", err)
+		return nil, err
+	}
+
+	models := make([]event, len(events))
+	for i, e := range events {
+		models[i] = e.event
+	}
+	withDetails, err := r.withDetails(r.mapModelToDomainCollection(models))
+	if err != nil {
+		log.Printf("EventRepository -> FindCalendar -> r.withDetails: %s", err)
+		return nil, err
 	}
 
-	return groupedEvents, nil
+	for i, e := range events {
+		day, ok := dayIndex[e.Day]
+		if !ok {
+			continue
+		}
+		days[day].Events = append(days[day].Events, withDetails[i])
+	}
+	return days, nil
+}
+
+// FindFacets counts the events matching the filters per category, subcategories included,
+// and per tag.
+func (r eventRepository) FindFacets(filters UrlFilters) (domain.EventFacets, error) {
+	conds := r.filterConditions(filters)
+
+	matching := r.sess.SQL().
+		Select("category_id").
+		From(EventTableName).
+		Where(conds, db.Cond{"category_id IS NOT": nil})
+
+	// every event counts for its category and all the categories above it, the same way
+	// filtering by a category includes its subcategories
+	perCategory := r.sess.SQL().
+		Select(db.Raw("l.ancestor_id AS category_id"), db.Raw("COUNT(*) AS count")).
+		From(db.Raw(
+			`? AS m JOIN (WITH RECURSIVE lineage AS (
+				SELECT id AS descendant_id, id AS ancestor_id FROM `+CategoriesTableName+`
+				UNION SELECT l.descendant_id, c.parent_id FROM lineage l
+				JOIN `+CategoriesTableName+` c ON c.id = l.ancestor_id WHERE c.parent_id IS NOT NULL
+			) SELECT * FROM lineage) AS l ON l.descendant_id = m.category_id`,
+			matching,
+		)).
+		GroupBy("l.ancestor_id")
+
+	var categories []struct {
+		CategoryId uint64 `db:"category_id"`
+		Name       string `db:"name"`
+		Count      uint64 `db:"count"`
+	}
+	err := r.sess.SQL().
+		Select("f.category_id", "c.name", "f.count").
+		From(db.Raw("? AS f", perCategory)).
+		Join(CategoriesTableName+" AS c").On("c.id = f.category_id").
+		OrderBy("-f.count", "c.name").
+		All(&categories)
+	if err != nil {
+		log.Printf("EventRepository -> FindFacets -> categories: %s", err)
+		return domain.EventFacets{}, err
+	}
+
+	matching = r.sess.SQL().Select("id").From(EventTableName).Where(conds)
+
+	var tags []struct {
+		Tag   string `db:"tag"`
+		Count uint64 `db:"count"`
+	}
+	err = r.sess.SQL().
+		Select(db.Raw("t.name AS tag"), db.Raw("COUNT(*) AS count")).
+		From(EventTagsTableName+" AS et").
+		Join(TagsTableName+" AS t").On("t.id = et.tag_id").
+		Where("et.event_id IN ?", matching).
+		GroupBy("t.name").
+		OrderBy(db.Raw("COUNT(*) DESC"), "t.name").
+		Limit(maxTagFacets).
+		All(&tags)
+	if err != nil {
+		log.Printf("EventRepository -> FindFacets -> tags: %s", err)
+		return domain.EventFacets{}, err
+	}
+
+	facets := domain.EventFacets{
+		Categories: make([]domain.CategoryFacet, len(categories)),
+		Tags:       make([]domain.TagFacet, len(tags)),
+	}
+	for i, c := range categories {
+		facets.Categories[i] = domain.CategoryFacet{CategoryId: c.CategoryId, Name: c.Name, Count: c.Count}
+	}
+	for i, t := range tags {
+		facets.Tags[i] = domain.TagFacet{Tag: t.Tag, Count: t.Count}
+	}
+	return facets, nil
+}
+
+// setTags replaces the tags of the event, the tags are created on first use.
+func (r eventRepository) setTags(tx db.Session, eventId uint64, tags []string) error {
+	_, err := tx.SQL().DeleteFrom(EventTagsTableName).Where("event_id = ?", eventId).Exec()
+	if err != nil {
+		return err
+	}
+	if len(tags) == 0 {
+		return nil
+	}
+
+	for _, tag := range tags {
+		_, err = tx.SQL().Exec(`INSERT INTO `+TagsTableName+` (name) VALUES (?) ON CONFLICT (name) DO NOTHING`, tag)
+		if err != nil {
+			return err
+		}
+	}
+	_, err = tx.SQL().Exec(
+		`INSERT INTO `+EventTagsTableName+` (event_id, tag_id) SELECT ?, id FROM `+TagsTableName+` WHERE name IN ?`,
+		eventId, tags,
+	)
+	return err
 }
-func (r eventRepository) FindList(filters UrlFilters) ([]domain.Event, error) {
-	query := r.coll.Find(db.Cond{"deleted_date": nil})
+
+// lockForUpdate returns the event as it is before the update, locked, so the revisions
+// of concurrent updates are recorded one after another.
+func (r eventRepository) lockForUpdate(tx db.Session, id uint64) (domain.Event, error) {
+	var evn event
+	err := tx.SQL().
+		SelectFrom(EventTableName).
+		Where("id = ? AND deleted_date IS NULL", id).
+		Amend(func(q string) string { return q + " FOR UPDATE" }).
+		One(&evn)
+	if err != nil {
+		return domain.Event{}, err
+	}
+
+	result := r.mapModelToDomain(evn)
+	result.Tags, err = r.findTags(tx, id)
+	if err != nil {
+		return domain.Event{}, err
+	}
+	return result, nil
+}
+
+func (r eventRepository) findTags(tx db.Session, eventId uint64) ([]string, error) {
+	var rows []struct {
+		Name string `db:"name"`
+	}
+	err := tx.SQL().
+		Select("t.name").
+		From(EventTagsTableName+" AS et").
+		Join(TagsTableName+" AS t").On("t.id = et.tag_id").
+		Where("et.event_id = ?", eventId).
+		OrderBy("t.name").
+		All(&rows)
+	if err != nil {
+		return nil, err
+	}
+
+	tags := make([]string, len(rows))
+	for i, row := range rows {
+		tags[i] = row.Name
+	}
+	return tags, nil
+}
+
+// withDetails loads the tags and the ratings of the events.
+func (r eventRepository) withDetails(events []domain.Event) ([]domain.Event, error) {
+	events, err := r.withTags(events)
+	if err != nil {
+		return nil, err
+	}
+	return r.withRatings(events)
+}
+
+// withTags loads the tags of the events with a single query.
+func (r eventRepository) withTags(events []domain.Event) ([]domain.Event, error) {
+	if len(events) == 0 {
+		return events, nil
+	}
+
+	ids := make([]uint64, len(events))
+	for i, e := range events {
+		ids[i] = e.Id
+	}
+
+	var rows []struct {
+		EventId uint64 `db:"event_id"`
+		Name    string `db:"name"`
+	}
+	err := r.sess.SQL().
+		Select("et.event_id", "t.name").
+		From(EventTagsTableName+" AS et").
+		Join(TagsTableName+" AS t").On("t.id = et.tag_id").
+		Where("et.event_id IN ?", ids).
+		OrderBy("t.name").
+		All(&rows)
+	if err != nil {
+		return nil, err
+	}
+
+	tags := make(map[uint64][]string)
+	for _, row := range rows {
+		tags[row.EventId] = append(tags[row.EventId], row.Name)
+	}
+	for i := range events {
+		events[i].Tags = tags[events[i].Id]
+	}
+	return events, nil
+}
+
+// withRatings loads the average rating and the number of reviews of the events with a single query.
+func (r eventRepository) withRatings(events []domain.Event) ([]domain.Event, error) {
+	if len(events) == 0 {
+		return events, nil
+	}
+
+	ids := make([]uint64, len(events))
+	for i, e := range events {
+		ids[i] = e.Id
+	}
+
+	var rows []struct {
+		EventId uint64  `db:"event_id"`
+		Average float64 `db:"average"`
+		Count   uint64  `db:"count"`
+	}
+	err := r.sess.SQL().
+		Select("event_id", db.Raw("AVG(rating)::float8 AS average"), db.Raw("COUNT(*) AS count")).
+		From(ReviewsTableName).
+		Where("event_id IN ?", ids).
+		GroupBy("event_id").
+		All(&rows)
+	if err != nil {
+		return nil, err
+	}
+
+	ratings := make(map[uint64]int, len(rows))
+	for i, row := range rows {
+		ratings[row.EventId] = i
+	}
+	for i := range events {
+		if j, ok := ratings[events[i].Id]; ok {
+			events[i].RatingAverage = rows[j].Average
+			events[i].RatingCount = rows[j].Count
+		}
+	}
+	return events, nil
+}
+
+// FindClusters groups the events matching the filters into square grid cells of cellDeg degrees.
+func (r eventRepository) FindClusters(filters UrlFilters, cellDeg float64) ([]domain.EventCluster, error) {
+	// cellDeg is computed from the zoom level, never taken from user input
+	cellX := fmt.Sprintf("FLOOR(lon / %f)", cellDeg)
+	cellY := fmt.Sprintf("FLOOR(lat / %f)", cellDeg)
+
+	var clusters []struct {
+		Count   uint64  `db:"count"`
+		Lat     float64 `db:"lat"`
+		Lon     float64 `db:"lon"`
+		MinLat  float64 `db:"min_lat"`
+		MinLon  float64 `db:"min_lon"`
+		MaxLat  float64 `db:"max_lat"`
+		MaxLon  float64 `db:"max_lon"`
+		EventId uint64  `db:"event_id"`
+	}
+	err := r.sess.SQL().
+		Select(
+			db.Raw("COUNT(*) AS count"),
+			db.Raw("AVG(lat) AS lat"),
+			db.Raw("AVG(lon) AS lon"),
+			db.Raw("MIN(lat) AS min_lat"),
+			db.Raw("MIN(lon) AS min_lon"),
+			db.Raw("MAX(lat) AS max_lat"),
+			db.Raw("MAX(lon) AS max_lon"),
+			db.Raw("MIN(id) AS event_id"),
+		).
+		From(EventTableName).
+		Where(r.filterConditions(filters)).
+		GroupBy(db.Raw(cellX), db.Raw(cellY)).
+		All(&clusters)
+	if err != nil {
+		log.Printf("EventRepository -> FindClusters -> r.sess.SQL(): %s", err)
+		return nil, err
+	}
+
+	result := make([]domain.EventCluster, len(clusters))
+	for i, c := range clusters {
+		result[i] = domain.EventCluster{
+			Center: domain.GeoPoint{Lat: c.Lat, Lon: c.Lon},
+			Bounds: domain.BoundingBox{MinLat: c.MinLat, MinLon: c.MinLon, MaxLat: c.MaxLat, MaxLon: c.MaxLon},
+			Count:  c.Count,
+		}
+		if c.Count == 1 {
+			result[i].EventId = c.EventId
+		}
+	}
+	return result, nil
+}
+
+// Suggest returns the distinct cities, locations and titles of listed events most similar to q.
+// Cancelled and deleted events are not listed.
+func (r eventRepository) Suggest(q string, limit uint) (domain.EventSuggestions, error) {
+	var suggestions domain.EventSuggestions
+	err := r.sess.Tx(func(tx db.Session) error {
+		_, err := tx.SQL().Exec(fmt.Sprintf("SET LOCAL pg_trgm.similarity_threshold = %f", suggestSimilarityThreshold))
+		if err != nil {
+			return err
+		}
+
+		suggestions.Cities, err = r.suggestColumn(tx, "city", q, limit)
+		if err != nil {
+			return err
+		}
+		suggestions.Locations, err = r.suggestColumn(tx, "location", q, limit)
+		if err != nil {
+			return err
+		}
+		suggestions.Titles, err = r.suggestColumn(tx, "title", q, limit)
+		return err
+	})
+	if err != nil {
+		log.Printf("EventRepository -> Suggest -> r.sess.Tx: %s", err)
+		return domain.EventSuggestions{}, err
+	}
+
+	return suggestions, nil
+}
+
+// suggestColumn must only be called with a column name, never with user input.
+func (r eventRepository) suggestColumn(tx db.Session, column, q string, limit uint) ([]domain.Suggestion, error) {
+	var rows []struct {
+		Value string `db:"value"`
+		Count uint64 `db:"count"`
+	}
+	err := tx.SQL().
+		Select(
+			db.Raw(fmt.Sprintf("MIN(%s) AS value", column)),
+			db.Raw("COUNT(*) AS count"),
+		).
+		From(EventTableName).
+		Where(db.And(
+			db.Cond{"deleted_date": nil, "status <>": domain.CancelledEventStatus},
+			db.Raw(fmt.Sprintf("%s <> ''", column)),
+			db.Raw(fmt.Sprintf("(%s %% ? OR %s ILIKE ?)", column, column), q, escapeLike(q)+"%"),
+		)).
+		GroupBy(db.Raw(fmt.Sprintf("LOWER(%s)", column))).
+		OrderBy(db.Raw(fmt.Sprintf("MAX(similarity(%s, ?)) DESC", column), q), db.Raw("COUNT(*) DESC")).
+		Limit(int(limit)).
+		All(&rows)
+	if err != nil {
+		return nil, err
+	}
+
+	suggestions := make([]domain.Suggestion, len(rows))
+	for i, row := range rows {
+		suggestions[i] = domain.Suggestion{Value: row.Value, Count: row.Count}
+	}
+	return suggestions, nil
+}
+
+func (r eventRepository) filterConditions(filters UrlFilters) *db.AndExpr {
+	conds := db.And(db.Cond{"deleted_date": nil})
 
 	if filters.City != "" {
 		city := "%" + strings.ToLower(filters.City) + "%"
-		query = query.And(db.Raw(`LOWER(city) LIKE ?`, city))
+		conds = conds.And(db.Raw(`LOWER(city) LIKE ?`, city))
 	}
 
 	if filters.Search != "" {
-		search := "%" + strings.ToLower(filters.Search) + "%"
-		query = query.And(db.Raw(`(LOWER(title) LIKE ? OR LOWER(description) LIKE ?)`, search, search))
+		conds = conds.And(db.Raw(`search_vector @@ `+searchQuery, filters.Search, filters.Search))
 	}
 
 	if filters.Date != nil {
 		startOfDay := filters.Date.Truncate(24 * time.Hour)
 		endOfDay := startOfDay.Add(24*time.Hour - time.Nanosecond)
-		query = query.And(db.Cond{"date >=": startOfDay, "date <=": endOfDay})
+		conds = conds.And(db.Cond{"date >=": startOfDay, "date <=": endOfDay})
+	}
+
+	if filters.From != nil {
+		conds = conds.And(db.Cond{"date >=": *filters.From})
+	}
+
+	if filters.To != nil {
+		conds = conds.And(db.Cond{"date <=": *filters.To})
+	}
+
+	if filters.Status != "" {
+		conds = conds.And(db.Cond{"status": filters.Status})
+	}
+
+	if filters.CategoryId != 0 {
+		// subcategories are included
+		conds = conds.And(db.Raw(
+			`category_id IN (WITH RECURSIVE subtree AS (
+				SELECT id FROM `+CategoriesTableName+` WHERE id = ?
+				UNION SELECT c.id FROM `+CategoriesTableName+` c JOIN subtree s ON c.parent_id = s.id
+			) SELECT id FROM subtree)`,
+			filters.CategoryId,
+		))
+	}
+
+	if filters.VenueId != 0 {
+		conds = conds.And(db.Cond{"venue_id": filters.VenueId})
+	}
+
+	if len(filters.Tags) > 0 {
+		// the events must have all of the tags
+		conds = conds.And(db.Raw(
+			`id IN (SELECT et.event_id FROM `+EventTagsTableName+` et JOIN `+TagsTableName+` t ON t.id = et.tag_id
+				WHERE t.name IN ? GROUP BY et.event_id HAVING COUNT(*) = ?)`,
+			filters.Tags, len(filters.Tags),
+		))
+	}
+
+	if filters.OwnerId != 0 {
+		conds = conds.And(db.Cond{"user_id": filters.OwnerId})
+	}
+
+	if filters.SubscriberId != 0 {
+		conds = conds.And(db.Raw(
+			`id IN (SELECT event_id FROM `+SubscriptionsTableName+` WHERE user_id = ? AND rsvp <> ?)`,
+			filters.SubscriberId, domain.DeclinedRsvpStatus,
+		))
 	}
 
 	if filters.Location != "" {
 		location := "%" + strings.ToLower(filters.Location) + "%"
-		query = query.And(db.Raw(`LOWER(location) LIKE ?`, location))
+		conds = conds.And(db.Raw(`LOWER(location) LIKE ?`, location))
+	}
+
+	if filters.Bbox != nil {
+		conds = conds.And(db.Cond{
+			"lat >=": filters.Bbox.MinLat,
+			"lat <=": filters.Bbox.MaxLat,
+			"lon >=": filters.Bbox.MinLon,
+			"lon <=": filters.Bbox.MaxLon,
+		})
+	}
+
+	if filters.Point != nil {
+		// earth_box is a cheap indexed prefilter, it may include points slightly outside of the radius
+		point, meters := filters.Point, filters.RadiusKm*1000
+		conds = conds.And(
+			db.Raw(`earth_box(ll_to_earth(?, ?), ?) @> ll_to_earth(lat, lon)`, point.Lat, point.Lon, meters),
+			db.Raw(`earth_distance(ll_to_earth(?, ?), ll_to_earth(lat, lon)) <= ?`, point.Lat, point.Lon, meters),
+		)
 	}
 
+	return conds
+}
+
+func (r eventRepository) FindByExternalUid(userId uint64, uid string) (domain.Event, error) {
+	var evn event
+	err := r.coll.Find(db.Cond{"user_id": userId, "external_uid": uid}).One(&evn)
+	if err != nil {
+		return domain.Event{}, err
+	}
+
+	events, err := r.withDetails([]domain.Event{r.mapModelToDomain(evn)})
+	if err != nil {
+		return domain.Event{}, err
+	}
+	return events[0], nil
+}
+
+// FindUnmatched returns the oldest events not yet run against the saved searches,
+// deleted ones included.
+func (r eventRepository) FindUnmatched(limit uint) ([]domain.Event, error) {
+	var events []event
+	err := r.coll.Find(db.Cond{"matched_date": nil}).OrderBy("id").Limit(int(limit)).All(&events)
+	if err != nil {
+		log.Printf("EventRepository -> FindUnmatched -> r.coll.Find: %s", err)
+		return nil, err
+	}
+	return r.withDetails(r.mapModelToDomainCollection(events))
+}
+
+// FindUpcomingByVenue returns the events at the venue that have not started yet and
+// were not cancelled.
+func (r eventRepository) FindUpcomingByVenue(venueId uint64) ([]domain.Event, error) {
 	var events []event
-	err := query.OrderBy("-date").All(&events)
+	err := r.coll.
+		Find(db.Cond{"venue_id": venueId, "status": domain.NewEventStatus, "date >=": time.Now(), "deleted_date": nil}).
+		OrderBy("date", "id").
+		All(&events)
 	if err != nil {
+		log.Printf("EventRepository -> FindUpcomingByVenue -> r.coll.Find: %s", err)
 		return nil, err
 	}
+	return r.withDetails(r.mapModelToDomainCollection(events))
+}
 
-	return r.mapModelToDomainCollection(events), nil
+func (r eventRepository) MarkMatched(ids []uint64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	_, err := r.sess.SQL().
+		Update(EventTableName).
+		Set("matched_date", time.Now()).
+		Where("id IN ?", ids).
+		Exec()
+	return err
+}
 
+// MarkMatchFailed counts the failed attempt to match the event. After maxAttempts the
+// event is marked as matched, so it doesn't hold up the events after it.
+func (r eventRepository) MarkMatchFailed(id uint64, maxAttempts uint) error {
+	_, err := r.sess.SQL().
+		Update(EventTableName).
+		Set(
+			db.Raw("match_attempts = match_attempts + 1"),
+			db.Raw("matched_date = CASE WHEN match_attempts + 1 >= ? THEN now() END", maxAttempts),
+		).
+		Where("id = ? AND matched_date IS NULL", id).
+		Exec()
+	return err
 }
 
 func (r eventRepository) Delete(id uint64) error {
@@ -172,40 +838,71 @@ func (r eventRepository) Delete(id uint64) error {
 }
 
 func (r eventRepository) mapDomainToModel(d domain.Event) event {
+	var externalUid *string
+	if d.ExternalUid != "" {
+		externalUid = &d.ExternalUid
+	}
+
 	return event{
-		Id:          d.Id,
-		UserId:      d.UserId,
-		Title:       d.Title,
-		Description: d.Description,
-		Status:      d.Status,
-		Image:       d.Image,
-		City:        d.City,
-		Location:    d.Location,
-		Lat:         d.Lat,
-		Lon:         d.Lon,
-		Date:        d.Date,
-		CreatedDate: d.CreatedDate,
-		UpdatedDate: d.UpdatedDate,
-		DeletedDate: d.DeletedDate,
+		Id:               d.Id,
+		UserId:           d.UserId,
+		Title:            d.Title,
+		Description:      d.Description,
+		Status:           d.Status,
+		Image:            d.Image,
+		City:             d.City,
+		Location:         d.Location,
+		VenueId:          d.VenueId,
+		Lat:              d.Lat,
+		Lon:              d.Lon,
+		LocationMismatch: d.LocationMismatch,
+		Capacity:         d.Capacity,
+		AttendeesPublic:  d.AttendeesPublic,
+		CategoryId:       d.CategoryId,
+		Date:             d.Date,
+		EndDate:          d.EndDate,
+		ExternalUid:      externalUid,
+		CreatedDate:      d.CreatedDate,
+		UpdatedDate:      d.UpdatedDate,
+		DeletedDate:      d.DeletedDate,
 	}
 }
 
 func (r eventRepository) mapModelToDomain(m event) domain.Event {
+	var externalUid string
+	if m.ExternalUid != nil {
+		externalUid = *m.ExternalUid
+	}
+	var snippet string
+	if m.Snippet != nil {
+		snippet = *m.Snippet
+	}
+
 	return domain.Event{
-		Id:          m.Id,
-		UserId:      m.UserId,
-		Title:       m.Title,
-		Description: m.Description,
-		Status:      m.Status,
-		Image:       m.Image,
-		City:        m.City,
-		Location:    m.Location,
-		Lat:         m.Lat,
-		Lon:         m.Lon,
-		Date:        m.Date,
-		CreatedDate: m.CreatedDate,
-		UpdatedDate: m.UpdatedDate,
-		DeletedDate: m.DeletedDate,
+		Id:               m.Id,
+		UserId:           m.UserId,
+		Title:            m.Title,
+		Description:      m.Description,
+		Status:           m.Status,
+		Image:            m.Image,
+		City:             m.City,
+		Location:         m.Location,
+		VenueId:          m.VenueId,
+		Lat:              m.Lat,
+		Lon:              m.Lon,
+		LocationMismatch: m.LocationMismatch,
+		Capacity:         m.Capacity,
+		AttendeesPublic:  m.AttendeesPublic,
+		CategoryId:       m.CategoryId,
+		Date:             m.Date,
+		EndDate:          m.EndDate,
+		ExternalUid:      externalUid,
+		CreatedDate:      m.CreatedDate,
+		UpdatedDate:      m.UpdatedDate,
+		DeletedDate:      m.DeletedDate,
+		DistanceKm:       m.DistanceKm,
+		SearchRank:       m.SearchRank,
+		Snippet:          snippet,
 	}
 }
 func (r eventRepository) mapModelToDomainCollection(evn []event) []domain.Event {
@@ -217,3 +914,9 @@ func (r eventRepository) mapModelToDomainCollection(evn []event) []domain.Event
 	}
 	return events
 }
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
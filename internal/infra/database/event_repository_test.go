@@ -0,0 +1,169 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"github.com/upper/db/v4"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEventRepository_FindFacetsRollsUpCategories(t *testing.T) {
+	sess := testSession(t)
+	eventRepo := NewEventRepository(sess)
+	categoryRepo := NewCategoryRepository(sess)
+
+	suffix := time.Now().UnixNano()
+	var parentId *uint64
+	categories := make([]domain.Category, 3)
+	for i := range categories {
+		c, err := categoryRepo.Save(domain.Category{
+			ParentId: parentId,
+			Name:     fmt.Sprintf("Category %d", i),
+			Slug:     fmt.Sprintf("category-%d-%d", i, suffix),
+		})
+		if err != nil {
+			t.Fatalf("CategoryRepository.Save: %s", err)
+		}
+		categories[i], parentId = c, &c.Id
+	}
+
+	owner := createTestUser(t, sess)
+	city := fmt.Sprintf("Facets %d", suffix)
+	// root: 1 event, child: 2, grandchild: 1
+	for _, c := range []domain.Category{categories[0], categories[1], categories[1], categories[2]} {
+		_, err := eventRepo.Save(domain.Event{
+			UserId:     owner.Id,
+			Title:      "Test event",
+			Status:     domain.NewEventStatus,
+			Date:       time.Now().Add(24 * time.Hour),
+			City:       city,
+			CategoryId: &c.Id,
+		})
+		if err != nil {
+			t.Fatalf("EventRepository.Save: %s", err)
+		}
+	}
+
+	facets, err := eventRepo.FindFacets(UrlFilters{City: city})
+	if err != nil {
+		t.Fatalf("FindFacets: %s", err)
+	}
+
+	counts := make(map[uint64]uint64, len(facets.Categories))
+	for _, f := range facets.Categories {
+		counts[f.CategoryId] = f.Count
+	}
+	want := map[uint64]uint64{categories[0].Id: 4, categories[1].Id: 3, categories[2].Id: 1}
+	for id, count := range want {
+		if counts[id] != count {
+			t.Errorf("category %d count = %d, want %d", id, counts[id], count)
+		}
+	}
+
+	// the counts match what filtering by the category returns
+	_, total, err := eventRepo.FindList(UrlFilters{City: city, CategoryId: categories[1].Id}, domain.Pagination{Page: 1, CountPerPage: 10})
+	if err != nil {
+		t.Fatalf("FindList: %s", err)
+	}
+	if total != counts[categories[1].Id] {
+		t.Errorf("FindList total = %d, facet count = %d", total, counts[categories[1].Id])
+	}
+}
+
+func TestEventRepository_Update(t *testing.T) {
+	sess := testSession(t)
+	repo := NewEventRepository(sess)
+
+	owner := createTestUser(t, sess)
+	evn := createTestEvent(t, sess, owner.Id, nil)
+	stored := evn
+
+	evn.Location = "New location"
+	evn.Date = evn.Date.Add(time.Hour)
+	updated, previous, err := repo.Update(evn)
+	if err != nil {
+		t.Fatalf("Update: %s", err)
+	}
+	if updated.Location != "New location" {
+		t.Errorf("updated location = %q, want %q", updated.Location, "New location")
+	}
+	if previous.Location != stored.Location || !previous.Date.Equal(stored.Date) {
+		t.Errorf("previous = %s at %s, want %s at %s", previous.Location, previous.Date, stored.Location, stored.Date)
+	}
+
+	// deleted events stay as they are
+	err = repo.Delete(evn.Id)
+	if err != nil {
+		t.Fatalf("Delete: %s", err)
+	}
+	_, _, err = repo.Update(evn)
+	if !errors.Is(err, db.ErrNoMoreRows) {
+		t.Errorf("Update of a deleted event = %v, want %v", err, db.ErrNoMoreRows)
+	}
+}
+
+func TestEventRepository_FindListEscapesSnippets(t *testing.T) {
+	sess := testSession(t)
+	repo := NewEventRepository(sess)
+
+	owner := createTestUser(t, sess)
+	word := fmt.Sprintf("zanzibar%d", time.Now().UnixNano())
+	_, err := repo.Save(domain.Event{
+		UserId:      owner.Id,
+		Title:       "Test event",
+		Description: `<img src=x onerror="alert(1)"> ` + word + ` & friends`,
+		Status:      domain.NewEventStatus,
+		Date:        time.Now().Add(24 * time.Hour),
+		Location:    "Test location",
+		City:        "Kyiv",
+	})
+	if err != nil {
+		t.Fatalf("Save: %s", err)
+	}
+
+	events, _, err := repo.FindList(UrlFilters{Search: word}, domain.Pagination{Page: 1, CountPerPage: 10})
+	if err != nil {
+		t.Fatalf("FindList: %s", err)
+	}
+	if len(events) != 1 {
+		t.Fatalf("events = %d, want 1", len(events))
+	}
+	snippet := events[0].Snippet
+	if !strings.Contains(snippet, "<mark>"+word+"</mark>") || strings.Contains(snippet, "<img") ||
+		!strings.Contains(snippet, "&lt;img") {
+		t.Errorf("snippet = %q, want the escaped description with %s highlighted", snippet, word)
+	}
+}
+
+func TestEventRepository_MarkMatchFailed(t *testing.T) {
+	sess := testSession(t)
+	repo := NewEventRepository(sess)
+
+	owner := createTestUser(t, sess)
+	evn := createTestEvent(t, sess, owner.Id, nil)
+
+	matched := func() bool {
+		t.Helper()
+		var row struct {
+			MatchedDate *time.Time `db:"matched_date"`
+		}
+		err := sess.SQL().Select("matched_date").From(EventTableName).Where("id = ?", evn.Id).One(&row)
+		if err != nil {
+			t.Fatalf("select matched_date: %s", err)
+		}
+		return row.MatchedDate != nil
+	}
+
+	for attempt := 1; attempt <= 3; attempt++ {
+		err := repo.MarkMatchFailed(evn.Id, 3)
+		if err != nil {
+			t.Fatalf("MarkMatchFailed: %s", err)
+		}
+		if got := matched(); got != (attempt == 3) {
+			t.Errorf("after attempt %d matched = %t", attempt, got)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package database
+
+import (
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"github.com/upper/db/v4"
+)
+
+const EventRevisionsTableName = "event_revisions"
+
+type eventRevision struct {
+	Id          uint64    `db:"id,omitempty"`
+	EventId     uint64    `db:"event_id"`
+	UserId      *uint64   `db:"user_id"`
+	Changes     string    `db:"changes"` // JSON array of eventChange
+	CreatedDate time.Time `db:"created_date"`
+}
+
+type eventChange struct {
+	Field       string `json:"field"`
+	From        string `json:"from"`
+	To          string `json:"to"`
+	Significant bool   `json:"significant,omitempty"`
+}
+
+// EventRevisionRepository reads the revisions written by EventRepository.Update.
+type EventRevisionRepository interface {
+	FindByEvent(eventId uint64, p domain.Pagination) ([]domain.EventRevision, uint64, error)
+}
+
+type eventRevisionRepository struct {
+	coll db.Collection
+}
+
+func NewEventRevisionRepository(dbSession db.Session) EventRevisionRepository {
+	return eventRevisionRepository{
+		coll: dbSession.Collection(EventRevisionsTableName),
+	}
+}
+
+// FindByEvent returns a page of the revisions of the event, the newest first.
+func (r eventRevisionRepository) FindByEvent(eventId uint64, p domain.Pagination) ([]domain.EventRevision, uint64, error) {
+	query := r.coll.Find(db.Cond{"event_id": eventId})
+	total, err := query.Count()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var revisions []eventRevision
+	err = query.
+		OrderBy("-id").
+		Paginate(uint(p.CountPerPage)).
+		Page(uint(p.Page)).
+		All(&revisions)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	result := make([]domain.EventRevision, len(revisions))
+	for i, rev := range revisions {
+		result[i], err = r.mapModelToDomain(rev)
+		if err != nil {
+			log.Printf("EventRevisionRepository -> FindByEvent -> r.mapModelToDomain(%d): %s", rev.Id, err)
+			return nil, 0, err
+		}
+	}
+	return result, total, nil
+}
+
+func (r eventRevisionRepository) mapModelToDomain(m eventRevision) (domain.EventRevision, error) {
+	var changes []eventChange
+	err := json.Unmarshal([]byte(m.Changes), &changes)
+	if err != nil {
+		return domain.EventRevision{}, err
+	}
+
+	result := domain.EventRevision{
+		Id:          m.Id,
+		EventId:     m.EventId,
+		UserId:      m.UserId,
+		Changes:     make([]domain.EventChange, len(changes)),
+		CreatedDate: m.CreatedDate,
+	}
+	for i, c := range changes {
+		result.Changes[i] = domain.EventChange{
+			Field:       c.Field,
+			From:        c.From,
+			To:          c.To,
+			Significant: c.Significant,
+		}
+	}
+	return result, nil
+}
+
+// saveRevision records the changes of an update within its transaction. Nothing is
+// recorded when nothing has changed. The user is unknown for the updates made by the system.
+func saveRevision(tx db.Session, eventId, userId uint64, changes []domain.EventChange) error {
+	if len(changes) == 0 {
+		return nil
+	}
+
+	models := make([]eventChange, len(changes))
+	for i, c := range changes {
+		models[i] = eventChange{
+			Field:       c.Field,
+			From:        c.From,
+			To:          c.To,
+			Significant: c.Significant,
+		}
+	}
+	data, err := json.Marshal(models)
+	if err != nil {
+		return err
+	}
+
+	rev := eventRevision{
+		EventId:     eventId,
+		Changes:     string(data),
+		CreatedDate: time.Now(),
+	}
+	if userId != 0 {
+		rev.UserId = &userId
+	}
+	_, err = tx.Collection(EventRevisionsTableName).Insert(rev)
+	return err
+}
@@ -0,0 +1,139 @@
+package database
+
+import (
+	"log"
+	"time"
+
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"github.com/upper/db/v4"
+)
+
+const MailQueueTableName = "mail_queue"
+
+type mailItem struct {
+	Id              uint64            `db:"id,omitempty"`
+	To              string            `db:"to_address"`
+	Subject         string            `db:"subject"`
+	Html            string            `db:"html"`
+	Text            string            `db:"text"`
+	Status          domain.MailStatus `db:"status"`
+	Attempts        uint              `db:"attempts"`
+	NextAttemptDate time.Time         `db:"next_attempt_date"`
+	LastError       string            `db:"last_error"`
+	CreatedDate     time.Time         `db:"created_date"`
+	SentDate        *time.Time        `db:"sent_date"`
+}
+
+type MailRepository interface {
+	Save(m domain.Mail) (domain.Mail, error)
+	ClaimDue(limit uint, lease time.Duration) ([]domain.Mail, error)
+	MarkSent(id uint64) error
+	MarkFailed(m domain.Mail, sendErr error) error
+}
+
+type mailRepository struct {
+	coll db.Collection
+	sess db.Session
+}
+
+func NewMailRepository(dbSession db.Session) MailRepository {
+	return mailRepository{
+		coll: dbSession.Collection(MailQueueTableName),
+		sess: dbSession,
+	}
+}
+
+// Save puts the email into the queue, to be sent as soon as possible.
+func (r mailRepository) Save(m domain.Mail) (domain.Mail, error) {
+	item := r.mapDomainToModel(m)
+	item.Status = domain.PendingMailStatus
+	item.CreatedDate, item.NextAttemptDate = time.Now(), time.Now()
+	err := r.coll.InsertReturning(&item)
+	if err != nil {
+		log.Printf("MailRepository -> Save -> r.coll.InsertReturning: %s", err)
+		return domain.Mail{}, err
+	}
+	return r.mapModelToDomain(item), nil
+}
+
+// ClaimDue returns the pending emails whose attempt is due and postpones their next attempt
+// by the lease, so that no other worker picks them up while they are being sent. Emails
+// of a worker which stopped mid-send are retried once the lease is over.
+func (r mailRepository) ClaimDue(limit uint, lease time.Duration) ([]domain.Mail, error) {
+	now := time.Now()
+	due := r.sess.SQL().
+		Select("id").
+		From(MailQueueTableName).
+		Where("status = ? AND next_attempt_date <= ?", domain.PendingMailStatus, now).
+		OrderBy("next_attempt_date", "id").
+		Limit(int(limit)).
+		Amend(func(query string) string {
+			return query + " FOR UPDATE SKIP LOCKED"
+		})
+
+	var items []mailItem
+	err := r.sess.SQL().Iterator(
+		`UPDATE `+MailQueueTableName+` SET next_attempt_date = ? WHERE id IN ? RETURNING *`,
+		now.Add(lease), due,
+	).All(&items)
+	if err != nil {
+		log.Printf("MailRepository -> ClaimDue -> r.sess.SQL().Iterator: %s", err)
+		return nil, err
+	}
+
+	mails := make([]domain.Mail, len(items))
+	for i, item := range items {
+		mails[i] = r.mapModelToDomain(item)
+	}
+	return mails, nil
+}
+
+func (r mailRepository) MarkSent(id uint64) error {
+	return r.coll.Find(db.Cond{"id": id}).Update(map[string]interface{}{
+		"status":    domain.SentMailStatus,
+		"sent_date": time.Now(),
+	})
+}
+
+// MarkFailed records the failed attempt, the email is retried at m.NextAttemptDate unless
+// its status has been set to dead.
+func (r mailRepository) MarkFailed(m domain.Mail, sendErr error) error {
+	return r.coll.Find(db.Cond{"id": m.Id}).Update(map[string]interface{}{
+		"status":            m.Status,
+		"attempts":          m.Attempts,
+		"next_attempt_date": m.NextAttemptDate,
+		"last_error":        sendErr.Error(),
+	})
+}
+
+func (r mailRepository) mapDomainToModel(d domain.Mail) mailItem {
+	return mailItem{
+		Id:              d.Id,
+		To:              d.To,
+		Subject:         d.Subject,
+		Html:            d.Html,
+		Text:            d.Text,
+		Status:          d.Status,
+		Attempts:        d.Attempts,
+		NextAttemptDate: d.NextAttemptDate,
+		LastError:       d.LastError,
+		CreatedDate:     d.CreatedDate,
+		SentDate:        d.SentDate,
+	}
+}
+
+func (r mailRepository) mapModelToDomain(m mailItem) domain.Mail {
+	return domain.Mail{
+		Id:              m.Id,
+		To:              m.To,
+		Subject:         m.Subject,
+		Html:            m.Html,
+		Text:            m.Text,
+		Status:          m.Status,
+		Attempts:        m.Attempts,
+		NextAttemptDate: m.NextAttemptDate,
+		LastError:       m.LastError,
+		CreatedDate:     m.CreatedDate,
+		SentDate:        m.SentDate,
+	}
+}
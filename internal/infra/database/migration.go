@@ -12,6 +12,17 @@ import (
 	"strconv"
 )
 
+// ConnectionUrl is the URL of the database for the connections made outside of upper/db.
+func ConnectionUrl(conf config.Configuration) string {
+	return fmt.Sprintf(
+		"postgres://%s:%s@%s/%s?sslmode=disable",
+		conf.DatabaseUser,
+		conf.DatabasePassword,
+		conf.DatabaseHost,
+		conf.DatabaseName,
+	)
+}
+
 func Migrate(conf config.Configuration) error {
 	if conf.MigrateToVersion == "" {
 		return nil
@@ -24,17 +35,9 @@ func Migrate(conf config.Configuration) error {
 		return err
 	}
 
-	urlString := fmt.Sprintf(
-		"postgres://%s:%s@%s/%s?sslmode=disable",
-		conf.DatabaseUser,
-		conf.DatabasePassword,
-		conf.DatabaseHost,
-		conf.DatabaseName,
-	)
-
 	m, err := migrate.New(
 		"file://"+migrationsPath,
-		urlString)
+		ConnectionUrl(conf))
 	if err != nil {
 		return err
 	}
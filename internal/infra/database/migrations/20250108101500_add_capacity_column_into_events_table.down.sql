@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS public.waitlist;
+ALTER TABLE events DROP COLUMN capacity;
@@ -0,0 +1,12 @@
+ALTER TABLE events ADD COLUMN capacity int NULL;
+
+CREATE TABLE IF NOT EXISTS public.waitlist
+(
+    id              serial PRIMARY KEY,
+    event_id        int NOT NULL references public.events (id),
+    user_id         int NOT NULL references public.users (id),
+    created_date    timestamptz NOT NULL,
+    UNIQUE (event_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS waitlist_event_id_idx ON public.waitlist (event_id, id);
@@ -0,0 +1,3 @@
+ALTER TABLE events DROP COLUMN attendees_public;
+ALTER TABLE subscriptions DROP COLUMN created_date;
+ALTER TABLE subscriptions DROP COLUMN rsvp;
@@ -0,0 +1,3 @@
+ALTER TABLE subscriptions ADD COLUMN rsvp VARCHAR(30) NOT NULL DEFAULT 'GOING';
+ALTER TABLE subscriptions ADD COLUMN created_date timestamptz NOT NULL DEFAULT now();
+ALTER TABLE events ADD COLUMN attendees_public boolean NOT NULL DEFAULT false;
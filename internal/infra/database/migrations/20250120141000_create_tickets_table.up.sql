@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS public.tickets
+(
+    id              serial PRIMARY KEY,
+    event_id        int NOT NULL references public.events (id),
+    user_id         int NOT NULL references public.users (id),
+    nonce           varchar(50) NOT NULL,
+    checked_in_date timestamptz NULL,
+    checked_in_by   int NULL references public.users (id),
+    created_date    timestamptz NOT NULL,
+    UNIQUE (event_id, user_id)
+);
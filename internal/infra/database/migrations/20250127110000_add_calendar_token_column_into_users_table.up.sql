@@ -0,0 +1,2 @@
+ALTER TABLE users ADD COLUMN calendar_token VARCHAR(64) NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS users_calendar_token_idx ON users (calendar_token);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS events_user_id_external_uid_idx;
+ALTER TABLE events DROP COLUMN external_uid;
+ALTER TABLE events DROP COLUMN end_date;
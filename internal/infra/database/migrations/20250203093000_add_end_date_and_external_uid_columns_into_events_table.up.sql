@@ -0,0 +1,3 @@
+ALTER TABLE events ADD COLUMN end_date timestamptz NULL;
+ALTER TABLE events ADD COLUMN external_uid VARCHAR(255) NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS events_user_id_external_uid_idx ON events (user_id, external_uid);
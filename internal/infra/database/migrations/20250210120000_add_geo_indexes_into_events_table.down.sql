@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS events_lat_lon_idx;
+DROP INDEX IF EXISTS events_earth_location_idx;
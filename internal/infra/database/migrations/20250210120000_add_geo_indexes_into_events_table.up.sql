@@ -0,0 +1,5 @@
+CREATE EXTENSION IF NOT EXISTS cube;
+CREATE EXTENSION IF NOT EXISTS earthdistance;
+
+CREATE INDEX IF NOT EXISTS events_earth_location_idx ON events USING gist (ll_to_earth(lat, lon));
+CREATE INDEX IF NOT EXISTS events_lat_lon_idx ON events (lat, lon);
@@ -0,0 +1,12 @@
+-- There is no built-in Ukrainian dictionary, so every field is indexed twice:
+-- with the english stemmer and with the language-agnostic simple configuration.
+ALTER TABLE events ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('english', coalesce(title, '')), 'A') ||
+    setweight(to_tsvector('simple', coalesce(title, '')), 'A') ||
+    setweight(to_tsvector('english', coalesce(description, '')), 'B') ||
+    setweight(to_tsvector('simple', coalesce(description, '')), 'B') ||
+    setweight(to_tsvector('simple', coalesce(city, '')), 'C') ||
+    setweight(to_tsvector('simple', coalesce(location, '')), 'C')
+) STORED;
+
+CREATE INDEX IF NOT EXISTS events_search_vector_idx ON events USING gin (search_vector);
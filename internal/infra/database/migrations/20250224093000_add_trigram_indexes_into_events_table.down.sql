@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS events_title_trgm_idx;
+DROP INDEX IF EXISTS events_location_trgm_idx;
+DROP INDEX IF EXISTS events_city_trgm_idx;
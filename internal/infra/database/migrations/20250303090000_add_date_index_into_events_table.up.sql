@@ -0,0 +1 @@
+CREATE INDEX IF NOT EXISTS events_date_idx ON events (date) WHERE deleted_date IS NULL;
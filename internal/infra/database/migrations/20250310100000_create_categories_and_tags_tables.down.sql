@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS public.event_tags;
+DROP TABLE IF EXISTS public.tags;
+DROP INDEX IF EXISTS events_category_id_idx;
+ALTER TABLE events DROP COLUMN category_id;
+DROP TABLE IF EXISTS public.categories;
@@ -0,0 +1,27 @@
+CREATE TABLE IF NOT EXISTS public.categories
+(
+    id           serial PRIMARY KEY,
+    parent_id    int NULL references public.categories (id),
+    name         varchar(80) NOT NULL,
+    slug         varchar(80) NOT NULL UNIQUE,
+    created_date timestamptz NOT NULL,
+    updated_date timestamptz NOT NULL
+);
+CREATE INDEX IF NOT EXISTS categories_parent_id_idx ON categories (parent_id);
+
+ALTER TABLE events ADD COLUMN category_id int NULL references public.categories (id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS events_category_id_idx ON events (category_id);
+
+CREATE TABLE IF NOT EXISTS public.tags
+(
+    id   serial PRIMARY KEY,
+    name varchar(30) NOT NULL UNIQUE
+);
+
+CREATE TABLE IF NOT EXISTS public.event_tags
+(
+    event_id int NOT NULL references public.events (id) ON DELETE CASCADE,
+    tag_id   int NOT NULL references public.tags (id) ON DELETE CASCADE,
+    PRIMARY KEY (event_id, tag_id)
+);
+CREATE INDEX IF NOT EXISTS event_tags_tag_id_idx ON event_tags (tag_id);
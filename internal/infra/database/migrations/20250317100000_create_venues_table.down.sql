@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS events_venue_id_idx;
+ALTER TABLE events DROP COLUMN venue_id;
+DROP TABLE IF EXISTS public.venue_photos;
+DROP TABLE IF EXISTS public.venues;
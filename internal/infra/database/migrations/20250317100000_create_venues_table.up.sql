@@ -0,0 +1,44 @@
+CREATE TABLE IF NOT EXISTS public.venues
+(
+    id           serial PRIMARY KEY,
+    user_id      int NOT NULL references public.users (id),
+    name         VARCHAR(120) NOT NULL,
+    address      VARCHAR(255) NOT NULL DEFAULT '',
+    city         VARCHAR(255) NOT NULL DEFAULT '',
+    lat          float NOT NULL,
+    lon          float NOT NULL,
+    capacity     int NULL,
+    created_date timestamptz NOT NULL,
+    updated_date timestamptz NOT NULL,
+    deleted_date timestamptz NULL
+);
+
+CREATE TABLE IF NOT EXISTS public.venue_photos
+(
+    id           serial PRIMARY KEY,
+    venue_id     int NOT NULL references public.venues (id) ON DELETE CASCADE,
+    image        VARCHAR(255) NOT NULL,
+    created_date timestamptz NOT NULL
+);
+CREATE INDEX IF NOT EXISTS venue_photos_venue_id_idx ON venue_photos (venue_id);
+
+ALTER TABLE events ADD COLUMN venue_id int NULL references public.venues (id);
+CREATE INDEX IF NOT EXISTS events_venue_id_idx ON events (venue_id);
+
+-- One venue per distinct place of the existing events: the same location and city
+-- (ignoring case and surrounding spaces) at the same coordinates, rounded to ~10 m.
+-- The venue belongs to the owner of the earliest event held there.
+INSERT INTO venues (user_id, name, address, city, lat, lon, created_date, updated_date)
+SELECT DISTINCT ON (LOWER(TRIM(location)), LOWER(TRIM(city)), ROUND(lat::numeric, 4), ROUND(lon::numeric, 4))
+       user_id, TRIM(location), TRIM(location), TRIM(city), lat, lon, now(), now()
+FROM events
+WHERE TRIM(location) <> ''
+ORDER BY LOWER(TRIM(location)), LOWER(TRIM(city)), ROUND(lat::numeric, 4), ROUND(lon::numeric, 4), created_date;
+
+UPDATE events e
+SET venue_id = v.id
+FROM venues v
+WHERE LOWER(TRIM(e.location)) = LOWER(v.name)
+  AND LOWER(TRIM(e.city)) = LOWER(v.city)
+  AND ROUND(e.lat::numeric, 4) = ROUND(v.lat::numeric, 4)
+  AND ROUND(e.lon::numeric, 4) = ROUND(v.lon::numeric, 4);
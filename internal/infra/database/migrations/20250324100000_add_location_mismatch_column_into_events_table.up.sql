@@ -0,0 +1 @@
+ALTER TABLE events ADD COLUMN location_mismatch boolean NOT NULL DEFAULT false;
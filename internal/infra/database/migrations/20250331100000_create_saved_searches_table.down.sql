@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS events_unmatched_idx;
+ALTER TABLE events DROP COLUMN matched_date;
+DROP TABLE IF EXISTS public.saved_search_matches;
+DROP TABLE IF EXISTS public.saved_searches;
@@ -0,0 +1,33 @@
+CREATE TABLE IF NOT EXISTS public.saved_searches
+(
+    id               serial PRIMARY KEY,
+    user_id          int NOT NULL references public.users (id) ON DELETE CASCADE,
+    name             VARCHAR(80) NOT NULL,
+    search           VARCHAR(255) NOT NULL DEFAULT '',
+    city             VARCHAR(255) NOT NULL DEFAULT '',
+    lat              float NULL,
+    lon              float NULL,
+    radius_km        float NULL,
+    category_id      int NULL references public.categories (id) ON DELETE CASCADE,
+    channel          VARCHAR(20) NOT NULL,
+    frequency        VARCHAR(20) NOT NULL,
+    last_digest_date timestamptz NULL,
+    created_date     timestamptz NOT NULL,
+    updated_date     timestamptz NOT NULL
+);
+CREATE INDEX IF NOT EXISTS saved_searches_user_id_idx ON saved_searches (user_id);
+
+CREATE TABLE IF NOT EXISTS public.saved_search_matches
+(
+    saved_search_id int NOT NULL references public.saved_searches (id) ON DELETE CASCADE,
+    event_id        int NOT NULL references public.events (id) ON DELETE CASCADE,
+    created_date    timestamptz NOT NULL,
+    notified_date   timestamptz NULL,
+    PRIMARY KEY (saved_search_id, event_id)
+);
+CREATE INDEX IF NOT EXISTS saved_search_matches_pending_idx ON saved_search_matches (saved_search_id) WHERE notified_date IS NULL;
+
+-- The matcher picks up the events without matched_date, the existing ones are not new anymore.
+ALTER TABLE events ADD COLUMN matched_date timestamptz NULL;
+UPDATE events SET matched_date = now();
+CREATE INDEX IF NOT EXISTS events_unmatched_idx ON events (id) WHERE matched_date IS NULL;
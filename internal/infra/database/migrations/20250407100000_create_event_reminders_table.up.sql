@@ -0,0 +1,11 @@
+-- Sent reminders, a reminder is due while its offset before the event date has passed
+-- and there is no row for the subscriber, offset and event date yet.
+CREATE TABLE IF NOT EXISTS public.event_reminders
+(
+    event_id        int NOT NULL references public.events (id) ON DELETE CASCADE,
+    user_id         int NOT NULL references public.users (id) ON DELETE CASCADE,
+    offset_minutes  int NOT NULL,
+    occurrence_date timestamptz NOT NULL,
+    sent_date       timestamptz NOT NULL,
+    PRIMARY KEY (event_id, user_id, offset_minutes, occurrence_date)
+);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS public.notification_preferences;
+DROP TABLE IF EXISTS public.notifications;
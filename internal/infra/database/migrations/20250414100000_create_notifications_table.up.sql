@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS public.notifications
+(
+    id           serial PRIMARY KEY,
+    user_id      int NOT NULL references public.users (id) ON DELETE CASCADE,
+    event_id     int NULL references public.events (id) ON DELETE CASCADE,
+    type         VARCHAR(40) NOT NULL,
+    channel      VARCHAR(20) NOT NULL,
+    title        VARCHAR(255) NOT NULL,
+    body         text NOT NULL DEFAULT '',
+    read_date    timestamptz NULL,
+    created_date timestamptz NOT NULL
+);
+CREATE INDEX IF NOT EXISTS notifications_user_id_idx ON notifications (user_id, id);
+CREATE INDEX IF NOT EXISTS notifications_unread_idx ON notifications (user_id) WHERE read_date IS NULL;
+
+-- The types without a row are enabled.
+CREATE TABLE IF NOT EXISTS public.notification_preferences
+(
+    user_id int NOT NULL references public.users (id) ON DELETE CASCADE,
+    type    VARCHAR(40) NOT NULL,
+    enabled boolean NOT NULL,
+    PRIMARY KEY (user_id, type)
+);
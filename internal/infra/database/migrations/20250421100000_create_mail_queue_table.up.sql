@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS public.mail_queue
+(
+    id                serial PRIMARY KEY,
+    to_address        VARCHAR(255) NOT NULL,
+    subject           VARCHAR(255) NOT NULL,
+    html              text NOT NULL,
+    text              text NOT NULL,
+    status            VARCHAR(20) NOT NULL,
+    attempts          int NOT NULL DEFAULT 0,
+    next_attempt_date timestamptz NOT NULL,
+    last_error        text NOT NULL DEFAULT '',
+    created_date      timestamptz NOT NULL,
+    sent_date         timestamptz NULL
+);
+CREATE INDEX IF NOT EXISTS mail_queue_pending_idx ON mail_queue (next_attempt_date) WHERE status = 'PENDING';
+
+ALTER TABLE users ADD COLUMN locale VARCHAR(10) NOT NULL DEFAULT 'en';
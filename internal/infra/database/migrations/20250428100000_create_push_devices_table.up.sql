@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS public.push_devices
+(
+    id           serial PRIMARY KEY,
+    user_id      int NOT NULL,
+    session_uuid varchar(50) NOT NULL,
+    platform     VARCHAR(20) NOT NULL,
+    token        text NOT NULL UNIQUE,
+    p256dh       VARCHAR(255) NOT NULL DEFAULT '',
+    auth         VARCHAR(255) NOT NULL DEFAULT '',
+    locale       VARCHAR(10) NOT NULL,
+    created_date timestamptz NOT NULL,
+    updated_date timestamptz NOT NULL,
+    FOREIGN KEY (user_id, session_uuid) REFERENCES public.sessions (user_id, uuid) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS push_devices_user_id_idx ON push_devices (user_id);
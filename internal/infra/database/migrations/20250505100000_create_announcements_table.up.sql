@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS public.announcements
+(
+    id           serial PRIMARY KEY,
+    event_id     int NOT NULL references public.events (id) ON DELETE CASCADE,
+    user_id      int NOT NULL references public.users (id),
+    title        VARCHAR(120) NOT NULL,
+    body         text NOT NULL,
+    channel      VARCHAR(20) NOT NULL,
+    recipients   int NOT NULL DEFAULT 0,
+    created_date timestamptz NOT NULL
+);
+CREATE INDEX IF NOT EXISTS announcements_event_id_idx ON announcements (event_id, created_date);
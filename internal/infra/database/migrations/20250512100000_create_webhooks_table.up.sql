@@ -0,0 +1,29 @@
+CREATE TABLE IF NOT EXISTS public.webhooks
+(
+    id           serial PRIMARY KEY,
+    user_id      int NOT NULL references public.users (id) ON DELETE CASCADE,
+    url          VARCHAR(2048) NOT NULL,
+    secret       VARCHAR(100) NOT NULL,
+    events       text[] NOT NULL,
+    active       boolean NOT NULL DEFAULT true,
+    created_date timestamptz NOT NULL,
+    updated_date timestamptz NOT NULL
+);
+CREATE INDEX IF NOT EXISTS webhooks_user_id_idx ON webhooks (user_id);
+
+CREATE TABLE IF NOT EXISTS public.webhook_deliveries
+(
+    id                serial PRIMARY KEY,
+    webhook_id        int NOT NULL references public.webhooks (id) ON DELETE CASCADE,
+    event_type        VARCHAR(40) NOT NULL,
+    payload           text NOT NULL,
+    status            VARCHAR(20) NOT NULL,
+    attempts          int NOT NULL DEFAULT 0,
+    next_attempt_date timestamptz NOT NULL,
+    response_code     int NOT NULL DEFAULT 0,
+    error             text NOT NULL DEFAULT '',
+    created_date      timestamptz NOT NULL,
+    delivered_date    timestamptz NULL
+);
+CREATE INDEX IF NOT EXISTS webhook_deliveries_webhook_id_idx ON webhook_deliveries (webhook_id, id);
+CREATE INDEX IF NOT EXISTS webhook_deliveries_pending_idx ON webhook_deliveries (next_attempt_date) WHERE status = 'PENDING';
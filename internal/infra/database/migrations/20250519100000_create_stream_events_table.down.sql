@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS public.stream_events;
+DROP FUNCTION IF EXISTS notify_stream_event();
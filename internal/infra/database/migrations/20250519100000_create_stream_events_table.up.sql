@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS public.stream_events
+(
+    id           bigserial PRIMARY KEY,
+    user_id      int NOT NULL references public.users (id) ON DELETE CASCADE,
+    type         VARCHAR(40) NOT NULL,
+    data         text NOT NULL,
+    created_date timestamptz NOT NULL
+);
+CREATE INDEX IF NOT EXISTS stream_events_user_id_idx ON stream_events (user_id, id);
+CREATE INDEX IF NOT EXISTS stream_events_created_date_idx ON stream_events (created_date);
+
+-- Wakes the streams of the user on every server instance, they read the new events themselves.
+CREATE OR REPLACE FUNCTION notify_stream_event() RETURNS trigger AS
+$$
+BEGIN
+    PERFORM pg_notify('stream_events', NEW.user_id::text);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER stream_events_notify
+    AFTER INSERT
+    ON stream_events
+    FOR EACH ROW
+EXECUTE FUNCTION notify_stream_event();
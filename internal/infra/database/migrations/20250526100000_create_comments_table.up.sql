@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS public.comments
+(
+    id           serial PRIMARY KEY,
+    event_id     int NOT NULL references public.events (id) ON DELETE CASCADE,
+    user_id      int NOT NULL references public.users (id) ON DELETE CASCADE,
+    parent_id    int references public.comments (id) ON DELETE CASCADE,
+    body         text NOT NULL,
+    created_date timestamptz NOT NULL,
+    updated_date timestamptz
+);
+CREATE INDEX IF NOT EXISTS comments_event_id_idx ON comments (event_id, created_date) WHERE parent_id IS NULL;
+CREATE INDEX IF NOT EXISTS comments_parent_id_idx ON comments (parent_id, created_date);
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS public.reviews
+(
+    id           serial PRIMARY KEY,
+    event_id     int NOT NULL references public.events (id) ON DELETE CASCADE,
+    user_id      int NOT NULL references public.users (id) ON DELETE CASCADE,
+    rating       smallint NOT NULL CHECK (rating BETWEEN 1 AND 5),
+    body         text NOT NULL DEFAULT '',
+    reply        text,
+    reply_date   timestamptz,
+    created_date timestamptz NOT NULL,
+    updated_date timestamptz,
+    UNIQUE (event_id, user_id)
+);
+CREATE INDEX IF NOT EXISTS reviews_event_id_idx ON reviews (event_id, created_date);
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS public.event_revisions
+(
+    id           serial PRIMARY KEY,
+    event_id     int NOT NULL references public.events (id) ON DELETE CASCADE,
+    user_id      int references public.users (id) ON DELETE SET NULL,
+    changes      jsonb NOT NULL,
+    created_date timestamptz NOT NULL
+);
+CREATE INDEX IF NOT EXISTS event_revisions_event_id_idx ON event_revisions (event_id, id);
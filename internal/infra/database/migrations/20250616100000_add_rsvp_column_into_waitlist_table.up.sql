@@ -0,0 +1 @@
+ALTER TABLE waitlist ADD COLUMN rsvp VARCHAR(30) NOT NULL DEFAULT 'GOING';
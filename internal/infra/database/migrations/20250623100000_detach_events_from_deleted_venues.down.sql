@@ -0,0 +1 @@
+-- the events stay detached, their place is kept in their own columns
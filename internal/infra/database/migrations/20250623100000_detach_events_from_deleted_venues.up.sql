@@ -0,0 +1 @@
+UPDATE events SET venue_id = NULL WHERE venue_id IN (SELECT id FROM venues WHERE deleted_date IS NOT NULL);
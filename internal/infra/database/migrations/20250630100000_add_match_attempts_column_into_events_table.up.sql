@@ -0,0 +1 @@
+ALTER TABLE events ADD COLUMN match_attempts INTEGER NOT NULL DEFAULT 0;
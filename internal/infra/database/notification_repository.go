@@ -0,0 +1,240 @@
+package database
+
+import (
+	"log"
+	"time"
+
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"github.com/upper/db/v4"
+)
+
+const (
+	NotificationsTableName           = "notifications"
+	NotificationPreferencesTableName = "notification_preferences"
+)
+
+type notification struct {
+	Id          uint64                     `db:"id,omitempty"`
+	UserId      uint64                     `db:"user_id"`
+	EventId     *uint64                    `db:"event_id"`
+	Type        domain.NotificationType    `db:"type"`
+	Channel     domain.NotificationChannel `db:"channel"`
+	Title       string                     `db:"title"`
+	Body        string                     `db:"body"`
+	ReadDate    *time.Time                 `db:"read_date"`
+	CreatedDate time.Time                  `db:"created_date"`
+}
+
+type notificationPreference struct {
+	UserId  uint64                  `db:"user_id"`
+	Type    domain.NotificationType `db:"type"`
+	Enabled bool                    `db:"enabled"`
+}
+
+type NotificationFilters struct {
+	UserId     uint64
+	UnreadOnly bool
+}
+
+type NotificationRepository interface {
+	SaveMany(notifications []domain.Notification) error
+	Find(id uint64) (domain.Notification, error)
+	FindList(filters NotificationFilters, p domain.Pagination) ([]domain.Notification, uint64, error)
+	CountUnread(userId uint64) (uint64, error)
+	MarkRead(userId uint64, ids []uint64) error
+	MarkAllRead(userId uint64) error
+	Delete(id uint64) error
+	FindPreferences(userId uint64) ([]domain.NotificationPreference, error)
+	SavePreferences(userId uint64, prefs []domain.NotificationPreference) error
+	FindDisabled(t domain.NotificationType, userIds []uint64) (map[uint64]bool, error)
+}
+
+type notificationRepository struct {
+	coll db.Collection
+	sess db.Session
+}
+
+func NewNotificationRepository(dbSession db.Session) NotificationRepository {
+	return notificationRepository{
+		coll: dbSession.Collection(NotificationsTableName),
+		sess: dbSession,
+	}
+}
+
+// SaveMany inserts the notifications with a single statement.
+func (r notificationRepository) SaveMany(notifications []domain.Notification) error {
+	if len(notifications) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	inserter := r.sess.SQL().
+		InsertInto(NotificationsTableName).
+		Columns("user_id", "event_id", "type", "channel", "title", "body", "created_date")
+	for _, n := range notifications {
+		m := r.mapDomainToModel(n)
+		inserter = inserter.Values(m.UserId, m.EventId, m.Type, m.Channel, m.Title, m.Body, now)
+	}
+
+	_, err := inserter.Exec()
+	if err != nil {
+		log.Printf("NotificationRepository -> SaveMany -> inserter.Exec: %s", err)
+		return err
+	}
+	return nil
+}
+
+func (r notificationRepository) Find(id uint64) (domain.Notification, error) {
+	var n notification
+	err := r.coll.Find(db.Cond{"id": id}).One(&n)
+	if err != nil {
+		return domain.Notification{}, err
+	}
+	return r.mapModelToDomain(n), nil
+}
+
+// FindList returns a page of the user's notifications, the newest first.
+func (r notificationRepository) FindList(filters NotificationFilters, p domain.Pagination) ([]domain.Notification, uint64, error) {
+	conds := db.Cond{"user_id": filters.UserId}
+	if filters.UnreadOnly {
+		conds["read_date"] = nil
+	}
+
+	query := r.coll.Find(conds)
+	total, err := query.Count()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var notifications []notification
+	err = query.
+		OrderBy("-id").
+		Paginate(uint(p.CountPerPage)).
+		Page(uint(p.Page)).
+		All(&notifications)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	result := make([]domain.Notification, len(notifications))
+	for i, n := range notifications {
+		result[i] = r.mapModelToDomain(n)
+	}
+	return result, total, nil
+}
+
+func (r notificationRepository) CountUnread(userId uint64) (uint64, error) {
+	return r.coll.Find(db.Cond{"user_id": userId, "read_date": nil}).Count()
+}
+
+func (r notificationRepository) MarkRead(userId uint64, ids []uint64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return r.coll.
+		Find(db.Cond{"user_id": userId, "id IN": ids, "read_date": nil}).
+		Update(map[string]interface{}{"read_date": time.Now()})
+}
+
+func (r notificationRepository) MarkAllRead(userId uint64) error {
+	return r.coll.
+		Find(db.Cond{"user_id": userId, "read_date": nil}).
+		Update(map[string]interface{}{"read_date": time.Now()})
+}
+
+func (r notificationRepository) Delete(id uint64) error {
+	return r.coll.Find(db.Cond{"id": id}).Delete()
+}
+
+// FindPreferences returns the preferences the user has set, the other types are enabled.
+func (r notificationRepository) FindPreferences(userId uint64) ([]domain.NotificationPreference, error) {
+	var prefs []notificationPreference
+	err := r.sess.Collection(NotificationPreferencesTableName).Find(db.Cond{"user_id": userId}).All(&prefs)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]domain.NotificationPreference, len(prefs))
+	for i, p := range prefs {
+		result[i] = domain.NotificationPreference{Type: p.Type, Enabled: p.Enabled}
+	}
+	return result, nil
+}
+
+func (r notificationRepository) SavePreferences(userId uint64, prefs []domain.NotificationPreference) error {
+	return r.sess.Tx(func(tx db.Session) error {
+		for _, p := range prefs {
+			_, err := tx.SQL().Exec(
+				`INSERT INTO `+NotificationPreferencesTableName+` (user_id, type, enabled) VALUES (?, ?, ?)
+				ON CONFLICT (user_id, type) DO UPDATE SET enabled = EXCLUDED.enabled`,
+				userId, p.Type, p.Enabled,
+			)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// FindDisabled returns which of the users have turned the type off.
+func (r notificationRepository) FindDisabled(t domain.NotificationType, userIds []uint64) (map[uint64]bool, error) {
+	disabled := make(map[uint64]bool)
+	if len(userIds) == 0 {
+		return disabled, nil
+	}
+
+	var prefs []notificationPreference
+	err := r.sess.Collection(NotificationPreferencesTableName).
+		Find(db.Cond{"type": t, "enabled": false, "user_id IN": userIds}).
+		All(&prefs)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range prefs {
+		disabled[p.UserId] = true
+	}
+	return disabled, nil
+}
+
+func (r notificationRepository) mapDomainToModel(d domain.Notification) notification {
+	var eventId *uint64
+	if d.EventId != 0 {
+		eventId = &d.EventId
+	}
+	channel := d.Channel
+	if channel == "" {
+		channel = domain.InAppNotificationChannel
+	}
+
+	return notification{
+		Id:          d.Id,
+		UserId:      d.UserId,
+		EventId:     eventId,
+		Type:        d.Type,
+		Channel:     channel,
+		Title:       d.Title,
+		Body:        d.Body,
+		ReadDate:    d.ReadDate,
+		CreatedDate: d.CreatedDate,
+	}
+}
+
+func (r notificationRepository) mapModelToDomain(m notification) domain.Notification {
+	var eventId uint64
+	if m.EventId != nil {
+		eventId = *m.EventId
+	}
+
+	return domain.Notification{
+		Id:          m.Id,
+		UserId:      m.UserId,
+		EventId:     eventId,
+		Type:        m.Type,
+		Channel:     m.Channel,
+		Title:       m.Title,
+		Body:        m.Body,
+		ReadDate:    m.ReadDate,
+		CreatedDate: m.CreatedDate,
+	}
+}
@@ -0,0 +1,149 @@
+package database
+
+import (
+	"log"
+	"time"
+
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"github.com/google/uuid"
+	"github.com/upper/db/v4"
+)
+
+const PushDevicesTableName = "push_devices"
+
+type pushDevice struct {
+	Id          uint64              `db:"id,omitempty"`
+	UserId      uint64              `db:"user_id"`
+	SessionUUID uuid.UUID           `db:"session_uuid"`
+	Platform    domain.PushPlatform `db:"platform"`
+	Token       string              `db:"token"`
+	P256dh      string              `db:"p256dh"`
+	Auth        string              `db:"auth"`
+	Locale      string              `db:"locale"`
+	CreatedDate time.Time           `db:"created_date"`
+	UpdatedDate time.Time           `db:"updated_date"`
+}
+
+type PushDeviceRepository interface {
+	Save(d domain.PushDevice) (domain.PushDevice, error)
+	Find(id uint64) (domain.PushDevice, error)
+	FindBySession(sess domain.Session) ([]domain.PushDevice, error)
+	FindByUsers(userIds []uint64) ([]domain.PushDevice, error)
+	Delete(id uint64) error
+	DeleteByToken(token string) error
+}
+
+type pushDeviceRepository struct {
+	coll db.Collection
+	sess db.Session
+}
+
+func NewPushDeviceRepository(dbSession db.Session) PushDeviceRepository {
+	return pushDeviceRepository{
+		coll: dbSession.Collection(PushDevicesTableName),
+		sess: dbSession,
+	}
+}
+
+// Save registers the device, a token which is already registered is moved to the given
+// session, as the app has been logged in again.
+func (r pushDeviceRepository) Save(d domain.PushDevice) (domain.PushDevice, error) {
+	m := r.mapDomainToModel(d)
+	now := time.Now()
+
+	var devices []pushDevice
+	err := r.sess.SQL().Iterator(
+		`INSERT INTO `+PushDevicesTableName+` (user_id, session_uuid, platform, token, p256dh, auth, locale, created_date, updated_date)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (token) DO UPDATE SET user_id = EXCLUDED.user_id, session_uuid = EXCLUDED.session_uuid,
+			platform = EXCLUDED.platform, p256dh = EXCLUDED.p256dh, auth = EXCLUDED.auth,
+			locale = EXCLUDED.locale, updated_date = EXCLUDED.updated_date
+		RETURNING *`,
+		m.UserId, m.SessionUUID, m.Platform, m.Token, m.P256dh, m.Auth, m.Locale, now, now,
+	).All(&devices)
+	if err != nil {
+		log.Printf("PushDeviceRepository -> Save -> r.sess.SQL().Iterator: %s", err)
+		return domain.PushDevice{}, err
+	}
+	if len(devices) == 0 {
+		return domain.PushDevice{}, db.ErrNoMoreRows
+	}
+	return r.mapModelToDomain(devices[0]), nil
+}
+
+func (r pushDeviceRepository) Find(id uint64) (domain.PushDevice, error) {
+	var d pushDevice
+	err := r.coll.Find(db.Cond{"id": id}).One(&d)
+	if err != nil {
+		return domain.PushDevice{}, err
+	}
+	return r.mapModelToDomain(d), nil
+}
+
+func (r pushDeviceRepository) FindBySession(sess domain.Session) ([]domain.PushDevice, error) {
+	var devices []pushDevice
+	err := r.coll.Find(db.Cond{"user_id": sess.UserId, "session_uuid": sess.UUID}).OrderBy("id").All(&devices)
+	if err != nil {
+		return nil, err
+	}
+	return r.mapModelToDomainCollection(devices), nil
+}
+
+func (r pushDeviceRepository) FindByUsers(userIds []uint64) ([]domain.PushDevice, error) {
+	if len(userIds) == 0 {
+		return nil, nil
+	}
+
+	var devices []pushDevice
+	err := r.coll.Find(db.Cond{"user_id IN": userIds}).All(&devices)
+	if err != nil {
+		return nil, err
+	}
+	return r.mapModelToDomainCollection(devices), nil
+}
+
+func (r pushDeviceRepository) Delete(id uint64) error {
+	return r.coll.Find(db.Cond{"id": id}).Delete()
+}
+
+func (r pushDeviceRepository) DeleteByToken(token string) error {
+	return r.coll.Find(db.Cond{"token": token}).Delete()
+}
+
+func (r pushDeviceRepository) mapDomainToModel(d domain.PushDevice) pushDevice {
+	return pushDevice{
+		Id:          d.Id,
+		UserId:      d.UserId,
+		SessionUUID: d.SessionUUID,
+		Platform:    d.Platform,
+		Token:       d.Token,
+		P256dh:      d.P256dh,
+		Auth:        d.Auth,
+		Locale:      d.Locale,
+		CreatedDate: d.CreatedDate,
+		UpdatedDate: d.UpdatedDate,
+	}
+}
+
+func (r pushDeviceRepository) mapModelToDomain(m pushDevice) domain.PushDevice {
+	return domain.PushDevice{
+		Id:          m.Id,
+		UserId:      m.UserId,
+		SessionUUID: m.SessionUUID,
+		Platform:    m.Platform,
+		Token:       m.Token,
+		P256dh:      m.P256dh,
+		Auth:        m.Auth,
+		Locale:      m.Locale,
+		CreatedDate: m.CreatedDate,
+		UpdatedDate: m.UpdatedDate,
+	}
+}
+
+func (r pushDeviceRepository) mapModelToDomainCollection(devices []pushDevice) []domain.PushDevice {
+	result := make([]domain.PushDevice, len(devices))
+	for i, d := range devices {
+		result[i] = r.mapModelToDomain(d)
+	}
+	return result
+}
@@ -0,0 +1,75 @@
+package database
+
+import (
+	"log"
+	"time"
+
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"github.com/upper/db/v4"
+)
+
+const EventRemindersTableName = "event_reminders"
+
+type ReminderRepository interface {
+	ClaimDue(offset, next time.Duration, now time.Time) ([]domain.Reminder, error)
+}
+
+type reminderRepository struct {
+	sess db.Session
+}
+
+func NewReminderRepository(dbSession db.Session) ReminderRepository {
+	return reminderRepository{
+		sess: dbSession,
+	}
+}
+
+// ClaimDue records as sent and returns the reminders of the offset which are due: the
+// subscribed events start within the offset but later than the next smaller offset, so
+// users subscribing late only get the closest reminder. A reminder is claimed once per
+// subscriber and event date, cancelled and deleted events get none.
+func (r reminderRepository) ClaimDue(offset, next time.Duration, now time.Time) ([]domain.Reminder, error) {
+	var rows []struct {
+		EventId        uint64    `db:"event_id"`
+		UserId         uint64    `db:"user_id"`
+		OccurrenceDate time.Time `db:"occurrence_date"`
+		SentDate       time.Time `db:"sent_date"`
+		Title          string    `db:"title"`
+		Location       string    `db:"location"`
+		City           string    `db:"city"`
+	}
+	err := r.sess.SQL().Iterator(
+		`WITH claimed AS (
+			INSERT INTO `+EventRemindersTableName+` (event_id, user_id, offset_minutes, occurrence_date, sent_date)
+			SELECT s.event_id, s.user_id, ?, e.date, ?
+			FROM `+SubscriptionsTableName+` s JOIN `+EventTableName+` e ON e.id = s.event_id
+			WHERE s.rsvp <> ? AND e.status = ? AND e.deleted_date IS NULL AND e.date > ? AND e.date <= ?
+			ON CONFLICT DO NOTHING
+			RETURNING event_id, user_id, occurrence_date, sent_date
+		)
+		SELECT c.event_id, c.user_id, c.occurrence_date, c.sent_date, e.title, e.location, e.city
+		FROM claimed c JOIN `+EventTableName+` e ON e.id = c.event_id
+		ORDER BY c.event_id, c.user_id`,
+		int(offset/time.Minute), now,
+		domain.DeclinedRsvpStatus, domain.NewEventStatus, now.Add(next), now.Add(offset),
+	).All(&rows)
+	if err != nil {
+		log.Printf("ReminderRepository -> ClaimDue -> r.sess.SQL().Iterator: %s", err)
+		return nil, err
+	}
+
+	reminders := make([]domain.Reminder, len(rows))
+	for i, row := range rows {
+		reminders[i] = domain.Reminder{
+			EventId:        row.EventId,
+			UserId:         row.UserId,
+			Offset:         offset,
+			OccurrenceDate: row.OccurrenceDate,
+			EventTitle:     row.Title,
+			Location:       row.Location,
+			City:           row.City,
+			SentDate:       row.SentDate,
+		}
+	}
+	return reminders, nil
+}
@@ -0,0 +1,58 @@
+package database
+
+import (
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"testing"
+	"time"
+)
+
+func TestReminderRepository_ClaimDueAfterReschedule(t *testing.T) {
+	sess := testSession(t)
+	reminderRepo := NewReminderRepository(sess)
+	eventRepo := NewEventRepository(sess)
+
+	owner, attendee := createTestUser(t, sess), createTestUser(t, sess)
+	evn := createTestEvent(t, sess, owner.Id, nil)
+	evn.Date = time.Now().Add(30 * time.Minute)
+	evn, _, err := eventRepo.Update(evn)
+	if err != nil {
+		t.Fatalf("EventRepository.Update: %s", err)
+	}
+	_, _, err = NewSubscriptionRepository(sess).Subscribe(evn.Id, attendee.Id, domain.GoingRsvpStatus)
+	if err != nil {
+		t.Fatalf("Subscribe: %s", err)
+	}
+
+	claim := func() []domain.Reminder {
+		t.Helper()
+		reminders, err := reminderRepo.ClaimDue(time.Hour, 0, time.Now())
+		if err != nil {
+			t.Fatalf("ClaimDue: %s", err)
+		}
+		var result []domain.Reminder
+		for _, r := range reminders {
+			if r.EventId == evn.Id {
+				result = append(result, r)
+			}
+		}
+		return result
+	}
+
+	if reminders := claim(); len(reminders) != 1 {
+		t.Fatalf("reminders = %d, want 1", len(reminders))
+	}
+	if reminders := claim(); len(reminders) != 0 {
+		t.Fatalf("reminders claimed twice: %+v", reminders)
+	}
+
+	// the event is moved, the reminder is due again for the new time
+	evn.Date = time.Now().Add(50 * time.Minute).Truncate(time.Second)
+	evn, _, err = eventRepo.Update(evn)
+	if err != nil {
+		t.Fatalf("EventRepository.Update: %s", err)
+	}
+	reminders := claim()
+	if len(reminders) != 1 || !reminders[0].OccurrenceDate.Equal(evn.Date) {
+		t.Fatalf("reminders = %+v, want one for %s", reminders, evn.Date)
+	}
+}
@@ -0,0 +1,223 @@
+package database
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"github.com/upper/db/v4"
+)
+
+const ReviewsTableName = "reviews"
+
+// uniqueViolation is the SQLSTATE of a unique constraint violation.
+const uniqueViolation = "23505"
+
+// ErrReviewExists is returned by Save when the user has reviewed the event already.
+var ErrReviewExists = errors.New("the review of the event by the user exists already")
+
+type review struct {
+	Id          uint64     `db:"id,omitempty"`
+	EventId     uint64     `db:"event_id"`
+	UserId      uint64     `db:"user_id"`
+	Rating      uint8      `db:"rating"`
+	Body        string     `db:"body"`
+	Reply       *string    `db:"reply"`
+	ReplyDate   *time.Time `db:"reply_date"`
+	CreatedDate time.Time  `db:"created_date"`
+	UpdatedDate *time.Time `db:"updated_date"`
+}
+
+// authoredReview is a review read along with the public details of its author.
+type authoredReview struct {
+	review           `db:",inline"`
+	AuthorFirstName  string `db:"author_first_name"`
+	AuthorSecondName string `db:"author_second_name"`
+	AuthorImage      string `db:"author_image"`
+}
+
+type ReviewRepository interface {
+	Save(rv domain.Review) (domain.Review, error)
+	Find(id uint64) (domain.Review, error)
+	FindByEvent(eventId uint64, p domain.Pagination) ([]domain.Review, uint64, error)
+	Exists(eventId, userId uint64) (bool, error)
+	Update(rv domain.Review) (domain.Review, error)
+	Reply(id uint64, reply string) (bool, error)
+	FindOrganizerRating(userId uint64) (domain.Rating, error)
+}
+
+type reviewRepository struct {
+	coll db.Collection
+	sess db.Session
+}
+
+func NewReviewRepository(dbSession db.Session) ReviewRepository {
+	return reviewRepository{
+		coll: dbSession.Collection(ReviewsTableName),
+		sess: dbSession,
+	}
+}
+
+func (r reviewRepository) Save(rv domain.Review) (domain.Review, error) {
+	m := r.mapDomainToModel(rv)
+	m.CreatedDate = time.Now()
+	m.UpdatedDate = nil
+	m.Reply = nil
+	m.ReplyDate = nil
+	err := r.coll.InsertReturning(&m)
+	if isUniqueViolation(err) {
+		return domain.Review{}, ErrReviewExists
+	} else if err != nil {
+		log.Printf("ReviewRepository -> Save -> r.coll.InsertReturning: %s", err)
+		return domain.Review{}, err
+	}
+	return r.Find(m.Id)
+}
+
+func (r reviewRepository) Find(id uint64) (domain.Review, error) {
+	var rv authoredReview
+	err := r.query().Where("r.id = ?", id).One(&rv)
+	if err != nil {
+		return domain.Review{}, err
+	}
+	return r.mapModelToDomain(rv), nil
+}
+
+// FindByEvent returns a page of the reviews of the event, the newest first.
+func (r reviewRepository) FindByEvent(eventId uint64, p domain.Pagination) ([]domain.Review, uint64, error) {
+	var reviews []authoredReview
+	paginator := r.query().
+		Where("r.event_id = ?", eventId).
+		OrderBy("-r.created_date", "-r.id").
+		Paginate(uint(p.CountPerPage))
+
+	err := paginator.Page(uint(p.Page)).All(&reviews)
+	if err != nil {
+		log.Printf("ReviewRepository -> FindByEvent -> paginator.All: %s", err)
+		return nil, 0, err
+	}
+
+	total, err := paginator.TotalEntries()
+	if err != nil {
+		log.Printf("ReviewRepository -> FindByEvent -> paginator.TotalEntries: %s", err)
+		return nil, 0, err
+	}
+
+	result := make([]domain.Review, len(reviews))
+	for i, rv := range reviews {
+		result[i] = r.mapModelToDomain(rv)
+	}
+	return result, total, nil
+}
+
+func (r reviewRepository) Exists(eventId, userId uint64) (bool, error) {
+	return r.coll.Find(db.Cond{"event_id": eventId, "user_id": userId}).Exists()
+}
+
+// Update changes the rating and the text of the review and marks it as edited.
+func (r reviewRepository) Update(rv domain.Review) (domain.Review, error) {
+	err := r.coll.Find(db.Cond{"id": rv.Id}).Update(map[string]interface{}{
+		"rating":       rv.Rating,
+		"body":         rv.Body,
+		"updated_date": time.Now(),
+	})
+	if err != nil {
+		log.Printf("ReviewRepository -> Update -> r.coll.Update: %s", err)
+		return domain.Review{}, err
+	}
+	return r.Find(rv.Id)
+}
+
+// Reply sets the reply of the organizer unless the review has one already, which is
+// reported by false.
+func (r reviewRepository) Reply(id uint64, reply string) (bool, error) {
+	res, err := r.sess.SQL().
+		Update(ReviewsTableName).
+		Set("reply", reply).
+		Set("reply_date", time.Now()).
+		Where("id = ? AND reply IS NULL", id).
+		Exec()
+	if err != nil {
+		log.Printf("ReviewRepository -> Reply -> r.sess.SQL().Update: %s", err)
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+// FindOrganizerRating aggregates the reviews of all the events of the user.
+func (r reviewRepository) FindOrganizerRating(userId uint64) (domain.Rating, error) {
+	var row struct {
+		Average float64 `db:"average"`
+		Count   uint64  `db:"count"`
+	}
+	err := r.sess.SQL().
+		Select(db.Raw("COALESCE(AVG(r.rating), 0)::float8 AS average"), db.Raw("COUNT(r.id) AS count")).
+		From(ReviewsTableName+" AS r").
+		Join(EventTableName+" AS e").On("e.id = r.event_id").
+		Where("e.user_id = ? AND e.deleted_date IS NULL", userId).
+		One(&row)
+	if err != nil {
+		log.Printf("ReviewRepository -> FindOrganizerRating -> r.sess.SQL(): %s", err)
+		return domain.Rating{}, err
+	}
+	return domain.Rating{Average: row.Average, Count: row.Count}, nil
+}
+
+func (r reviewRepository) query() db.Selector {
+	return r.sess.SQL().
+		Select(
+			"r.*",
+			"u.first_name AS author_first_name",
+			"u.second_name AS author_second_name",
+			"u.image AS author_image",
+		).
+		From(ReviewsTableName + " AS r").
+		Join("users AS u").On("r.user_id = u.id")
+}
+
+func (r reviewRepository) mapDomainToModel(d domain.Review) review {
+	return review{
+		Id:          d.Id,
+		EventId:     d.EventId,
+		UserId:      d.UserId,
+		Rating:      d.Rating,
+		Body:        d.Body,
+		Reply:       d.Reply,
+		ReplyDate:   d.ReplyDate,
+		CreatedDate: d.CreatedDate,
+		UpdatedDate: d.UpdatedDate,
+	}
+}
+
+func (r reviewRepository) mapModelToDomain(m authoredReview) domain.Review {
+	return domain.Review{
+		Id:      m.Id,
+		EventId: m.EventId,
+		UserId:  m.UserId,
+		Rating:  m.Rating,
+		Body:    m.Body,
+		Author: domain.User{
+			Id:         m.UserId,
+			FirstName:  m.AuthorFirstName,
+			SecondName: m.AuthorSecondName,
+			Image:      m.AuthorImage,
+		},
+		Reply:       m.Reply,
+		ReplyDate:   m.ReplyDate,
+		CreatedDate: m.CreatedDate,
+		UpdatedDate: m.UpdatedDate,
+	}
+}
+
+// isUniqueViolation tells whether err is a unique constraint violation, whichever driver
+// reported it.
+func isUniqueViolation(err error) bool {
+	var sqlErr interface{ SQLState() string }
+	return errors.As(err, &sqlErr) && sqlErr.SQLState() == uniqueViolation
+}
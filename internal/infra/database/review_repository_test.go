@@ -0,0 +1,41 @@
+package database
+
+import (
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"sync"
+	"testing"
+)
+
+func TestReviewRepository_ConcurrentSave(t *testing.T) {
+	sess := testSession(t)
+	repo := NewReviewRepository(sess)
+
+	owner, reviewer := createTestUser(t, sess), createTestUser(t, sess)
+	evn := createTestEvent(t, sess, owner.Id, nil)
+
+	const calls = 4
+	errs := make([]error, calls)
+	var wg sync.WaitGroup
+	for i := 0; i < calls; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = repo.Save(domain.Review{EventId: evn.Id, UserId: reviewer.Id, Rating: 5, Body: "Great"})
+		}(i)
+	}
+	wg.Wait()
+
+	var saved int
+	for _, err := range errs {
+		switch err {
+		case nil:
+			saved++
+		case ErrReviewExists:
+		default:
+			t.Fatalf("Save: %s", err)
+		}
+	}
+	if saved != 1 {
+		t.Errorf("saved reviews = %d, want 1", saved)
+	}
+}
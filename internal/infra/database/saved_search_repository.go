@@ -0,0 +1,295 @@
+package database
+
+import (
+	"log"
+	"time"
+
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"github.com/upper/db/v4"
+)
+
+const (
+	SavedSearchesTableName      = "saved_searches"
+	SavedSearchMatchesTableName = "saved_search_matches"
+)
+
+type savedSearch struct {
+	Id             uint64                      `db:"id,omitempty"`
+	UserId         uint64                      `db:"user_id"`
+	Name           string                      `db:"name"`
+	Search         string                      `db:"search"`
+	City           string                      `db:"city"`
+	Lat            *float64                    `db:"lat"`
+	Lon            *float64                    `db:"lon"`
+	RadiusKm       *float64                    `db:"radius_km"`
+	CategoryId     *uint64                     `db:"category_id"`
+	Channel        domain.NotificationChannel  `db:"channel"`
+	Frequency      domain.SavedSearchFrequency `db:"frequency"`
+	LastDigestDate *time.Time                  `db:"last_digest_date"`
+	CreatedDate    time.Time                   `db:"created_date,omitempty"`
+	UpdatedDate    time.Time                   `db:"updated_date,omitempty"`
+}
+
+type SavedSearchRepository interface {
+	Save(s domain.SavedSearch) (domain.SavedSearch, error)
+	Update(s domain.SavedSearch) (domain.SavedSearch, error)
+	Find(id uint64) (domain.SavedSearch, error)
+	FindByUser(userId uint64) ([]domain.SavedSearch, error)
+	CountByUser(userId uint64) (uint64, error)
+	Delete(id uint64) error
+	FindMatching(eventId uint64) ([]domain.SavedSearch, error)
+	AddMatch(searchId, eventId uint64, notified bool) (bool, error)
+	FindDueDigests(before time.Time) ([]domain.SavedSearch, error)
+	FindPendingMatches(searchId uint64) ([]domain.SavedSearchMatch, error)
+	MarkDigested(searchId uint64, at time.Time) error
+}
+
+type savedSearchRepository struct {
+	coll db.Collection
+	sess db.Session
+}
+
+func NewSavedSearchRepository(dbSession db.Session) SavedSearchRepository {
+	return savedSearchRepository{
+		coll: dbSession.Collection(SavedSearchesTableName),
+		sess: dbSession,
+	}
+}
+
+func (r savedSearchRepository) Save(s domain.SavedSearch) (domain.SavedSearch, error) {
+	ss := r.mapDomainToModel(s)
+	ss.CreatedDate, ss.UpdatedDate = time.Now(), time.Now()
+	err := r.coll.InsertReturning(&ss)
+	if err != nil {
+		log.Printf("SavedSearchRepository -> Save -> r.coll.InsertReturning: %s", err)
+		return domain.SavedSearch{}, err
+	}
+	return r.mapModelToDomain(ss), nil
+}
+
+func (r savedSearchRepository) Update(s domain.SavedSearch) (domain.SavedSearch, error) {
+	ss := r.mapDomainToModel(s)
+	ss.UpdatedDate = time.Now()
+	err := r.coll.Find(db.Cond{"id": ss.Id}).Update(&ss)
+	if err != nil {
+		log.Printf("SavedSearchRepository -> Update -> r.coll.Find: %s", err)
+		return domain.SavedSearch{}, err
+	}
+	return r.mapModelToDomain(ss), nil
+}
+
+func (r savedSearchRepository) Find(id uint64) (domain.SavedSearch, error) {
+	var ss savedSearch
+	err := r.coll.Find(db.Cond{"id": id}).One(&ss)
+	if err != nil {
+		return domain.SavedSearch{}, err
+	}
+	return r.mapModelToDomain(ss), nil
+}
+
+func (r savedSearchRepository) FindByUser(userId uint64) ([]domain.SavedSearch, error) {
+	var searches []savedSearch
+	err := r.coll.Find(db.Cond{"user_id": userId}).OrderBy("id").All(&searches)
+	if err != nil {
+		return nil, err
+	}
+	return r.mapModelToDomainCollection(searches), nil
+}
+
+func (r savedSearchRepository) CountByUser(userId uint64) (uint64, error) {
+	return r.coll.Find(db.Cond{"user_id": userId}).Count()
+}
+
+func (r savedSearchRepository) Delete(id uint64) error {
+	return r.coll.Find(db.Cond{"id": id}).Delete()
+}
+
+// FindMatching returns the saved searches of other users which the event matches. The
+// criteria work as the same event list filters, see eventRepository.filterConditions.
+func (r savedSearchRepository) FindMatching(eventId uint64) ([]domain.SavedSearch, error) {
+	var searches []savedSearch
+	err := r.sess.SQL().
+		Select("s.*").
+		From(SavedSearchesTableName+" AS s").
+		Join(EventTableName+" AS e").On("e.id = ?", eventId).
+		Where(db.And(
+			db.Raw("s.user_id <> e.user_id"),
+			db.Raw(`(s.search = '' OR e.search_vector @@ (websearch_to_tsquery('english', s.search) || websearch_to_tsquery('simple', s.search)))`),
+			db.Raw(`(s.city = '' OR LOWER(e.city) LIKE '%' || LOWER(s.city) || '%')`),
+			db.Raw(`(s.lat IS NULL OR earth_distance(ll_to_earth(s.lat, s.lon), ll_to_earth(e.lat, e.lon)) <= s.radius_km * 1000)`),
+			// subcategories match the searches of their ancestors
+			db.Raw(
+				`(s.category_id IS NULL OR s.category_id IN (WITH RECURSIVE ancestors AS (
+					SELECT c.id, c.parent_id FROM `+CategoriesTableName+` c
+					WHERE c.id = (SELECT category_id FROM `+EventTableName+` WHERE id = ?)
+					UNION SELECT c.id, c.parent_id FROM `+CategoriesTableName+` c JOIN ancestors a ON c.id = a.parent_id
+				) SELECT id FROM ancestors))`,
+				eventId,
+			),
+		)).
+		OrderBy("s.id").
+		All(&searches)
+	if err != nil {
+		log.Printf("SavedSearchRepository -> FindMatching -> r.sess.SQL(): %s", err)
+		return nil, err
+	}
+	return r.mapModelToDomainCollection(searches), nil
+}
+
+// AddMatch records the event found by the search, notified matches are left out of the
+// digests. It reports false when the match had already been recorded.
+func (r savedSearchRepository) AddMatch(searchId, eventId uint64, notified bool) (bool, error) {
+	now := time.Now()
+	var notifiedDate *time.Time
+	if notified {
+		notifiedDate = &now
+	}
+
+	res, err := r.sess.SQL().Exec(
+		`INSERT INTO `+SavedSearchMatchesTableName+` (saved_search_id, event_id, created_date, notified_date)
+		VALUES (?, ?, ?, ?) ON CONFLICT (saved_search_id, event_id) DO NOTHING`,
+		searchId, eventId, now, notifiedDate,
+	)
+	if err != nil {
+		return false, err
+	}
+	added, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return added > 0, nil
+}
+
+// FindDueDigests returns the daily searches with pending matches which were last digested,
+// or created, before the given time.
+func (r savedSearchRepository) FindDueDigests(before time.Time) ([]domain.SavedSearch, error) {
+	pending := r.sess.SQL().
+		Select("saved_search_id").
+		From(SavedSearchMatchesTableName).
+		Where("notified_date IS NULL")
+
+	var searches []savedSearch
+	err := r.coll.Find(
+		db.Cond{"frequency": domain.DailySavedSearchFrequency},
+		db.Raw("COALESCE(last_digest_date, created_date) <= ?", before),
+		db.Raw("id IN ?", pending),
+	).OrderBy("id").All(&searches)
+	if err != nil {
+		log.Printf("SavedSearchRepository -> FindDueDigests -> r.coll.Find: %s", err)
+		return nil, err
+	}
+	return r.mapModelToDomainCollection(searches), nil
+}
+
+// FindPendingMatches returns the matches waiting for the digest, leaving out the events
+// which have been deleted or cancelled since.
+func (r savedSearchRepository) FindPendingMatches(searchId uint64) ([]domain.SavedSearchMatch, error) {
+	var rows []struct {
+		SavedSearchId uint64    `db:"saved_search_id"`
+		EventId       uint64    `db:"event_id"`
+		Title         string    `db:"title"`
+		Date          time.Time `db:"date"`
+		CreatedDate   time.Time `db:"created_date"`
+	}
+	err := r.sess.SQL().
+		Select("m.saved_search_id", "m.event_id", "e.title", "e.date", "m.created_date").
+		From(SavedSearchMatchesTableName+" AS m").
+		Join(EventTableName+" AS e").On("e.id = m.event_id").
+		Where(db.Cond{
+			"m.saved_search_id": searchId,
+			"m.notified_date":   nil,
+			"e.deleted_date":    nil,
+			"e.status <>":       domain.CancelledEventStatus,
+			"e.date >=":         time.Now(),
+		}).
+		OrderBy("e.date", "e.id").
+		All(&rows)
+	if err != nil {
+		log.Printf("SavedSearchRepository -> FindPendingMatches -> r.sess.SQL(): %s", err)
+		return nil, err
+	}
+
+	matches := make([]domain.SavedSearchMatch, len(rows))
+	for i, row := range rows {
+		matches[i] = domain.SavedSearchMatch{
+			SavedSearchId: row.SavedSearchId,
+			EventId:       row.EventId,
+			EventTitle:    row.Title,
+			EventDate:     row.Date,
+			CreatedDate:   row.CreatedDate,
+		}
+	}
+	return matches, nil
+}
+
+// MarkDigested closes the pending matches of the search recorded up to the given time.
+func (r savedSearchRepository) MarkDigested(searchId uint64, at time.Time) error {
+	return r.sess.Tx(func(tx db.Session) error {
+		_, err := tx.SQL().
+			Update(SavedSearchMatchesTableName).
+			Set("notified_date", at).
+			Where("saved_search_id = ? AND notified_date IS NULL AND created_date <= ?", searchId, at).
+			Exec()
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.SQL().
+			Update(SavedSearchesTableName).
+			Set("last_digest_date", at).
+			Where("id = ?", searchId).
+			Exec()
+		return err
+	})
+}
+
+func (r savedSearchRepository) mapDomainToModel(d domain.SavedSearch) savedSearch {
+	ss := savedSearch{
+		Id:             d.Id,
+		UserId:         d.UserId,
+		Name:           d.Name,
+		Search:         d.Search,
+		City:           d.City,
+		CategoryId:     d.CategoryId,
+		Channel:        d.Channel,
+		Frequency:      d.Frequency,
+		LastDigestDate: d.LastDigestDate,
+		CreatedDate:    d.CreatedDate,
+		UpdatedDate:    d.UpdatedDate,
+	}
+	if d.Point != nil {
+		ss.Lat, ss.Lon, ss.RadiusKm = &d.Point.Lat, &d.Point.Lon, &d.RadiusKm
+	}
+	return ss
+}
+
+func (r savedSearchRepository) mapModelToDomain(m savedSearch) domain.SavedSearch {
+	s := domain.SavedSearch{
+		Id:             m.Id,
+		UserId:         m.UserId,
+		Name:           m.Name,
+		Search:         m.Search,
+		City:           m.City,
+		CategoryId:     m.CategoryId,
+		Channel:        m.Channel,
+		Frequency:      m.Frequency,
+		LastDigestDate: m.LastDigestDate,
+		CreatedDate:    m.CreatedDate,
+		UpdatedDate:    m.UpdatedDate,
+	}
+	if m.Lat != nil && m.Lon != nil {
+		s.Point = &domain.GeoPoint{Lat: *m.Lat, Lon: *m.Lon}
+		if m.RadiusKm != nil {
+			s.RadiusKm = *m.RadiusKm
+		}
+	}
+	return s
+}
+
+func (r savedSearchRepository) mapModelToDomainCollection(searches []savedSearch) []domain.SavedSearch {
+	result := make([]domain.SavedSearch, len(searches))
+	for i, s := range searches {
+		result[i] = r.mapModelToDomain(s)
+	}
+	return result
+}
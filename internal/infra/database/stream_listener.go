@@ -0,0 +1,74 @@
+package database
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/BohdanBoriak/boilerplate-go-back/config"
+	"github.com/lib/pq"
+)
+
+const (
+	streamChannel = "stream_events"
+	// streamPingInterval checks the connection when nothing has been heard for a while
+	streamPingInterval = 90 * time.Second
+)
+
+// StreamListener listens to the notifications of the stream_events trigger, so the events
+// saved by any server instance reach the streams open on this one.
+type StreamListener struct {
+	url string
+}
+
+func NewStreamListener(conf config.Configuration) StreamListener {
+	return StreamListener{url: ConnectionUrl(conf)}
+}
+
+// Listen sends the id of the user whenever there are new events for them. After the
+// connection has been lost, 0 is sent, as the notifications in between are gone.
+// The channel is closed when the context is done.
+func (l StreamListener) Listen(ctx context.Context) (<-chan uint64, error) {
+	listener := pq.NewListener(l.url, time.Second, time.Minute, func(ev pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Printf("StreamListener: %s", err)
+		}
+	})
+	err := listener.Listen(streamChannel)
+	if err != nil {
+		_ = listener.Close()
+		return nil, err
+	}
+
+	users := make(chan uint64)
+	go func() {
+		defer close(users)
+		defer listener.Close()
+
+		for {
+			var userId uint64
+			select {
+			case <-ctx.Done():
+				return
+			case n := <-listener.Notify:
+				if n != nil {
+					userId, err = strconv.ParseUint(n.Extra, 10, 64)
+					if err != nil {
+						continue
+					}
+				}
+			case <-time.After(streamPingInterval):
+				go func() { _ = listener.Ping() }()
+				continue
+			}
+
+			select {
+			case users <- userId:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return users, nil
+}
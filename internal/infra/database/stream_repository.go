@@ -0,0 +1,109 @@
+package database
+
+import (
+	"log"
+	"time"
+
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"github.com/upper/db/v4"
+)
+
+const StreamEventsTableName = "stream_events"
+
+type streamEvent struct {
+	Id          uint64                 `db:"id,omitempty"`
+	UserId      uint64                 `db:"user_id"`
+	Type        domain.StreamEventType `db:"type"`
+	Data        string                 `db:"data"`
+	CreatedDate time.Time              `db:"created_date"`
+}
+
+type StreamRepository interface {
+	SaveMany(userIds []uint64, t domain.StreamEventType, data string) error
+	FindAfter(userId, afterId uint64, limit uint) ([]domain.StreamEvent, error)
+	FindLastId(userId uint64) (uint64, error)
+	DeleteBefore(date time.Time) error
+}
+
+type streamRepository struct {
+	coll db.Collection
+	sess db.Session
+}
+
+func NewStreamRepository(dbSession db.Session) StreamRepository {
+	return streamRepository{
+		coll: dbSession.Collection(StreamEventsTableName),
+		sess: dbSession,
+	}
+}
+
+// SaveMany saves a copy of the event for each of the users with a single statement, the
+// streams are woken by the trigger of the table.
+func (r streamRepository) SaveMany(userIds []uint64, t domain.StreamEventType, data string) error {
+	if len(userIds) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	inserter := r.sess.SQL().
+		InsertInto(StreamEventsTableName).
+		Columns("user_id", "type", "data", "created_date")
+	for _, userId := range userIds {
+		inserter = inserter.Values(userId, t, data, now)
+	}
+
+	_, err := inserter.Exec()
+	if err != nil {
+		log.Printf("StreamRepository -> SaveMany -> inserter.Exec: %s", err)
+		return err
+	}
+	return nil
+}
+
+// FindAfter returns the user's events following the given one, the oldest first.
+func (r streamRepository) FindAfter(userId, afterId uint64, limit uint) ([]domain.StreamEvent, error) {
+	var events []streamEvent
+	err := r.coll.
+		Find(db.Cond{"user_id": userId, "id >": afterId}).
+		OrderBy("id").
+		Limit(int(limit)).
+		All(&events)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]domain.StreamEvent, len(events))
+	for i, e := range events {
+		result[i] = domain.StreamEvent{
+			Id:          e.Id,
+			UserId:      e.UserId,
+			Type:        e.Type,
+			Data:        e.Data,
+			CreatedDate: e.CreatedDate,
+		}
+	}
+	return result, nil
+}
+
+// FindLastId returns the id of the user's latest event, 0 when there is none.
+func (r streamRepository) FindLastId(userId uint64) (uint64, error) {
+	var row struct {
+		Id *uint64 `db:"id"`
+	}
+	err := r.sess.SQL().
+		Select(db.Raw("MAX(id) AS id")).
+		From(StreamEventsTableName).
+		Where("user_id = ?", userId).
+		One(&row)
+	if err != nil {
+		return 0, err
+	}
+	if row.Id == nil {
+		return 0, nil
+	}
+	return *row.Id, nil
+}
+
+func (r streamRepository) DeleteBefore(date time.Time) error {
+	return r.coll.Find(db.Cond{"created_date <": date}).Delete()
+}
@@ -1,79 +1,470 @@
 package database
 
 import (
+	"errors"
 	"fmt"
 	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
 	"github.com/upper/db/v4"
 	"log"
+	"time"
 )
 
+const (
+	SubscriptionsTableName = "subscriptions"
+	WaitlistTableName      = "waitlist"
+)
+
+type subscription struct {
+	EventId     uint64            `db:"event_id"`
+	UserId      uint64            `db:"user_id"`
+	Rsvp        domain.RsvpStatus `db:"rsvp"`
+	CreatedDate time.Time         `db:"created_date"`
+}
+
+type waitlistEntry struct {
+	Id          uint64            `db:"id,omitempty"`
+	EventId     uint64            `db:"event_id"`
+	UserId      uint64            `db:"user_id"`
+	Rsvp        domain.RsvpStatus `db:"rsvp"`
+	CreatedDate time.Time         `db:"created_date"`
+}
+
+type attendee struct {
+	user           `db:",inline"`
+	Rsvp           domain.RsvpStatus `db:"rsvp"`
+	SubscribedDate time.Time         `db:"subscribed_date"`
+}
+
 type subscriptionRepository struct {
 	db db.Session
 }
 
 type SubscriptionRepository interface {
-	Subscribe(eventId, userId uint64) error
-	FindUserSubscriptions(userId uint64) ([]domain.Event, error)
+	Subscribe(eventId, userId uint64, rsvp domain.RsvpStatus) (domain.Subscription, []domain.Subscription, error)
+	Unsubscribe(eventId, userId uint64) ([]domain.Subscription, error)
+	FillFromWaitlist(eventId uint64) ([]domain.Subscription, error)
+	FindUserCalendar(userId uint64) ([]domain.Event, error)
+	FindAttendees(eventId uint64, p domain.Pagination) ([]domain.Attendee, uint64, error)
+	CountAttendees(eventId uint64) (domain.AttendeeCounts, error)
+	FindSubscriberIds(eventId uint64) ([]uint64, error)
+	IsSubscribed(eventId, userId uint64) (bool, error)
+	HoldsPlace(eventId, userId uint64) (bool, error)
+	IsAttendee(eventId, userId uint64) (bool, error)
 }
 
 func NewSubscriptionRepository(db db.Session) SubscriptionRepository {
 	return subscriptionRepository{db: db}
 }
 
-func (r subscriptionRepository) Subscribe(eventId, userId uint64) error {
-	var event map[string]interface{}
-	err := r.db.Collection("events").
-		Find(db.Cond{"id": eventId, "deleted_date": nil}).One(&event)
+// Subscribe creates or updates the user's RSVP for the event. Users answering "going" or
+// "maybe" take a place; once the event is full they are put on the end of its waitlist,
+// keeping their answer.
+// Repeating the same call is a no-op. Declining frees the place, and the users promoted
+// from the waitlist to fill it are returned as well.
+// The event row is locked for the duration of the transaction, so concurrent subscribers
+// are serialized and the capacity can never be exceeded.
+func (r subscriptionRepository) Subscribe(eventId, userId uint64, rsvp domain.RsvpStatus) (domain.Subscription, []domain.Subscription, error) {
+	var (
+		sub      domain.Subscription
+		promoted []domain.Subscription
+	)
+	err := r.db.Tx(func(tx db.Session) error {
+		evn, err := r.lockEvent(tx, eventId)
+		if err != nil {
+			return err
+		}
+
+		var current subscription
+		res := tx.Collection(SubscriptionsTableName).Find(db.Cond{"event_id": eventId, "user_id": userId})
+		err = res.One(&current)
+		if err != nil && !errors.Is(err, db.ErrNoMoreRows) {
+			return err
+		}
+		subscribed := err == nil
+
+		if subscribed && current.Rsvp == rsvp {
+			sub = r.mapModelToSubscription(current)
+			return nil
+		}
+
+		if subscribed && current.Rsvp != domain.DeclinedRsvpStatus && rsvp != domain.DeclinedRsvpStatus {
+			// going <-> maybe, the place is kept
+			current.Rsvp = rsvp
+			sub = r.mapModelToSubscription(current)
+			return res.Update(map[string]interface{}{"rsvp": rsvp})
+		}
+
+		if rsvp == domain.DeclinedRsvpStatus {
+			err = tx.Collection(WaitlistTableName).Find(db.Cond{"event_id": eventId, "user_id": userId}).Delete()
+			if err != nil {
+				return err
+			}
+
+			current = subscription{EventId: eventId, UserId: userId, Rsvp: rsvp, CreatedDate: time.Now()}
+			if subscribed {
+				err = res.Update(map[string]interface{}{"rsvp": rsvp})
+			} else {
+				_, err = tx.Collection(SubscriptionsTableName).Insert(current)
+			}
+			if err != nil {
+				return err
+			}
+			sub = r.mapModelToSubscription(current)
 
-	if err != nil || event == nil {
-		log.Printf("SubscribeRepository -> Subscribe -> r.db.Collection(\"events\"): %s", err)
-		return fmt.Errorf("event not found or is deleted")
+			promoted, err = r.promote(tx, evn)
+			return err
+		}
+
+		// a new or previously declined subscriber asks for a place
+		if subscribed {
+			err = res.Delete()
+			if err != nil {
+				return err
+			}
+		}
+
+		var entry waitlistEntry
+		wl := tx.Collection(WaitlistTableName).Find(db.Cond{"event_id": eventId, "user_id": userId})
+		err = wl.One(&entry)
+		if err == nil {
+			// going <-> maybe, the place in the waitlist is kept
+			if entry.Rsvp != rsvp {
+				entry.Rsvp = rsvp
+				err = wl.Update(map[string]interface{}{"rsvp": rsvp})
+				if err != nil {
+					return err
+				}
+			}
+			sub, err = r.waitlisted(tx, entry)
+			return err
+		} else if !errors.Is(err, db.ErrNoMoreRows) {
+			return err
+		}
+
+		taken, err := r.countTaken(tx, eventId)
+		if err != nil {
+			return err
+		}
+
+		if evn.Capacity == nil || taken < *evn.Capacity {
+			current = subscription{EventId: eventId, UserId: userId, Rsvp: rsvp, CreatedDate: time.Now()}
+			_, err = tx.Collection(SubscriptionsTableName).Insert(current)
+			sub = r.mapModelToSubscription(current)
+			return err
+		}
+
+		entry = waitlistEntry{EventId: eventId, UserId: userId, Rsvp: rsvp, CreatedDate: time.Now()}
+		err = tx.Collection(WaitlistTableName).InsertReturning(&entry)
+		if err != nil {
+			return err
+		}
+		sub, err = r.waitlisted(tx, entry)
+		return err
+	})
+	if err != nil {
+		log.Printf("SubscriptionRepository -> Subscribe -> r.db.Tx: %s", err)
+		return domain.Subscription{}, nil, err
 	}
 
-	_, er := r.db.Collection("subscriptions").Insert(map[string]interface{}{
-		"event_id": eventId,
-		"user_id":  userId,
+	return sub, promoted, nil
+}
+
+// Unsubscribe removes the user from the event or from its waitlist. Freed places are
+// handed to the first users on the waitlist within the same transaction; the promoted
+// subscriptions are returned.
+func (r subscriptionRepository) Unsubscribe(eventId, userId uint64) ([]domain.Subscription, error) {
+	var promoted []domain.Subscription
+	err := r.db.Tx(func(tx db.Session) error {
+		evn, err := r.lockEvent(tx, eventId)
+		if err != nil {
+			return err
+		}
+
+		res := tx.Collection(SubscriptionsTableName).Find(db.Cond{"event_id": eventId, "user_id": userId})
+		exists, err := res.Exists()
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return tx.Collection(WaitlistTableName).Find(db.Cond{"event_id": eventId, "user_id": userId}).Delete()
+		}
+
+		err = res.Delete()
+		if err != nil {
+			return err
+		}
+
+		promoted, err = r.promote(tx, evn)
+		return err
 	})
-	return er
+	if err != nil {
+		log.Printf("SubscriptionRepository -> Unsubscribe -> r.db.Tx: %s", err)
+		return nil, err
+	}
+
+	return promoted, nil
 }
 
-func (r subscriptionRepository) FindUserSubscriptions(userId uint64) ([]domain.Event, error) {
-	var dbEvents []event
+// FillFromWaitlist promotes waitlisted users while the event has free places,
+// e.g. after its capacity was raised.
+func (r subscriptionRepository) FillFromWaitlist(eventId uint64) ([]domain.Subscription, error) {
+	var promoted []domain.Subscription
+	err := r.db.Tx(func(tx db.Session) error {
+		evn, err := r.lockEvent(tx, eventId)
+		if err != nil {
+			return err
+		}
+
+		promoted, err = r.promote(tx, evn)
+		return err
+	})
+	if err != nil {
+		log.Printf("SubscriptionRepository -> FillFromWaitlist -> r.db.Tx: %s", err)
+		return nil, err
+	}
+
+	return promoted, nil
+}
 
-	// Выполняем запрос
+// FindUserCalendar returns the events the user is going to, including deleted ones,
+// so that calendar feeds can mark them as cancelled.
+func (r subscriptionRepository) FindUserCalendar(userId uint64) ([]domain.Event, error) {
+	var dbEvents []event
 	err := r.db.SQL().
-		Select("e.id", "e.user_id", "e.title", "e.description", "e.status", "e.image", "e.location", "e.date", "e.lat", "e.lon").
+		Select("e.*").
 		From("subscriptions AS s").
 		Join("events AS e").On("s.event_id = e.id").
-		Where("s.user_id = ? AND e.deleted_date IS NULL", userId).
+		Where("s.user_id = ? AND s.rsvp <> ?", userId, domain.DeclinedRsvpStatus).
+		OrderBy("e.date").
 		All(&dbEvents)
+	if err != nil {
+		log.Printf("SubscriptionRepository -> FindUserCalendar -> r.db.SQL(): %s", err)
+		return nil, err
+	}
+
+	var eventRepo eventRepository
+	return eventRepo.mapModelToDomainCollection(dbEvents), nil
+}
 
+// FindSubscriberIds returns the users who have not declined the event.
+func (r subscriptionRepository) FindSubscriberIds(eventId uint64) ([]uint64, error) {
+	var rows []struct {
+		UserId uint64 `db:"user_id"`
+	}
+	err := r.db.SQL().
+		Select("user_id").
+		From(SubscriptionsTableName).
+		Where("event_id = ? AND rsvp <> ?", eventId, domain.DeclinedRsvpStatus).
+		OrderBy("user_id").
+		All(&rows)
 	if err != nil {
+		log.Printf("SubscriptionRepository -> FindSubscriberIds -> r.db.SQL(): %s", err)
 		return nil, err
 	}
 
-	return r.mapModelToDomainCollection(dbEvents), nil
+	ids := make([]uint64, len(rows))
+	for i, row := range rows {
+		ids[i] = row.UserId
+	}
+	return ids, nil
 }
-func (r subscriptionRepository) mapModelToDomain(m event) domain.Event {
-	return domain.Event{
-		Id:          m.Id,
-		UserId:      m.UserId,
-		Title:       m.Title,
-		Description: m.Description,
-		Status:      m.Status,
-		Image:       m.Image,
-		Location:    m.Location,
-		Lat:         m.Lat,
-		Lon:         m.Lon,
-		Date:        m.Date,
+
+// IsSubscribed tells whether the user has answered the event or is on its waitlist.
+func (r subscriptionRepository) IsSubscribed(eventId, userId uint64) (bool, error) {
+	cond := db.Cond{"event_id": eventId, "user_id": userId}
+	subscribed, err := r.db.Collection(SubscriptionsTableName).Find(cond).Exists()
+	if err != nil || subscribed {
+		return subscribed, err
+	}
+	return r.db.Collection(WaitlistTableName).Find(cond).Exists()
+}
+
+// HoldsPlace tells whether the user has a place at the event, going or maybe.
+func (r subscriptionRepository) HoldsPlace(eventId, userId uint64) (bool, error) {
+	return r.db.Collection(SubscriptionsTableName).
+		Find(db.Cond{"event_id": eventId, "user_id": userId, "rsvp <>": domain.DeclinedRsvpStatus}).
+		Exists()
+}
+
+// IsAttendee tells whether the user went to the event. The user has to be going, and once
+// the tickets of the event have been checked in at the door, has to have checked in too.
+func (r subscriptionRepository) IsAttendee(eventId, userId uint64) (bool, error) {
+	var s subscription
+	err := r.db.SQL().
+		Select("s.*").
+		From("subscriptions AS s").
+		Where("s.event_id = ? AND s.user_id = ? AND s.rsvp = ?", eventId, userId, domain.GoingRsvpStatus).
+		And(`(NOT EXISTS (SELECT 1 FROM tickets AS t WHERE t.event_id = s.event_id AND t.checked_in_date IS NOT NULL)
+			OR EXISTS (SELECT 1 FROM tickets AS t WHERE t.event_id = s.event_id AND t.user_id = s.user_id AND t.checked_in_date IS NOT NULL))`).
+		One(&s)
+	if errors.Is(err, db.ErrNoMoreRows) {
+		return false, nil
+	} else if err != nil {
+		log.Printf("SubscriptionRepository -> IsAttendee -> r.db.SQL: %s", err)
+		return false, err
 	}
+	return true, nil
 }
-func (r subscriptionRepository) mapModelToDomainCollection(evn []event) []domain.Event {
 
-	var events []domain.Event
-	for _, ev := range evn {
+func (r subscriptionRepository) FindAttendees(eventId uint64, p domain.Pagination) ([]domain.Attendee, uint64, error) {
+	var attendees []attendee
+	paginator := r.db.SQL().
+		Select("u.*", "s.rsvp", "s.created_date AS subscribed_date").
+		From("subscriptions AS s").
+		Join("users AS u").On("s.user_id = u.id").
+		Where("s.event_id = ? AND u.deleted_date IS NULL", eventId).
+		OrderBy("s.created_date", "u.id").
+		Paginate(uint(p.CountPerPage))
+
+	err := paginator.Page(uint(p.Page)).All(&attendees)
+	if err != nil {
+		log.Printf("SubscriptionRepository -> FindAttendees -> paginator.All: %s", err)
+		return nil, 0, err
+	}
+
+	total, err := paginator.TotalEntries()
+	if err != nil {
+		log.Printf("SubscriptionRepository -> FindAttendees -> paginator.TotalEntries: %s", err)
+		return nil, 0, err
+	}
 
-		events = append(events, r.mapModelToDomain(ev))
+	var userRepo userRepository
+	result := make([]domain.Attendee, len(attendees))
+	for i, a := range attendees {
+		result[i] = domain.Attendee{
+			User:           userRepo.mapModelToDomain(a.user),
+			Rsvp:           a.Rsvp,
+			SubscribedDate: a.SubscribedDate,
+		}
+	}
+
+	return result, total, nil
+}
+
+func (r subscriptionRepository) CountAttendees(eventId uint64) (domain.AttendeeCounts, error) {
+	var rows []struct {
+		Rsvp  domain.RsvpStatus `db:"rsvp"`
+		Count uint64            `db:"count"`
+	}
+	err := r.db.SQL().
+		Select("rsvp", db.Raw("COUNT(*) AS count")).
+		From(SubscriptionsTableName).
+		Where("event_id = ?", eventId).
+		GroupBy("rsvp").
+		All(&rows)
+	if err != nil {
+		log.Printf("SubscriptionRepository -> CountAttendees -> r.db.SQL(): %s", err)
+		return domain.AttendeeCounts{}, err
+	}
+
+	var counts domain.AttendeeCounts
+	for _, row := range rows {
+		switch row.Rsvp {
+		case domain.GoingRsvpStatus:
+			counts.Going = row.Count
+		case domain.MaybeRsvpStatus:
+			counts.Maybe = row.Count
+		case domain.DeclinedRsvpStatus:
+			counts.Declined = row.Count
+		}
+	}
+
+	counts.Waitlisted, err = r.db.Collection(WaitlistTableName).Find(db.Cond{"event_id": eventId}).Count()
+	if err != nil {
+		log.Printf("SubscriptionRepository -> CountAttendees -> r.db.Collection(WaitlistTableName): %s", err)
+		return domain.AttendeeCounts{}, err
+	}
+
+	return counts, nil
+}
+
+func (r subscriptionRepository) lockEvent(tx db.Session, eventId uint64) (event, error) {
+	var evn event
+	err := tx.SQL().
+		Iterator("SELECT * FROM events WHERE id = ? AND deleted_date IS NULL FOR UPDATE", eventId).
+		One(&evn)
+	if err != nil {
+		if errors.Is(err, db.ErrNoMoreRows) {
+			return event{}, fmt.Errorf("event not found or is deleted")
+		}
+		return event{}, err
+	}
+
+	return evn, nil
+}
+
+// countTaken counts the places taken, declined subscriptions don't hold one.
+func (r subscriptionRepository) countTaken(tx db.Session, eventId uint64) (uint64, error) {
+	return tx.Collection(SubscriptionsTableName).
+		Find(db.Cond{"event_id": eventId, "rsvp <>": domain.DeclinedRsvpStatus}).
+		Count()
+}
+
+func (r subscriptionRepository) waitlisted(tx db.Session, entry waitlistEntry) (domain.Subscription, error) {
+	position, err := tx.Collection(WaitlistTableName).Find(db.Cond{"event_id": entry.EventId, "id <=": entry.Id}).Count()
+	if err != nil {
+		return domain.Subscription{}, err
+	}
+
+	return domain.Subscription{
+		EventId:     entry.EventId,
+		UserId:      entry.UserId,
+		Status:      domain.WaitlistedSubscriptionStatus,
+		Rsvp:        entry.Rsvp,
+		Position:    position,
+		CreatedDate: entry.CreatedDate,
+	}, nil
+}
+
+// promote must be called with the event row locked.
+func (r subscriptionRepository) promote(tx db.Session, evn event) ([]domain.Subscription, error) {
+	var promoted []domain.Subscription
+	for {
+		if evn.Capacity != nil {
+			taken, err := r.countTaken(tx, evn.Id)
+			if err != nil {
+				return nil, err
+			}
+			if taken >= *evn.Capacity {
+				return promoted, nil
+			}
+		}
+
+		var entry waitlistEntry
+		err := tx.Collection(WaitlistTableName).Find(db.Cond{"event_id": evn.Id}).OrderBy("id").One(&entry)
+		if err != nil {
+			if errors.Is(err, db.ErrNoMoreRows) {
+				return promoted, nil
+			}
+			return nil, err
+		}
+
+		err = tx.Collection(WaitlistTableName).Find(db.Cond{"id": entry.Id}).Delete()
+		if err != nil {
+			return nil, err
+		}
+
+		s := subscription{
+			EventId:     entry.EventId,
+			UserId:      entry.UserId,
+			Rsvp:        entry.Rsvp,
+			CreatedDate: time.Now(),
+		}
+		_, err = tx.Collection(SubscriptionsTableName).Insert(s)
+		if err != nil {
+			return nil, err
+		}
+
+		promoted = append(promoted, r.mapModelToSubscription(s))
+	}
+}
+
+func (r subscriptionRepository) mapModelToSubscription(m subscription) domain.Subscription {
+	return domain.Subscription{
+		EventId:     m.EventId,
+		UserId:      m.UserId,
+		Status:      domain.ActiveSubscriptionStatus,
+		Rsvp:        m.Rsvp,
+		CreatedDate: m.CreatedDate,
 	}
-	return events
 }
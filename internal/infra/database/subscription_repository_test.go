@@ -0,0 +1,241 @@
+package database
+
+import (
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"github.com/google/uuid"
+	"github.com/upper/db/v4"
+	"sort"
+	"sync"
+	"testing"
+)
+
+func TestSubscriptionRepository_ConcurrentSubscribe(t *testing.T) {
+	sess := testSession(t)
+	repo := NewSubscriptionRepository(sess)
+
+	const (
+		capacity    = 3
+		subscribers = 12
+	)
+	cpt := uint64(capacity)
+	owner := createTestUser(t, sess)
+	evn := createTestEvent(t, sess, owner.Id, &cpt)
+
+	users := make([]domain.User, subscribers)
+	for i := range users {
+		users[i] = createTestUser(t, sess)
+	}
+
+	subs := make([]domain.Subscription, subscribers)
+	errs := make([]error, subscribers)
+	var wg sync.WaitGroup
+	for i, u := range users {
+		wg.Add(1)
+		go func(i int, userId uint64) {
+			defer wg.Done()
+			subs[i], _, errs[i] = repo.Subscribe(evn.Id, userId, domain.GoingRsvpStatus)
+		}(i, u.Id)
+	}
+	wg.Wait()
+
+	var active int
+	var positions []uint64
+	for i, err := range errs {
+		if err != nil {
+			t.Fatalf("Subscribe: %s", err)
+		}
+		switch subs[i].Status {
+		case domain.ActiveSubscriptionStatus:
+			active++
+		case domain.WaitlistedSubscriptionStatus:
+			positions = append(positions, subs[i].Position)
+		}
+	}
+	if active != capacity {
+		t.Errorf("active subscriptions = %d, want %d", active, capacity)
+	}
+	assertContiguous(t, positions, subscribers-capacity)
+	assertTaken(t, sess, evn.Id, capacity)
+	assertWaitlist(t, sess, repo, evn.Id, subscribers-capacity)
+}
+
+func TestSubscriptionRepository_UnsubscribePromotes(t *testing.T) {
+	sess := testSession(t)
+	repo := NewSubscriptionRepository(sess)
+
+	cpt := uint64(2)
+	owner := createTestUser(t, sess)
+	evn := createTestEvent(t, sess, owner.Id, &cpt)
+
+	users := make([]domain.User, 6)
+	for i := range users {
+		users[i] = createTestUser(t, sess)
+		_, _, err := repo.Subscribe(evn.Id, users[i].Id, domain.GoingRsvpStatus)
+		if err != nil {
+			t.Fatalf("Subscribe: %s", err)
+		}
+	}
+
+	// the first on the waitlist takes the freed place
+	promoted, err := repo.Unsubscribe(evn.Id, users[0].Id)
+	if err != nil {
+		t.Fatalf("Unsubscribe: %s", err)
+	}
+	if len(promoted) != 1 || promoted[0].UserId != users[2].Id {
+		t.Fatalf("promoted = %+v, want user %d", promoted, users[2].Id)
+	}
+	assertTaken(t, sess, evn.Id, 2)
+	assertWaitlist(t, sess, repo, evn.Id, 3)
+
+	// the attendees and the waitlisted users leave at the same time
+	var wg sync.WaitGroup
+	for _, u := range []domain.User{users[1], users[2], users[4]} {
+		wg.Add(1)
+		go func(userId uint64) {
+			defer wg.Done()
+			_, err := repo.Unsubscribe(evn.Id, userId)
+			if err != nil {
+				t.Errorf("Unsubscribe: %s", err)
+			}
+		}(u.Id)
+	}
+	wg.Wait()
+
+	assertTaken(t, sess, evn.Id, 2)
+	assertWaitlist(t, sess, repo, evn.Id, 0)
+}
+
+// assertTaken checks the places taken at the event, declined subscriptions don't hold one.
+func assertTaken(t *testing.T, sess db.Session, eventId uint64, want uint64) {
+	t.Helper()
+
+	taken, err := subscriptionRepository{db: sess}.countTaken(sess, eventId)
+	if err != nil {
+		t.Fatalf("countTaken: %s", err)
+	}
+	if taken != want {
+		t.Errorf("places taken = %d, want %d", taken, want)
+	}
+}
+
+// assertWaitlist checks the waitlist positions, asking every waitlisted user for theirs.
+func assertWaitlist(t *testing.T, sess db.Session, repo SubscriptionRepository, eventId uint64, want int) {
+	t.Helper()
+
+	var entries []waitlistEntry
+	err := sess.Collection(WaitlistTableName).Find(db.Cond{"event_id": eventId}).All(&entries)
+	if err != nil {
+		t.Fatalf("waitlist: %s", err)
+	}
+
+	positions := make([]uint64, len(entries))
+	for i, e := range entries {
+		sub, _, err := repo.Subscribe(eventId, e.UserId, e.Rsvp)
+		if err != nil {
+			t.Fatalf("Subscribe: %s", err)
+		}
+		if sub.Status != domain.WaitlistedSubscriptionStatus {
+			t.Fatalf("user %d status = %s, want %s", e.UserId, sub.Status, domain.WaitlistedSubscriptionStatus)
+		}
+		positions[i] = sub.Position
+	}
+	assertContiguous(t, positions, want)
+}
+
+func assertContiguous(t *testing.T, positions []uint64, want int) {
+	t.Helper()
+
+	if len(positions) != want {
+		t.Fatalf("waitlisted = %d, want %d", len(positions), want)
+	}
+	sort.Slice(positions, func(i, j int) bool { return positions[i] < positions[j] })
+	for i, p := range positions {
+		if p != uint64(i+1) {
+			t.Fatalf("waitlist positions = %v, want 1..%d", positions, want)
+		}
+	}
+}
+
+func TestSubscriptionRepository_WaitlistKeepsRsvp(t *testing.T) {
+	sess := testSession(t)
+	repo := NewSubscriptionRepository(sess)
+
+	cpt := uint64(1)
+	owner := createTestUser(t, sess)
+	evn := createTestEvent(t, sess, owner.Id, &cpt)
+	attendee, waiting := createTestUser(t, sess), createTestUser(t, sess)
+
+	_, _, err := repo.Subscribe(evn.Id, attendee.Id, domain.GoingRsvpStatus)
+	if err != nil {
+		t.Fatalf("Subscribe: %s", err)
+	}
+	sub, _, err := repo.Subscribe(evn.Id, waiting.Id, domain.MaybeRsvpStatus)
+	if err != nil {
+		t.Fatalf("Subscribe: %s", err)
+	}
+	if sub.Status != domain.WaitlistedSubscriptionStatus || sub.Rsvp != domain.MaybeRsvpStatus {
+		t.Fatalf("subscription = %+v, want waitlisted with %s", sub, domain.MaybeRsvpStatus)
+	}
+
+	promoted, err := repo.Unsubscribe(evn.Id, attendee.Id)
+	if err != nil {
+		t.Fatalf("Unsubscribe: %s", err)
+	}
+	if len(promoted) != 1 || promoted[0].Rsvp != domain.MaybeRsvpStatus {
+		t.Fatalf("promoted = %+v, want %s", promoted, domain.MaybeRsvpStatus)
+	}
+}
+
+func TestSubscriptionRepository_IsAttendee(t *testing.T) {
+	sess := testSession(t)
+	repo := NewSubscriptionRepository(sess)
+	tickets := NewTicketRepository(sess)
+
+	owner := createTestUser(t, sess)
+	evn := createTestEvent(t, sess, owner.Id, nil)
+	going, maybe, absent := createTestUser(t, sess), createTestUser(t, sess), createTestUser(t, sess)
+	for _, s := range []struct {
+		user domain.User
+		rsvp domain.RsvpStatus
+	}{{going, domain.GoingRsvpStatus}, {maybe, domain.MaybeRsvpStatus}, {absent, domain.GoingRsvpStatus}} {
+		_, _, err := repo.Subscribe(evn.Id, s.user.Id, s.rsvp)
+		if err != nil {
+			t.Fatalf("Subscribe: %s", err)
+		}
+	}
+
+	assertAttendee := func(user domain.User, want bool) {
+		t.Helper()
+		attendee, err := repo.IsAttendee(evn.Id, user.Id)
+		if err != nil {
+			t.Fatalf("IsAttendee: %s", err)
+		}
+		if attendee != want {
+			t.Errorf("user %d attendee = %t, want %t", user.Id, attendee, want)
+		}
+	}
+
+	// without check-ins everyone going is taken at their word
+	assertAttendee(going, true)
+	assertAttendee(absent, true)
+	assertAttendee(maybe, false)
+
+	// once the door is checking tickets, only those checked in went
+	for _, u := range []domain.User{going, absent} {
+		_, err := tickets.Save(domain.Ticket{EventId: evn.Id, UserId: u.Id, Nonce: uuid.NewString()})
+		if err != nil {
+			t.Fatalf("TicketRepository.Save: %s", err)
+		}
+	}
+	ticket, err := tickets.FindByEventAndUser(evn.Id, going.Id)
+	if err != nil {
+		t.Fatalf("FindByEventAndUser: %s", err)
+	}
+	_, err = tickets.CheckIn(ticket.Id, owner.Id)
+	if err != nil {
+		t.Fatalf("CheckIn: %s", err)
+	}
+	assertAttendee(going, true)
+	assertAttendee(absent, false)
+	assertAttendee(maybe, false)
+}
@@ -0,0 +1,149 @@
+package database
+
+import (
+	"errors"
+	"time"
+
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"github.com/upper/db/v4"
+)
+
+const TicketsTableName = "tickets"
+
+type ticket struct {
+	Id            uint64     `db:"id,omitempty"`
+	EventId       uint64     `db:"event_id"`
+	UserId        uint64     `db:"user_id"`
+	Nonce         string     `db:"nonce"`
+	CheckedInDate *time.Time `db:"checked_in_date"`
+	CheckedInBy   *uint64    `db:"checked_in_by"`
+	CreatedDate   time.Time  `db:"created_date"`
+}
+
+type TicketRepository interface {
+	Save(t domain.Ticket) (domain.Ticket, error)
+	Find(id uint64) (domain.Ticket, error)
+	FindByEventAndUser(eventId, userId uint64) (domain.Ticket, error)
+	CheckIn(id, checkedInBy uint64) (bool, error)
+	Delete(eventId, userId uint64) error
+	Stats(eventId uint64) (domain.CheckInStats, error)
+}
+
+type ticketRepository struct {
+	coll db.Collection
+	sess db.Session
+}
+
+func NewTicketRepository(dbSession db.Session) TicketRepository {
+	return ticketRepository{
+		coll: dbSession.Collection(TicketsTableName),
+		sess: dbSession,
+	}
+}
+
+// Save creates the ticket unless the user already has one for the event, the existing
+// ticket is returned then. Concurrent calls end up with the same ticket.
+func (r ticketRepository) Save(t domain.Ticket) (domain.Ticket, error) {
+	tkt := r.mapDomainToModel(t)
+	tkt.CreatedDate = time.Now()
+	err := r.sess.SQL().
+		Iterator(
+			"INSERT INTO tickets (event_id, user_id, nonce, created_date) VALUES (?, ?, ?, ?) "+
+				"ON CONFLICT (event_id, user_id) DO NOTHING RETURNING *",
+			tkt.EventId, tkt.UserId, tkt.Nonce, tkt.CreatedDate,
+		).
+		One(&tkt)
+	if errors.Is(err, db.ErrNoMoreRows) {
+		return r.FindByEventAndUser(t.EventId, t.UserId)
+	} else if err != nil {
+		return domain.Ticket{}, err
+	}
+	return r.mapModelToDomain(tkt), nil
+}
+
+func (r ticketRepository) Find(id uint64) (domain.Ticket, error) {
+	var tkt ticket
+	err := r.coll.Find(db.Cond{"id": id}).One(&tkt)
+	if err != nil {
+		return domain.Ticket{}, err
+	}
+	return r.mapModelToDomain(tkt), nil
+}
+
+func (r ticketRepository) FindByEventAndUser(eventId, userId uint64) (domain.Ticket, error) {
+	var tkt ticket
+	err := r.coll.Find(db.Cond{"event_id": eventId, "user_id": userId}).One(&tkt)
+	if err != nil {
+		return domain.Ticket{}, err
+	}
+	return r.mapModelToDomain(tkt), nil
+}
+
+// CheckIn marks the ticket as used. It reports false when the ticket had already been
+// checked in, the condition on checked_in_date makes concurrent scans safe.
+func (r ticketRepository) CheckIn(id, checkedInBy uint64) (bool, error) {
+	res, err := r.sess.SQL().
+		Update(TicketsTableName).
+		Set("checked_in_date", time.Now(), "checked_in_by", checkedInBy).
+		Where("id = ? AND checked_in_date IS NULL", id).
+		Exec()
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected == 1, nil
+}
+
+// Delete revokes a ticket that has not been used yet.
+func (r ticketRepository) Delete(eventId, userId uint64) error {
+	err := r.coll.Find(db.Cond{"event_id": eventId, "user_id": userId, "checked_in_date": nil}).Delete()
+	if err != nil && !errors.Is(err, db.ErrNoMoreRows) {
+		return err
+	}
+	return nil
+}
+
+func (r ticketRepository) Stats(eventId uint64) (domain.CheckInStats, error) {
+	issued, err := r.coll.Find(db.Cond{"event_id": eventId}).Count()
+	if err != nil {
+		return domain.CheckInStats{}, err
+	}
+
+	checkedIn, err := r.coll.Find(db.Cond{"event_id": eventId, "checked_in_date IS NOT": nil}).Count()
+	if err != nil {
+		return domain.CheckInStats{}, err
+	}
+
+	return domain.CheckInStats{
+		Issued:    issued,
+		CheckedIn: checkedIn,
+	}, nil
+}
+
+func (r ticketRepository) mapDomainToModel(d domain.Ticket) ticket {
+	return ticket{
+		Id:            d.Id,
+		EventId:       d.EventId,
+		UserId:        d.UserId,
+		Nonce:         d.Nonce,
+		CheckedInDate: d.CheckedInDate,
+		CheckedInBy:   d.CheckedInBy,
+		CreatedDate:   d.CreatedDate,
+	}
+}
+
+func (r ticketRepository) mapModelToDomain(m ticket) domain.Ticket {
+	return domain.Ticket{
+		Id:            m.Id,
+		EventId:       m.EventId,
+		UserId:        m.UserId,
+		Nonce:         m.Nonce,
+		CheckedInDate: m.CheckedInDate,
+		CheckedInBy:   m.CheckedInBy,
+		CreatedDate:   m.CreatedDate,
+	}
+}
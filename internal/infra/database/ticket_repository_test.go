@@ -0,0 +1,38 @@
+package database
+
+import (
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"github.com/google/uuid"
+	"sync"
+	"testing"
+)
+
+func TestTicketRepository_ConcurrentSave(t *testing.T) {
+	sess := testSession(t)
+	repo := NewTicketRepository(sess)
+
+	owner, attendee := createTestUser(t, sess), createTestUser(t, sess)
+	evn := createTestEvent(t, sess, owner.Id, nil)
+
+	const calls = 8
+	tickets := make([]domain.Ticket, calls)
+	errs := make([]error, calls)
+	var wg sync.WaitGroup
+	for i := 0; i < calls; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			tickets[i], errs[i] = repo.Save(domain.Ticket{EventId: evn.Id, UserId: attendee.Id, Nonce: uuid.NewString()})
+		}(i)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			t.Fatalf("Save: %s", err)
+		}
+		if tickets[i].Id != tickets[0].Id || tickets[i].Nonce != tickets[0].Nonce {
+			t.Fatalf("tickets = %+v and %+v, want the same ticket", tickets[0], tickets[i])
+		}
+	}
+}
@@ -10,21 +10,24 @@ import (
 const UsersTableName = "users"
 
 type user struct {
-	Id          uint64      `db:"id,omitempty"`
-	FirstName   string      `db:"first_name"`
-	SecondName  string      `db:"second_name"`
-	Password    string      `db:"password"`
-	Email       string      `db:"email"`
-	Image       string      `db:"image"`
-	Role        domain.Role `db:"role"`
-	CreatedDate time.Time   `db:"created_date,omitempty"`
-	UpdatedDate time.Time   `db:"updated_date,omitempty"`
-	DeletedDate *time.Time  `db:"deleted_date,omitempty"`
+	Id            uint64      `db:"id,omitempty"`
+	FirstName     string      `db:"first_name"`
+	SecondName    string      `db:"second_name"`
+	Password      string      `db:"password"`
+	Email         string      `db:"email"`
+	Image         string      `db:"image"`
+	Role          domain.Role `db:"role"`
+	CalendarToken *string     `db:"calendar_token"`
+	Locale        string      `db:"locale"`
+	CreatedDate   time.Time   `db:"created_date,omitempty"`
+	UpdatedDate   time.Time   `db:"updated_date,omitempty"`
+	DeletedDate   *time.Time  `db:"deleted_date,omitempty"`
 }
 
 type UserRepository interface {
 	FindByEmail(phone string) (domain.User, error)
 	FindById(id uint64) (domain.User, error)
+	FindByCalendarToken(token string) (domain.User, error)
 	Find(id uint64) (interface{}, error)
 	Save(user domain.User) (domain.User, error)
 	Update(user domain.User) (domain.User, error)
@@ -63,6 +66,16 @@ func (r userRepository) FindById(id uint64) (domain.User, error) {
 	return r.mapModelToDomain(usr), nil
 }
 
+func (r userRepository) FindByCalendarToken(token string) (domain.User, error) {
+	var usr user
+	err := r.coll.Find(db.Cond{"calendar_token": token, "deleted_date": nil}).One(&usr)
+	if err != nil {
+		return domain.User{}, err
+	}
+
+	return r.mapModelToDomain(usr), nil
+}
+
 func (r userRepository) Find(id uint64) (interface{}, error) {
 	var usr user
 	err := r.coll.Find(db.Cond{"id": id}).One(&usr)
@@ -98,31 +111,45 @@ func (r userRepository) Delete(id uint64) error {
 }
 
 func (r userRepository) mapDomainToModel(d domain.User) user {
+	var calendarToken *string
+	if d.CalendarToken != "" {
+		calendarToken = &d.CalendarToken
+	}
+
 	return user{
-		Id:          d.Id,
-		Email:       d.Email,
-		Password:    d.Password,
-		FirstName:   d.FirstName,
-		SecondName:  d.SecondName,
-		Image:       d.Image,
-		Role:        d.Role,
-		CreatedDate: d.CreatedDate,
-		UpdatedDate: d.UpdatedDate,
-		DeletedDate: d.DeletedDate,
+		Id:            d.Id,
+		Email:         d.Email,
+		Password:      d.Password,
+		FirstName:     d.FirstName,
+		SecondName:    d.SecondName,
+		Image:         d.Image,
+		Role:          d.Role,
+		CalendarToken: calendarToken,
+		Locale:        d.Locale,
+		CreatedDate:   d.CreatedDate,
+		UpdatedDate:   d.UpdatedDate,
+		DeletedDate:   d.DeletedDate,
 	}
 }
 
 func (r userRepository) mapModelToDomain(m user) domain.User {
+	var calendarToken string
+	if m.CalendarToken != nil {
+		calendarToken = *m.CalendarToken
+	}
+
 	return domain.User{
-		Id:          m.Id,
-		Email:       m.Email,
-		Password:    m.Password,
-		FirstName:   m.FirstName,
-		SecondName:  m.SecondName,
-		Role:        m.Role,
-		Image:       m.Image,
-		CreatedDate: m.CreatedDate,
-		UpdatedDate: m.UpdatedDate,
-		DeletedDate: m.DeletedDate,
+		Id:            m.Id,
+		Email:         m.Email,
+		Password:      m.Password,
+		FirstName:     m.FirstName,
+		SecondName:    m.SecondName,
+		Role:          m.Role,
+		CalendarToken: calendarToken,
+		Locale:        m.Locale,
+		Image:         m.Image,
+		CreatedDate:   m.CreatedDate,
+		UpdatedDate:   m.UpdatedDate,
+		DeletedDate:   m.DeletedDate,
 	}
 }
@@ -0,0 +1,226 @@
+package database
+
+import (
+	"log"
+	"strings"
+	"time"
+
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"github.com/upper/db/v4"
+)
+
+const (
+	VenuesTableName      = "venues"
+	VenuePhotosTableName = "venue_photos"
+)
+
+type venue struct {
+	Id          uint64     `db:"id,omitempty"`
+	UserId      uint64     `db:"user_id"`
+	Name        string     `db:"name"`
+	Address     string     `db:"address"`
+	City        string     `db:"city"`
+	Lat         float64    `db:"lat"`
+	Lon         float64    `db:"lon"`
+	Capacity    *uint64    `db:"capacity"`
+	CreatedDate time.Time  `db:"created_date,omitempty"`
+	UpdatedDate time.Time  `db:"updated_date,omitempty"`
+	DeletedDate *time.Time `db:"deleted_date,omitempty"`
+}
+
+type venuePhoto struct {
+	Id          uint64    `db:"id,omitempty"`
+	VenueId     uint64    `db:"venue_id"`
+	Image       string    `db:"image"`
+	CreatedDate time.Time `db:"created_date"`
+}
+
+type VenueFilters struct {
+	Search string
+	City   string
+}
+
+type VenueRepository interface {
+	Save(v domain.Venue) (domain.Venue, error)
+	Update(v domain.Venue) (domain.Venue, error)
+	Find(id uint64) (domain.Venue, error)
+	FindList(filters VenueFilters, p domain.Pagination) ([]domain.Venue, uint64, error)
+	Delete(id uint64) error
+	SavePhoto(p domain.VenuePhoto) (domain.VenuePhoto, error)
+	FindPhoto(venueId, id uint64) (domain.VenuePhoto, error)
+	DeletePhoto(id uint64) error
+}
+
+type venueRepository struct {
+	coll db.Collection
+	sess db.Session
+}
+
+func NewVenueRepository(dbSession db.Session) VenueRepository {
+	return venueRepository{
+		coll: dbSession.Collection(VenuesTableName),
+		sess: dbSession,
+	}
+}
+
+func (r venueRepository) Save(v domain.Venue) (domain.Venue, error) {
+	vn := r.mapDomainToModel(v)
+	vn.CreatedDate, vn.UpdatedDate = time.Now(), time.Now()
+	err := r.coll.InsertReturning(&vn)
+	if err != nil {
+		log.Printf("VenueRepository -> Save -> r.coll.InsertReturning: %s", err)
+		return domain.Venue{}, err
+	}
+	return r.mapModelToDomain(vn), nil
+}
+
+func (r venueRepository) Update(v domain.Venue) (domain.Venue, error) {
+	vn := r.mapDomainToModel(v)
+	vn.UpdatedDate = time.Now()
+	err := r.coll.Find(db.Cond{"id": vn.Id, "deleted_date": nil}).Update(&vn)
+	if err != nil {
+		log.Printf("VenueRepository -> Update -> r.coll.Find: %s", err)
+		return domain.Venue{}, err
+	}
+
+	result := r.mapModelToDomain(vn)
+	result.Photos = v.Photos
+	return result, nil
+}
+
+func (r venueRepository) Find(id uint64) (domain.Venue, error) {
+	var vn venue
+	err := r.coll.Find(db.Cond{"id": id, "deleted_date": nil}).One(&vn)
+	if err != nil {
+		return domain.Venue{}, err
+	}
+
+	var photos []venuePhoto
+	err = r.sess.Collection(VenuePhotosTableName).Find(db.Cond{"venue_id": id}).OrderBy("id").All(&photos)
+	if err != nil {
+		log.Printf("VenueRepository -> Find -> photos: %s", err)
+		return domain.Venue{}, err
+	}
+
+	result := r.mapModelToDomain(vn)
+	for _, p := range photos {
+		result.Photos = append(result.Photos, r.mapPhotoModelToDomain(p))
+	}
+	return result, nil
+}
+
+// FindList returns a page of the venues ordered by name, without their photos.
+func (r venueRepository) FindList(filters VenueFilters, p domain.Pagination) ([]domain.Venue, uint64, error) {
+	conds := db.And(db.Cond{"deleted_date": nil})
+	if filters.Search != "" {
+		search := "%" + escapeLike(strings.ToLower(filters.Search)) + "%"
+		conds = conds.And(db.Raw(`(LOWER(name) LIKE ? OR LOWER(address) LIKE ?)`, search, search))
+	}
+	if filters.City != "" {
+		city := "%" + escapeLike(strings.ToLower(filters.City)) + "%"
+		conds = conds.And(db.Raw(`LOWER(city) LIKE ?`, city))
+	}
+
+	query := r.coll.Find(conds)
+	total, err := query.Count()
+	if err != nil {
+		log.Printf("VenueRepository -> FindList -> query.Count: %s", err)
+		return nil, 0, err
+	}
+
+	var venues []venue
+	err = query.OrderBy("name", "id").Paginate(uint(p.CountPerPage)).Page(uint(p.Page)).All(&venues)
+	if err != nil {
+		log.Printf("VenueRepository -> FindList -> query.All: %s", err)
+		return nil, 0, err
+	}
+
+	result := make([]domain.Venue, len(venues))
+	for i, v := range venues {
+		result[i] = r.mapModelToDomain(v)
+	}
+	return result, total, nil
+}
+
+// Delete hides the venue. Its events are detached from it and keep their place in the
+// legacy columns, so they can still be updated.
+func (r venueRepository) Delete(id uint64) error {
+	return r.sess.Tx(func(tx db.Session) error {
+		err := tx.Collection(VenuesTableName).
+			Find(db.Cond{"id": id, "deleted_date": nil}).
+			Update(map[string]interface{}{"deleted_date": time.Now()})
+		if err != nil {
+			return err
+		}
+		return tx.Collection(EventTableName).
+			Find(db.Cond{"venue_id": id}).
+			Update(map[string]interface{}{"venue_id": nil})
+	})
+}
+
+func (r venueRepository) SavePhoto(p domain.VenuePhoto) (domain.VenuePhoto, error) {
+	photo := venuePhoto{
+		VenueId:     p.VenueId,
+		Image:       p.Image,
+		CreatedDate: time.Now(),
+	}
+	err := r.sess.Collection(VenuePhotosTableName).InsertReturning(&photo)
+	if err != nil {
+		return domain.VenuePhoto{}, err
+	}
+	return r.mapPhotoModelToDomain(photo), nil
+}
+
+func (r venueRepository) FindPhoto(venueId, id uint64) (domain.VenuePhoto, error) {
+	var photo venuePhoto
+	err := r.sess.Collection(VenuePhotosTableName).Find(db.Cond{"id": id, "venue_id": venueId}).One(&photo)
+	if err != nil {
+		return domain.VenuePhoto{}, err
+	}
+	return r.mapPhotoModelToDomain(photo), nil
+}
+
+func (r venueRepository) DeletePhoto(id uint64) error {
+	return r.sess.Collection(VenuePhotosTableName).Find(db.Cond{"id": id}).Delete()
+}
+
+func (r venueRepository) mapDomainToModel(d domain.Venue) venue {
+	return venue{
+		Id:          d.Id,
+		UserId:      d.UserId,
+		Name:        d.Name,
+		Address:     d.Address,
+		City:        d.City,
+		Lat:         d.Lat,
+		Lon:         d.Lon,
+		Capacity:    d.Capacity,
+		CreatedDate: d.CreatedDate,
+		UpdatedDate: d.UpdatedDate,
+		DeletedDate: d.DeletedDate,
+	}
+}
+
+func (r venueRepository) mapModelToDomain(m venue) domain.Venue {
+	return domain.Venue{
+		Id:          m.Id,
+		UserId:      m.UserId,
+		Name:        m.Name,
+		Address:     m.Address,
+		City:        m.City,
+		Lat:         m.Lat,
+		Lon:         m.Lon,
+		Capacity:    m.Capacity,
+		CreatedDate: m.CreatedDate,
+		UpdatedDate: m.UpdatedDate,
+		DeletedDate: m.DeletedDate,
+	}
+}
+
+func (r venueRepository) mapPhotoModelToDomain(m venuePhoto) domain.VenuePhoto {
+	return domain.VenuePhoto{
+		Id:          m.Id,
+		VenueId:     m.VenueId,
+		Image:       m.Image,
+		CreatedDate: m.CreatedDate,
+	}
+}
@@ -0,0 +1,37 @@
+package database
+
+import (
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"testing"
+)
+
+func TestVenueRepository_DeleteDetachesEvents(t *testing.T) {
+	sess := testSession(t)
+	venueRepo := NewVenueRepository(sess)
+	eventRepo := NewEventRepository(sess)
+
+	owner := createTestUser(t, sess)
+	v, err := venueRepo.Save(domain.Venue{UserId: owner.Id, Name: "Hall", City: "Lviv", Lat: 49.84, Lon: 24.03})
+	if err != nil {
+		t.Fatalf("VenueRepository.Save: %s", err)
+	}
+	evn := createTestEvent(t, sess, owner.Id, nil)
+	evn.VenueId, evn.Location, evn.City = &v.Id, v.Name, v.City
+	_, _, err = eventRepo.Update(evn)
+	if err != nil {
+		t.Fatalf("EventRepository.Update: %s", err)
+	}
+
+	err = venueRepo.Delete(v.Id)
+	if err != nil {
+		t.Fatalf("Delete: %s", err)
+	}
+	found, err := eventRepo.Find(evn.Id)
+	if err != nil {
+		t.Fatalf("EventRepository.Find: %s", err)
+	}
+	e := found.(domain.Event)
+	if e.VenueId != nil || e.Location != v.Name || e.City != v.City {
+		t.Errorf("event = venue %v at %s, %s, want no venue at %s, %s", e.VenueId, e.Location, e.City, v.Name, v.City)
+	}
+}
@@ -0,0 +1,303 @@
+package database
+
+import (
+	"log"
+	"time"
+
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"github.com/upper/db/v4"
+	"github.com/upper/db/v4/adapter/postgresql"
+)
+
+const (
+	WebhooksTableName          = "webhooks"
+	WebhookDeliveriesTableName = "webhook_deliveries"
+)
+
+type webhook struct {
+	Id          uint64                 `db:"id,omitempty"`
+	UserId      uint64                 `db:"user_id"`
+	Url         string                 `db:"url"`
+	Secret      string                 `db:"secret"`
+	Events      postgresql.StringArray `db:"events"`
+	Active      bool                   `db:"active"`
+	CreatedDate time.Time              `db:"created_date"`
+	UpdatedDate time.Time              `db:"updated_date"`
+}
+
+type webhookDelivery struct {
+	Id              uint64                       `db:"id,omitempty"`
+	WebhookId       uint64                       `db:"webhook_id"`
+	EventType       domain.WebhookEventType      `db:"event_type"`
+	Payload         string                       `db:"payload"`
+	Status          domain.WebhookDeliveryStatus `db:"status"`
+	Attempts        uint                         `db:"attempts"`
+	NextAttemptDate time.Time                    `db:"next_attempt_date"`
+	ResponseCode    int                          `db:"response_code"`
+	Error           string                       `db:"error"`
+	CreatedDate     time.Time                    `db:"created_date"`
+	DeliveredDate   *time.Time                   `db:"delivered_date"`
+}
+
+type WebhookRepository interface {
+	Save(w domain.Webhook) (domain.Webhook, error)
+	Update(w domain.Webhook) (domain.Webhook, error)
+	Find(id uint64) (domain.Webhook, error)
+	FindByUser(userId uint64) ([]domain.Webhook, error)
+	CountByUser(userId uint64) (uint64, error)
+	FindSubscribed(t domain.WebhookEventType, ownerId uint64) ([]domain.Webhook, error)
+	Delete(id uint64) error
+	SaveDelivery(d domain.WebhookDelivery) (domain.WebhookDelivery, error)
+	UpdateDelivery(d domain.WebhookDelivery) error
+	FindDelivery(id uint64) (domain.WebhookDelivery, error)
+	FindDeliveries(webhookId uint64, p domain.Pagination) ([]domain.WebhookDelivery, uint64, error)
+	ClaimDueDeliveries(limit uint, lease time.Duration) ([]domain.WebhookDelivery, error)
+}
+
+type webhookRepository struct {
+	coll       db.Collection
+	deliveries db.Collection
+	sess       db.Session
+}
+
+func NewWebhookRepository(dbSession db.Session) WebhookRepository {
+	return webhookRepository{
+		coll:       dbSession.Collection(WebhooksTableName),
+		deliveries: dbSession.Collection(WebhookDeliveriesTableName),
+		sess:       dbSession,
+	}
+}
+
+func (r webhookRepository) Save(w domain.Webhook) (domain.Webhook, error) {
+	m := r.mapDomainToModel(w)
+	m.CreatedDate, m.UpdatedDate = time.Now(), time.Now()
+	err := r.coll.InsertReturning(&m)
+	if err != nil {
+		log.Printf("WebhookRepository -> Save -> r.coll.InsertReturning: %s", err)
+		return domain.Webhook{}, err
+	}
+	return r.mapModelToDomain(m), nil
+}
+
+func (r webhookRepository) Update(w domain.Webhook) (domain.Webhook, error) {
+	m := r.mapDomainToModel(w)
+	m.UpdatedDate = time.Now()
+	err := r.coll.Find(db.Cond{"id": m.Id}).Update(&m)
+	if err != nil {
+		log.Printf("WebhookRepository -> Update -> r.coll.Update: %s", err)
+		return domain.Webhook{}, err
+	}
+	return r.mapModelToDomain(m), nil
+}
+
+func (r webhookRepository) Find(id uint64) (domain.Webhook, error) {
+	var w webhook
+	err := r.coll.Find(db.Cond{"id": id}).One(&w)
+	if err != nil {
+		return domain.Webhook{}, err
+	}
+	return r.mapModelToDomain(w), nil
+}
+
+func (r webhookRepository) FindByUser(userId uint64) ([]domain.Webhook, error) {
+	var webhooks []webhook
+	err := r.coll.Find(db.Cond{"user_id": userId}).OrderBy("id").All(&webhooks)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]domain.Webhook, len(webhooks))
+	for i, w := range webhooks {
+		result[i] = r.mapModelToDomain(w)
+	}
+	return result, nil
+}
+
+func (r webhookRepository) CountByUser(userId uint64) (uint64, error) {
+	return r.coll.Find(db.Cond{"user_id": userId}).Count()
+}
+
+// FindSubscribed returns the active webhooks subscribed to the type, which belong to the
+// owner of the changed event or to an admin.
+func (r webhookRepository) FindSubscribed(t domain.WebhookEventType, ownerId uint64) ([]domain.Webhook, error) {
+	var webhooks []webhook
+	err := r.sess.SQL().
+		Select("w.*").
+		From(WebhooksTableName+" AS w").
+		Join(UsersTableName+" AS u").On("u.id = w.user_id").
+		Where("w.active AND ? = ANY(w.events)", t).
+		And("(w.user_id = ? OR u.role = ?)", ownerId, domain.AdminRole).
+		And("u.deleted_date IS NULL").
+		OrderBy("w.id").
+		All(&webhooks)
+	if err != nil {
+		log.Printf("WebhookRepository -> FindSubscribed -> r.sess.SQL(): %s", err)
+		return nil, err
+	}
+
+	result := make([]domain.Webhook, len(webhooks))
+	for i, w := range webhooks {
+		result[i] = r.mapModelToDomain(w)
+	}
+	return result, nil
+}
+
+func (r webhookRepository) Delete(id uint64) error {
+	return r.coll.Find(db.Cond{"id": id}).Delete()
+}
+
+// SaveDelivery queues the delivery, its first attempt is due at once.
+func (r webhookRepository) SaveDelivery(d domain.WebhookDelivery) (domain.WebhookDelivery, error) {
+	m := r.mapDeliveryToModel(d)
+	m.Status = domain.PendingWebhookDeliveryStatus
+	m.CreatedDate, m.NextAttemptDate = time.Now(), time.Now()
+	err := r.deliveries.InsertReturning(&m)
+	if err != nil {
+		log.Printf("WebhookRepository -> SaveDelivery -> r.deliveries.InsertReturning: %s", err)
+		return domain.WebhookDelivery{}, err
+	}
+	return r.mapModelToDelivery(m), nil
+}
+
+func (r webhookRepository) UpdateDelivery(d domain.WebhookDelivery) error {
+	return r.deliveries.Find(db.Cond{"id": d.Id}).Update(map[string]interface{}{
+		"status":            d.Status,
+		"attempts":          d.Attempts,
+		"next_attempt_date": d.NextAttemptDate,
+		"response_code":     d.ResponseCode,
+		"error":             d.Error,
+		"delivered_date":    d.DeliveredDate,
+	})
+}
+
+func (r webhookRepository) FindDelivery(id uint64) (domain.WebhookDelivery, error) {
+	var d webhookDelivery
+	err := r.deliveries.Find(db.Cond{"id": id}).One(&d)
+	if err != nil {
+		return domain.WebhookDelivery{}, err
+	}
+	return r.mapModelToDelivery(d), nil
+}
+
+// FindDeliveries returns a page of the deliveries of the webhook, the newest first.
+func (r webhookRepository) FindDeliveries(webhookId uint64, p domain.Pagination) ([]domain.WebhookDelivery, uint64, error) {
+	query := r.deliveries.Find(db.Cond{"webhook_id": webhookId})
+	total, err := query.Count()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var deliveries []webhookDelivery
+	err = query.
+		OrderBy("-id").
+		Paginate(uint(p.CountPerPage)).
+		Page(uint(p.Page)).
+		All(&deliveries)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	result := make([]domain.WebhookDelivery, len(deliveries))
+	for i, d := range deliveries {
+		result[i] = r.mapModelToDelivery(d)
+	}
+	return result, total, nil
+}
+
+// ClaimDueDeliveries works like MailRepository.ClaimDue.
+func (r webhookRepository) ClaimDueDeliveries(limit uint, lease time.Duration) ([]domain.WebhookDelivery, error) {
+	now := time.Now()
+	due := r.sess.SQL().
+		Select("id").
+		From(WebhookDeliveriesTableName).
+		Where("status = ? AND next_attempt_date <= ?", domain.PendingWebhookDeliveryStatus, now).
+		OrderBy("next_attempt_date", "id").
+		Limit(int(limit)).
+		Amend(func(query string) string {
+			return query + " FOR UPDATE SKIP LOCKED"
+		})
+
+	var deliveries []webhookDelivery
+	err := r.sess.SQL().Iterator(
+		`UPDATE `+WebhookDeliveriesTableName+` SET next_attempt_date = ? WHERE id IN ? RETURNING *`,
+		now.Add(lease), due,
+	).All(&deliveries)
+	if err != nil {
+		log.Printf("WebhookRepository -> ClaimDueDeliveries -> r.sess.SQL().Iterator: %s", err)
+		return nil, err
+	}
+
+	result := make([]domain.WebhookDelivery, len(deliveries))
+	for i, d := range deliveries {
+		result[i] = r.mapModelToDelivery(d)
+	}
+	return result, nil
+}
+
+func (r webhookRepository) mapDomainToModel(d domain.Webhook) webhook {
+	events := make(postgresql.StringArray, len(d.Events))
+	for i, e := range d.Events {
+		events[i] = string(e)
+	}
+
+	return webhook{
+		Id:          d.Id,
+		UserId:      d.UserId,
+		Url:         d.Url,
+		Secret:      d.Secret,
+		Events:      events,
+		Active:      d.Active,
+		CreatedDate: d.CreatedDate,
+		UpdatedDate: d.UpdatedDate,
+	}
+}
+
+func (r webhookRepository) mapModelToDomain(m webhook) domain.Webhook {
+	events := make([]domain.WebhookEventType, len(m.Events))
+	for i, e := range m.Events {
+		events[i] = domain.WebhookEventType(e)
+	}
+
+	return domain.Webhook{
+		Id:          m.Id,
+		UserId:      m.UserId,
+		Url:         m.Url,
+		Secret:      m.Secret,
+		Events:      events,
+		Active:      m.Active,
+		CreatedDate: m.CreatedDate,
+		UpdatedDate: m.UpdatedDate,
+	}
+}
+
+func (r webhookRepository) mapDeliveryToModel(d domain.WebhookDelivery) webhookDelivery {
+	return webhookDelivery{
+		Id:              d.Id,
+		WebhookId:       d.WebhookId,
+		EventType:       d.EventType,
+		Payload:         d.Payload,
+		Status:          d.Status,
+		Attempts:        d.Attempts,
+		NextAttemptDate: d.NextAttemptDate,
+		ResponseCode:    d.ResponseCode,
+		Error:           d.Error,
+		CreatedDate:     d.CreatedDate,
+		DeliveredDate:   d.DeliveredDate,
+	}
+}
+
+func (r webhookRepository) mapModelToDelivery(m webhookDelivery) domain.WebhookDelivery {
+	return domain.WebhookDelivery{
+		Id:              m.Id,
+		WebhookId:       m.WebhookId,
+		EventType:       m.EventType,
+		Payload:         m.Payload,
+		Status:          m.Status,
+		Attempts:        m.Attempts,
+		NextAttemptDate: m.NextAttemptDate,
+		ResponseCode:    m.ResponseCode,
+		Error:           m.Error,
+		CreatedDate:     m.CreatedDate,
+		DeliveredDate:   m.DeliveredDate,
+	}
+}
@@ -0,0 +1,161 @@
+package geocoding
+
+import (
+	"bufio"
+	"bytes"
+	_ "embed"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+)
+
+// maxReverseDistanceKm is how far from the nearest known city a point may be
+// to still be reverse-geocoded to it.
+const maxReverseDistanceKm = 50
+
+//go:embed data/cities.tsv
+var bundledCities []byte
+
+// GeoNames dump columns, see https://download.geonames.org/export/dump/readme.txt
+const (
+	colName           = 1
+	colAsciiName      = 2
+	colAlternateNames = 3
+	colLatitude       = 4
+	colLongitude      = 5
+	colCountryCode    = 8
+	colPopulation     = 14
+	minColumns        = 15
+)
+
+type city struct {
+	name       string
+	country    string
+	point      domain.GeoPoint
+	population uint64
+}
+
+// Gazetteer geocodes cities offline, from a GeoNames cities file (e.g. cities15000.txt).
+// Street addresses are resolved to their city.
+type Gazetteer struct {
+	cities []city
+	byName map[string][]int
+}
+
+// NewGazetteer loads the cities file at path, or the bundled one when path is empty.
+func NewGazetteer(path string) (Gazetteer, error) {
+	var r io.Reader = bytes.NewReader(bundledCities)
+	if path != "" {
+		f, err := os.Open(path)
+		if err != nil {
+			return Gazetteer{}, err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	g := Gazetteer{byName: make(map[string][]int)}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		cols := strings.Split(scanner.Text(), "\t")
+		if len(cols) < minColumns {
+			continue
+		}
+
+		lat, err := strconv.ParseFloat(cols[colLatitude], 64)
+		if err != nil {
+			return Gazetteer{}, fmt.Errorf("line %d: invalid latitude: %w", line, err)
+		}
+		lon, err := strconv.ParseFloat(cols[colLongitude], 64)
+		if err != nil {
+			return Gazetteer{}, fmt.Errorf("line %d: invalid longitude: %w", line, err)
+		}
+		population, _ := strconv.ParseUint(cols[colPopulation], 10, 64)
+
+		g.cities = append(g.cities, city{
+			name:       cols[colName],
+			country:    cols[colCountryCode],
+			point:      domain.GeoPoint{Lat: lat, Lon: lon},
+			population: population,
+		})
+
+		names := append([]string{cols[colName], cols[colAsciiName]}, strings.Split(cols[colAlternateNames], ",")...)
+		for _, name := range names {
+			g.index(normalizeName(name), len(g.cities)-1)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return Gazetteer{}, err
+	}
+
+	return g, nil
+}
+
+func (g Gazetteer) index(name string, i int) {
+	if name == "" {
+		return
+	}
+	for _, existing := range g.byName[name] {
+		if existing == i {
+			return
+		}
+	}
+	g.byName[name] = append(g.byName[name], i)
+}
+
+// Forward finds the city of the query. The comma separated parts of an address are tried
+// from the last one, so "Khreshchatyk 22, Kyiv" resolves to Kyiv. The most populous city
+// wins when the name is ambiguous.
+func (g Gazetteer) Forward(query string) (domain.GeoPlace, error) {
+	parts := strings.Split(query, ",")
+	for i := len(parts) - 1; i >= 0; i-- {
+		best := -1
+		for _, c := range g.byName[normalizeName(parts[i])] {
+			if best == -1 || g.cities[c].population > g.cities[best].population {
+				best = c
+			}
+		}
+		if best != -1 {
+			return g.place(best), nil
+		}
+	}
+
+	return domain.GeoPlace{}, domain.ErrPlaceNotFound
+}
+
+// Reverse returns the city nearest to the point.
+func (g Gazetteer) Reverse(point domain.GeoPoint) (domain.GeoPlace, error) {
+	best, bestDistance := -1, 0.0
+	for i, c := range g.cities {
+		distance := point.DistanceKm(c.point)
+		if best == -1 || distance < bestDistance {
+			best, bestDistance = i, distance
+		}
+	}
+	if best == -1 || bestDistance > maxReverseDistanceKm {
+		return domain.GeoPlace{}, domain.ErrPlaceNotFound
+	}
+
+	return g.place(best), nil
+}
+
+func (g Gazetteer) place(i int) domain.GeoPlace {
+	c := g.cities[i]
+	return domain.GeoPlace{
+		Name:    c.name,
+		City:    c.name,
+		Country: c.country,
+		Point:   c.point,
+	}
+}
+
+func normalizeName(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
@@ -0,0 +1,131 @@
+package geocoding
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+)
+
+const (
+	httpTimeout = 5 * time.Second
+	userAgent   = "eventio-geocoder/1.0"
+)
+
+// HttpGeocoder talks to a Nominatim compatible API: GET /search?q=&format=jsonv2&limit=1
+// and GET /reverse?lat=&lon=&format=jsonv2. Any service implementing these two endpoints,
+// e.g. a local stub, can stand in for it.
+type HttpGeocoder struct {
+	baseUrl string
+	client  *http.Client
+}
+
+type nominatimPlace struct {
+	Lat         string `json:"lat"`
+	Lon         string `json:"lon"`
+	Name        string `json:"name"`
+	DisplayName string `json:"display_name"`
+	Error       string `json:"error"`
+	Address     struct {
+		City        string `json:"city"`
+		Town        string `json:"town"`
+		Village     string `json:"village"`
+		CountryCode string `json:"country_code"`
+	} `json:"address"`
+}
+
+func NewHttpGeocoder(baseUrl string) HttpGeocoder {
+	return HttpGeocoder{
+		baseUrl: baseUrl,
+		client:  &http.Client{Timeout: httpTimeout},
+	}
+}
+
+func (g HttpGeocoder) Forward(query string) (domain.GeoPlace, error) {
+	params := url.Values{"q": {query}, "format": {"jsonv2"}, "limit": {"1"}, "addressdetails": {"1"}}
+
+	var places []nominatimPlace
+	err := g.get("/search", params, &places)
+	if err != nil {
+		return domain.GeoPlace{}, err
+	}
+	if len(places) == 0 {
+		return domain.GeoPlace{}, domain.ErrPlaceNotFound
+	}
+
+	return places[0].toDomain()
+}
+
+func (g HttpGeocoder) Reverse(point domain.GeoPoint) (domain.GeoPlace, error) {
+	params := url.Values{
+		"lat":    {strconv.FormatFloat(point.Lat, 'f', -1, 64)},
+		"lon":    {strconv.FormatFloat(point.Lon, 'f', -1, 64)},
+		"format": {"jsonv2"},
+		"zoom":   {"10"},
+	}
+
+	var place nominatimPlace
+	err := g.get("/reverse", params, &place)
+	if err != nil {
+		return domain.GeoPlace{}, err
+	}
+	if place.Error != "" {
+		return domain.GeoPlace{}, domain.ErrPlaceNotFound
+	}
+
+	return place.toDomain()
+}
+
+func (g HttpGeocoder) get(path string, params url.Values, result interface{}) error {
+	req, err := http.NewRequest(http.MethodGet, g.baseUrl+path+"?"+params.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("geocoder responded with %s", resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(result)
+}
+
+func (p nominatimPlace) toDomain() (domain.GeoPlace, error) {
+	lat, err := strconv.ParseFloat(p.Lat, 64)
+	if err != nil {
+		return domain.GeoPlace{}, fmt.Errorf("invalid latitude %q", p.Lat)
+	}
+	lon, err := strconv.ParseFloat(p.Lon, 64)
+	if err != nil {
+		return domain.GeoPlace{}, fmt.Errorf("invalid longitude %q", p.Lon)
+	}
+
+	city := p.Address.City
+	if city == "" {
+		city = p.Address.Town
+	}
+	if city == "" {
+		city = p.Address.Village
+	}
+	name := p.Name
+	if name == "" {
+		name = p.DisplayName
+	}
+
+	return domain.GeoPlace{
+		Name:    name,
+		City:    city,
+		Country: p.Address.CountryCode,
+		Point:   domain.GeoPoint{Lat: lat, Lon: lon},
+	}, nil
+}
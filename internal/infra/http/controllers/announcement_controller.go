@@ -0,0 +1,97 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/app"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/http/requests"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/http/resources"
+	"log"
+	"net/http"
+)
+
+type AnnouncementController struct {
+	announcementService app.AnnouncementService
+}
+
+func NewAnnouncementController(as app.AnnouncementService) AnnouncementController {
+	return AnnouncementController{
+		announcementService: as,
+	}
+}
+
+func (c AnnouncementController) Announce() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		announcement, err := requests.Bind(r, requests.AnnouncementRequest{}, domain.Announcement{})
+		if err != nil {
+			log.Printf("AnnouncementController -> Announce -> requests.Bind: %s", err)
+			BadRequest(w, err)
+			return
+		}
+		ev, ok := r.Context().Value(EventKey).(domain.Event)
+		if !ok {
+			InternalServerError(w, fmt.Errorf("failed to cast event"))
+			return
+		}
+		user := r.Context().Value(UserKey).(domain.User)
+		if ev.UserId != user.Id {
+			Forbidden(w, fmt.Errorf("only the event owner can make announcements"))
+			return
+		}
+
+		announcement.UserId = user.Id
+		announcement, err = c.announcementService.Announce(ev, announcement)
+		if errors.Is(err, app.ErrTooManyAnnouncements) {
+			TooManyRequests(w, err)
+			return
+		} else if errors.Is(err, app.ErrEventNotAnnounceable) {
+			Conflict(w, err)
+			return
+		} else if err != nil {
+			InternalServerError(w, err)
+			return
+		}
+
+		var announcementDto resources.AnnouncementDto
+		Created(w, announcementDto.DomainToDto(announcement))
+	}
+}
+
+// FindByEvent lists the announcements of the event, the newest first.
+func (c AnnouncementController) FindByEvent() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ev, ok := r.Context().Value(EventKey).(domain.Event)
+		if !ok {
+			InternalServerError(w, fmt.Errorf("failed to cast event"))
+			return
+		}
+		user := r.Context().Value(UserKey).(domain.User)
+
+		allowed, err := c.announcementService.CanRead(ev, user)
+		if err != nil {
+			InternalServerError(w, err)
+			return
+		}
+		if !allowed {
+			Forbidden(w, fmt.Errorf("announcements are visible only to the subscribers of the event"))
+			return
+		}
+
+		pagination, err := requests.BindPagination(r)
+		if err != nil {
+			BadRequest(w, err)
+			return
+		}
+
+		announcements, total, err := c.announcementService.FindByEvent(ev.Id, pagination)
+		if err != nil {
+			InternalServerError(w, err)
+			return
+		}
+
+		paginationHeaders(w, r, pagination, total)
+		var announcementsDto resources.AnnouncementsDto
+		Success(w, announcementsDto.DomainToDto(announcements, total, pagination))
+	}
+}
@@ -0,0 +1,124 @@
+package controllers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/app"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/http/resources"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/ical"
+	"github.com/go-chi/chi/v5"
+	"github.com/upper/db/v4"
+	"log"
+	"net/http"
+)
+
+const CalendarFeedPath = "/api/v1/calendar/%s.ics"
+
+type CalendarController struct {
+	eventService app.EventService
+	userService  app.UserService
+}
+
+func NewCalendarController(es app.EventService, us app.UserService) CalendarController {
+	return CalendarController{
+		eventService: es,
+		userService:  us,
+	}
+}
+
+func (c CalendarController) EventIcs() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ev, ok := r.Context().Value(EventKey).(domain.Event)
+		if !ok {
+			InternalServerError(w, fmt.Errorf("failed to cast event"))
+			return
+		}
+
+		writeCalendar(w, ev.Title, []domain.Event{ev}, fmt.Sprintf("event_%d.ics", ev.Id))
+	}
+}
+
+// Feed is a public endpoint, the token in the path is the only credential.
+func (c CalendarController) Feed() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token := chi.URLParam(r, "token")
+		user, err := c.userService.FindByCalendarToken(token)
+		if err != nil {
+			if errors.Is(err, db.ErrNoMoreRows) {
+				NotFound(w, fmt.Errorf("calendar not found"))
+				return
+			}
+			InternalServerError(w, err)
+			return
+		}
+
+		events, err := c.eventService.GetUserCalendar(user.Id)
+		if err != nil {
+			log.Printf("CalendarController -> Feed -> c.eventService.GetUserCalendar: %s", err)
+			InternalServerError(w, err)
+			return
+		}
+
+		writeCalendar(w, "Eventio", events, "")
+	}
+}
+
+func (c CalendarController) FindMyFeed() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := r.Context().Value(UserKey).(domain.User)
+		user, err := c.userService.EnsureCalendarToken(user)
+		if err != nil {
+			log.Printf("CalendarController -> FindMyFeed -> c.userService.EnsureCalendarToken: %s", err)
+			InternalServerError(w, err)
+			return
+		}
+
+		var feedDto resources.CalendarFeedDto
+		Success(w, feedDto.DomainToDto(user, feedUrl(r, user.CalendarToken)))
+	}
+}
+
+func (c CalendarController) RotateFeed() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := r.Context().Value(UserKey).(domain.User)
+		user, err := c.userService.RotateCalendarToken(user)
+		if err != nil {
+			log.Printf("CalendarController -> RotateFeed -> c.userService.RotateCalendarToken: %s", err)
+			InternalServerError(w, err)
+			return
+		}
+
+		var feedDto resources.CalendarFeedDto
+		Success(w, feedDto.DomainToDto(user, feedUrl(r, user.CalendarToken)))
+	}
+}
+
+func writeCalendar(w http.ResponseWriter, name string, events []domain.Event, filename string) {
+	var buf bytes.Buffer
+	err := ical.Encode(&buf, name, events)
+	if err != nil {
+		log.Printf("ical.Encode: %s", err)
+		InternalServerError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	if filename != "" {
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	}
+	w.WriteHeader(http.StatusOK)
+	_, err = w.Write(buf.Bytes())
+	if err != nil {
+		log.Print(err)
+	}
+}
+
+func feedUrl(r *http.Request, token string) string {
+	scheme := "http"
+	if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return fmt.Sprintf("%s://%s"+CalendarFeedPath, scheme, r.Host, token)
+}
@@ -0,0 +1,113 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/app"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/http/requests"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/http/resources"
+	"log"
+	"net/http"
+)
+
+type CategoryController struct {
+	categoryService app.CategoryService
+}
+
+func NewCategoryController(cs app.CategoryService) CategoryController {
+	return CategoryController{
+		categoryService: cs,
+	}
+}
+
+func (c CategoryController) Save() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		category, err := requests.Bind(r, requests.CategoryRequest{}, domain.Category{})
+		if err != nil {
+			log.Printf("CategoryController -> Save -> requests.Bind: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		category, err = c.categoryService.Save(category)
+		if err != nil {
+			categoryError(w, err)
+			return
+		}
+
+		var categoryDto resources.CategoryDto
+		Created(w, categoryDto.DomainToDto(category))
+	}
+}
+
+func (c CategoryController) Update() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req, err := requests.Bind(r, requests.CategoryRequest{}, domain.Category{})
+		if err != nil {
+			log.Printf("CategoryController -> Update -> requests.Bind: %s", err)
+			BadRequest(w, err)
+			return
+		}
+		category, ok := r.Context().Value(CategoryKey).(domain.Category)
+		if !ok {
+			InternalServerError(w, fmt.Errorf("failed to cast category"))
+			return
+		}
+
+		category.ParentId = req.ParentId
+		category.Name = req.Name
+		category.Slug = req.Slug
+		category, err = c.categoryService.Update(category)
+		if err != nil {
+			categoryError(w, err)
+			return
+		}
+
+		var categoryDto resources.CategoryDto
+		Success(w, categoryDto.DomainToDto(category))
+	}
+}
+
+func (c CategoryController) FindAll() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		categories, err := c.categoryService.FindAll()
+		if err != nil {
+			InternalServerError(w, err)
+			return
+		}
+
+		var categoriesDto resources.CategoriesDto
+		Success(w, categoriesDto.DomainToDto(categories))
+	}
+}
+
+func (c CategoryController) Delete() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		category, ok := r.Context().Value(CategoryKey).(domain.Category)
+		if !ok {
+			InternalServerError(w, fmt.Errorf("failed to cast category"))
+			return
+		}
+
+		err := c.categoryService.Delete(category.Id)
+		if err != nil {
+			categoryError(w, err)
+			return
+		}
+
+		noContent(w)
+	}
+}
+
+func categoryError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, app.ErrCategorySlugTaken), errors.Is(err, app.ErrCategoryHasChildren):
+		Conflict(w, err)
+	case errors.Is(err, app.ErrCategoryNotFound), errors.Is(err, app.ErrCategoryCycle), errors.Is(err, app.ErrInvalidCategorySlug):
+		BadRequest(w, err)
+	default:
+		log.Printf("CategoryController: %s", err)
+		InternalServerError(w, err)
+	}
+}
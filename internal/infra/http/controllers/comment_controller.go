@@ -0,0 +1,149 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/app"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/http/requests"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/http/resources"
+	"log"
+	"net/http"
+)
+
+type CommentController struct {
+	commentService app.CommentService
+}
+
+func NewCommentController(cs app.CommentService) CommentController {
+	return CommentController{
+		commentService: cs,
+	}
+}
+
+// Save comments on the event, or replies to one of its comments when parentId is given.
+func (c CommentController) Save() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		comment, err := requests.Bind(r, requests.CommentRequest{}, domain.Comment{})
+		if err != nil {
+			log.Printf("CommentController -> Save -> requests.Bind: %s", err)
+			BadRequest(w, err)
+			return
+		}
+		ev, ok := r.Context().Value(EventKey).(domain.Event)
+		if !ok {
+			InternalServerError(w, fmt.Errorf("failed to cast event"))
+			return
+		}
+		user := r.Context().Value(UserKey).(domain.User)
+
+		comment.UserId = user.Id
+		comment, err = c.commentService.Save(ev, comment)
+		if errors.Is(err, app.ErrInvalidCommentParent) {
+			BadRequest(w, err)
+			return
+		} else if err != nil {
+			InternalServerError(w, err)
+			return
+		}
+
+		var commentDto resources.CommentDto
+		Created(w, commentDto.DomainToDto(comment))
+	}
+}
+
+// FindByEvent lists the comments of the event, the newest first, each with its replies.
+func (c CommentController) FindByEvent() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ev, ok := r.Context().Value(EventKey).(domain.Event)
+		if !ok {
+			InternalServerError(w, fmt.Errorf("failed to cast event"))
+			return
+		}
+		pagination, err := requests.BindPagination(r)
+		if err != nil {
+			BadRequest(w, err)
+			return
+		}
+
+		comments, total, err := c.commentService.FindByEvent(ev.Id, pagination)
+		if err != nil {
+			InternalServerError(w, err)
+			return
+		}
+
+		paginationHeaders(w, r, pagination, total)
+		var commentsDto resources.CommentsDto
+		Success(w, commentsDto.DomainToDto(comments, total, pagination))
+	}
+}
+
+func (c CommentController) Update() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		comment, ok := c.pathComment(w, r)
+		if !ok {
+			return
+		}
+		user := r.Context().Value(UserKey).(domain.User)
+		if comment.UserId != user.Id {
+			Forbidden(w, fmt.Errorf("only the author can edit the comment"))
+			return
+		}
+
+		req, err := requests.Bind(r, requests.UpdateCommentRequest{}, domain.Comment{})
+		if err != nil {
+			log.Printf("CommentController -> Update -> requests.Bind: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		comment.Body = req.Body
+		comment, err = c.commentService.Update(comment)
+		if err != nil {
+			InternalServerError(w, err)
+			return
+		}
+
+		var commentDto resources.CommentDto
+		Success(w, commentDto.DomainToDto(comment))
+	}
+}
+
+// Delete removes the comment with its replies. The author, the owner of the event and
+// the admins can do it.
+func (c CommentController) Delete() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		comment, ok := c.pathComment(w, r)
+		if !ok {
+			return
+		}
+		user := r.Context().Value(UserKey).(domain.User)
+
+		allowed, err := c.commentService.CanDelete(comment, user)
+		if err != nil {
+			InternalServerError(w, err)
+			return
+		}
+		if !allowed {
+			Forbidden(w, fmt.Errorf("only the author, the event owner or an admin can delete the comment"))
+			return
+		}
+
+		err = c.commentService.Delete(comment.Id)
+		if err != nil {
+			InternalServerError(w, err)
+			return
+		}
+
+		noContent(w)
+	}
+}
+
+func (c CommentController) pathComment(w http.ResponseWriter, r *http.Request) (domain.Comment, bool) {
+	comment, ok := r.Context().Value(CommentKey).(domain.Comment)
+	if !ok {
+		InternalServerError(w, fmt.Errorf("failed to cast comment"))
+		return domain.Comment{}, false
+	}
+	return comment, true
+}
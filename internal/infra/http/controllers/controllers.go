@@ -5,6 +5,10 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
 )
 
 /* should not use built-in type string as key for value;
@@ -15,9 +19,17 @@ type CtxKey struct {
 }
 
 var (
-	UserKey  = CtxKey{Name: "user"}
-	SessKey  = CtxKey{Name: "sess"}
-	EventKey = CtxKey{Name: "event"}
+	UserKey         = CtxKey{Name: "user"}
+	SessKey         = CtxKey{Name: "sess"}
+	EventKey        = CtxKey{Name: "event"}
+	CategoryKey     = CtxKey{Name: "category"}
+	VenueKey        = CtxKey{Name: "venue"}
+	SavedSearchKey  = CtxKey{Name: "savedSearch"}
+	NotificationKey = CtxKey{Name: "notification"}
+	PushDeviceKey   = CtxKey{Name: "pushDevice"}
+	WebhookKey      = CtxKey{Name: "webhook"}
+	CommentKey      = CtxKey{Name: "comment"}
+	ReviewKey       = CtxKey{Name: "review"}
 )
 
 func Ok(w http.ResponseWriter) {
@@ -65,6 +77,20 @@ func Forbidden(w http.ResponseWriter, err error) {
 	encodeErrorBody(w, err)
 }
 
+func Conflict(w http.ResponseWriter, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusConflict)
+
+	encodeErrorBody(w, err)
+}
+
+func TooManyRequests(w http.ResponseWriter, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusTooManyRequests)
+
+	encodeErrorBody(w, err)
+}
+
 func InternalServerError(w http.ResponseWriter, err error) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusInternalServerError)
@@ -117,3 +143,34 @@ func Unauthorized(w http.ResponseWriter, err error) {
 
 	encodeErrorBody(w, err)
 }
+
+// paginationHeaders sets X-Total-Count and an RFC 8288 Link header with the first, prev,
+// next and last pages. The links keep all the other query parameters of the request.
+func paginationHeaders(w http.ResponseWriter, r *http.Request, p domain.Pagination, total uint64) {
+	w.Header().Set("X-Total-Count", strconv.FormatUint(total, 10))
+
+	last := (total + p.CountPerPage - 1) / p.CountPerPage
+	if last == 0 {
+		last = 1
+	}
+
+	pageUrl := func(page uint64) string {
+		u := *r.URL
+		query := u.Query()
+		query.Set("page", strconv.FormatUint(page, 10))
+		query.Set("perPage", strconv.FormatUint(p.CountPerPage, 10))
+		u.RawQuery = query.Encode()
+		return u.RequestURI()
+	}
+
+	links := []string{fmt.Sprintf(`<%s>; rel="first"`, pageUrl(1))}
+	if p.Page > 1 && p.Page <= last {
+		links = append(links, fmt.Sprintf(`<%s>; rel="prev"`, pageUrl(p.Page-1)))
+	}
+	if p.Page < last {
+		links = append(links, fmt.Sprintf(`<%s>; rel="next"`, pageUrl(p.Page+1)))
+	}
+	links = append(links, fmt.Sprintf(`<%s>; rel="last"`, pageUrl(last)))
+
+	w.Header().Set("Link", strings.Join(links, ", "))
+}
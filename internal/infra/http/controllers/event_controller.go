@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"errors"
 	"fmt"
 	"github.com/BohdanBoriak/boilerplate-go-back/internal/app"
 	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
@@ -8,11 +9,32 @@ import (
 	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/filesystem"
 	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/http/requests"
 	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/http/resources"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/ical"
 	"io"
 	"log"
+	"math"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
+	"unicode/utf8"
+)
+
+const (
+	maxImportSize   = 10 << 20
+	defaultRadiusKm = 10
+	maxRadiusKm     = 500
+	maxMapZoom      = 22
+
+	defaultSuggestLimit = 5
+	maxSuggestLimit     = 20
+	maxSuggestLength    = 100
+
+	maxCalendarDays       = 93
+	defaultCalendarPerDay = 3
+	maxCalendarPerDay     = 20
+	// groupedByDatePerDay keeps groupedByDate returning every event of a day
+	groupedByDatePerDay = math.MaxUint32
 )
 
 type EventController struct {
@@ -41,7 +63,10 @@ func (c EventController) Save() http.HandlerFunc {
 		event.Status = domain.NewEventStatus
 
 		event, err = c.eventService.Save(event)
-		if err != nil {
+		if isEventInputError(err) {
+			BadRequest(w, err)
+			return
+		} else if err != nil {
 			log.Printf("EventController -> Save -> c.eventService.Save(event): %s", err)
 			InternalServerError(w, err)
 			return
@@ -51,24 +76,53 @@ func (c EventController) Save() http.HandlerFunc {
 		Created(w, eventDto)
 	}
 }
+
+// Update changes the details of the event, those absent from the request stay as they are.
+// A new place is located as on creation.
 func (c EventController) Update() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		reqevent, err := requests.Bind(r, requests.UpdateEventRequest{}, domain.Event{})
+		ev, ok := r.Context().Value(EventKey).(domain.Event)
+		if !ok {
+			InternalServerError(w, fmt.Errorf("failed to cast event"))
+			return
+		}
+		user := r.Context().Value(UserKey).(domain.User)
+		if ev.UserId != user.Id && user.Role != domain.AdminRole {
+			Forbidden(w, fmt.Errorf("only the event owner can change it"))
+			return
+		}
+
+		reqevent, err := requests.Bind(r, requests.NewUpdateEventRequest(ev), domain.Event{})
 		if err != nil {
 			log.Printf("EventController -> Update -> requests.Bind:%s", err)
 			BadRequest(w, err)
 			return
 		}
-		ev, ok := r.Context().Value(EventKey).(domain.Event)
-		if !ok {
-			InternalServerError(w, fmt.Errorf("failed to cast event"))
-			return
+		moved := reqevent.Location != ev.Location || reqevent.City != ev.City || !sameId(reqevent.VenueId, ev.VenueId)
+		if moved || reqevent.Lat != 0 || reqevent.Lon != 0 {
+			// without the coordinates the new place is geocoded
+			ev.Lat, ev.Lon = reqevent.Lat, reqevent.Lon
 		}
 		ev.Title = reqevent.Title
 		ev.Description = reqevent.Description
+		ev.Capacity = reqevent.Capacity
+		ev.AttendeesPublic = reqevent.AttendeesPublic
+		ev.Date = reqevent.Date
+		ev.EndDate = reqevent.EndDate
+		ev.CategoryId = reqevent.CategoryId
+		ev.VenueId = reqevent.VenueId
+		ev.Location = reqevent.Location
+		ev.City = reqevent.City
+		if reqevent.Tags != nil {
+			ev.Tags = reqevent.Tags
+		}
+		ev.UpdatedBy = user.Id
 		reqevent, err = c.eventService.Update(ev)
 
-		if err != nil {
+		if isEventInputError(err) {
+			BadRequest(w, err)
+			return
+		} else if err != nil {
 			log.Printf("EventController -> Update -> c.eventService.Update(ev): %s", err)
 			InternalServerError(w, err)
 			return
@@ -97,22 +151,66 @@ func (c EventController) Delete() http.HandlerFunc {
 		Ok(w)
 	}
 }
-func (c EventController) FindAll() http.HandlerFunc {
+func (c EventController) Cancel() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		ev, ok := r.Context().Value(EventKey).(domain.Event)
+		if !ok {
+			InternalServerError(w, fmt.Errorf("failed to cast event"))
+			return
+		}
+		user := r.Context().Value(UserKey).(domain.User)
+		if ev.UserId != user.Id {
+			Forbidden(w, fmt.Errorf("only the event owner can cancel it"))
+			return
+		}
 
-		events, err := c.eventService.FindAll()
+		ev.UpdatedBy = user.Id
+		ev, err := c.eventService.Cancel(ev)
+		if errors.Is(err, app.ErrEventNotUpcoming) {
+			Conflict(w, err)
+			return
+		} else if err != nil {
+			log.Printf("EventController -> Cancel -> c.eventService.Cancel: %s", err)
+			InternalServerError(w, err)
+			return
+		}
 
+		var eventDto resources.EventDto
+		Success(w, eventDto.DomainToDto(ev))
+	}
+}
+func (c EventController) FindAll() http.HandlerFunc {
+	return c.FindList()
+}
+func (c EventController) Subscribe() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ev, ok := r.Context().Value(EventKey).(domain.Event)
+		if !ok {
+			InternalServerError(w, fmt.Errorf("failed to cast event"))
+			return
+		}
+		sub := domain.Subscription{Rsvp: domain.GoingRsvpStatus}
+		if r.ContentLength != 0 {
+			var err error
+			sub, err = requests.Bind(r, requests.SubscribeRequest{}, domain.Subscription{})
+			if err != nil {
+				log.Printf("EventController -> Subscribe -> requests.Bind: %s", err)
+				BadRequest(w, err)
+				return
+			}
+		}
+		user := r.Context().Value(UserKey).(domain.User)
+		sub, err := c.eventService.SubscribeToEvent(ev.Id, user.Id, sub.Rsvp)
 		if err != nil {
-			log.Printf("EventController -> FindAll -> evns, err := c.eventService.FindAll(): %s", err)
 			InternalServerError(w, err)
 			return
 		}
-		var eventsDto resources.EventsDto
-		Success(w, eventsDto.DomainToDto(events))
-		Ok(w)
+
+		var subscriptionDto resources.SubscriptionDto
+		Success(w, subscriptionDto.DomainToDto(sub))
 	}
 }
-func (c EventController) Subscribe() http.HandlerFunc {
+func (c EventController) Unsubscribe() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ev, ok := r.Context().Value(EventKey).(domain.Event)
 		if !ok {
@@ -120,24 +218,91 @@ func (c EventController) Subscribe() http.HandlerFunc {
 			return
 		}
 		user := r.Context().Value(UserKey).(domain.User)
-		if err := c.eventService.SubscribeToEvent(ev.Id, user.Id); err != nil {
+		err := c.eventService.UnsubscribeFromEvent(ev.Id, user.Id)
+		if err != nil {
+			log.Printf("EventController -> Unsubscribe -> c.eventService.UnsubscribeFromEvent: %s", err)
 			InternalServerError(w, err)
 			return
 		}
 
-		Success(w, "Subscribed successfully")
+		noContent(w)
 	}
 }
+func (c EventController) FindAttendees() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ev, ok := r.Context().Value(EventKey).(domain.Event)
+		if !ok {
+			InternalServerError(w, fmt.Errorf("failed to cast event"))
+			return
+		}
+		user := r.Context().Value(UserKey).(domain.User)
+		if ev.UserId != user.Id && user.Role != domain.AdminRole && !ev.AttendeesPublic {
+			Forbidden(w, fmt.Errorf("attendee list is visible only to the event owner"))
+			return
+		}
+
+		pagination, err := requests.BindPagination(r)
+		if err != nil {
+			BadRequest(w, err)
+			return
+		}
+
+		attendees, total, counts, err := c.eventService.FindAttendees(ev.Id, pagination)
+		if err != nil {
+			log.Printf("EventController -> FindAttendees -> c.eventService.FindAttendees: %s", err)
+			InternalServerError(w, err)
+			return
+		}
+
+		var attendeesDto resources.AttendeesDto
+		Success(w, attendeesDto.DomainToDto(attendees, total, pagination, counts))
+	}
+}
+
+// History lists the revisions of the event, the newest first.
+func (c EventController) History() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ev, ok := r.Context().Value(EventKey).(domain.Event)
+		if !ok {
+			InternalServerError(w, fmt.Errorf("failed to cast event"))
+			return
+		}
+		pagination, err := requests.BindPagination(r)
+		if err != nil {
+			BadRequest(w, err)
+			return
+		}
+
+		revisions, total, err := c.eventService.FindHistory(ev.Id, pagination)
+		if err != nil {
+			InternalServerError(w, err)
+			return
+		}
+
+		paginationHeaders(w, r, pagination, total)
+		var revisionsDto resources.EventRevisionsDto
+		Success(w, revisionsDto.DomainToDto(revisions, total, pagination))
+	}
+}
+
 func (c EventController) GetUserSubscriptions() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		filters, pagination, err := bindListParams(r)
+		if err != nil {
+			BadRequest(w, err)
+			return
+		}
+
 		user := r.Context().Value(UserKey).(domain.User)
-		subscriptions, err := c.eventService.GetUserSubscriptions(user.Id)
+		subscriptions, total, err := c.eventService.GetUserSubscriptions(user.Id, filters, pagination)
 		if err != nil {
 			InternalServerError(w, err)
 			return
 		}
-		var eventsDto resources.EventsDto
-		Success(w, eventsDto.DomainToDto(subscriptions))
+
+		paginationHeaders(w, r, pagination, total)
+		var eventsDto resources.EventsPageDto
+		Success(w, eventsDto.DomainToDto(subscriptions, total, pagination))
 
 	}
 }
@@ -169,63 +334,357 @@ func (c EventController) FindEventsByDate() http.HandlerFunc {
 	}
 }
 
+// FindEventsGroupByDate returns all the events grouped by day, the from-to range and
+// the other list filters narrow them down.
 func (c EventController) FindEventsGroupByDate() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		groupedEvents, err := c.eventService.FindEventsGroupByDate()
+		filters, err := bindUrlFilters(r)
+		if err != nil {
+			BadRequest(w, err)
+			return
+		}
+		loc, err := bindTimeZone(r)
+		if err != nil {
+			BadRequest(w, err)
+			return
+		}
+
+		days, err := c.eventService.FindCalendar(filters, loc, groupedByDatePerDay)
 		if err != nil {
 			InternalServerError(w, err)
 			return
 		}
 
-		filteredGroupedEvents := make(map[string][]resources.EventDto)
+		var total uint64
+		groupedEvents := make(map[string][]resources.EventDto, len(days))
+		for _, day := range days {
+			total += day.Count
+			groupedEvents[day.Date] = resources.EventsDto{}.DomainToDto(day.Events).Events
+		}
+
+		w.Header().Set("X-Total-Count", strconv.FormatUint(total, 10))
+		Success(w, groupedEvents)
+	}
+}
 
-		for date, events := range groupedEvents {
-			var filteredEvents []resources.EventDto
-			for _, event := range events {
-				filteredEvents = append(filteredEvents, resources.EventDto{}.DomainToDto(event))
+// FindCalendar returns per day event counts of the from-to range, in the tz time zone.
+// With mode=expanded the first perDay events of each day are included.
+func (c EventController) FindCalendar() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		filters, err := bindUrlFilters(r)
+		if err != nil {
+			BadRequest(w, err)
+			return
+		}
+		if filters.From == nil || filters.To == nil {
+			BadRequest(w, fmt.Errorf("from and to parameters are required"))
+			return
+		}
+		err = checkCalendarRange(filters)
+		if err != nil {
+			BadRequest(w, err)
+			return
+		}
+		loc, err := bindTimeZone(r)
+		if err != nil {
+			BadRequest(w, err)
+			return
+		}
+
+		var perDay uint
+		switch mode := r.URL.Query().Get("mode"); mode {
+		case "", "counts":
+		case "expanded":
+			perDay = defaultCalendarPerDay
+			if param := r.URL.Query().Get("perDay"); param != "" {
+				value, err := strconv.ParseUint(param, 10, 64)
+				if err != nil || value == 0 || value > maxCalendarPerDay {
+					BadRequest(w, fmt.Errorf("invalid perDay parameter(from 1 to %d)", maxCalendarPerDay))
+					return
+				}
+				perDay = uint(value)
 			}
-			filteredGroupedEvents[date] = filteredEvents
+		default:
+			BadRequest(w, fmt.Errorf("invalid mode parameter(counts or expanded)"))
+			return
 		}
 
-		// Отправляем результат
-		Success(w, filteredGroupedEvents)
+		days, err := c.eventService.FindCalendar(filters, loc, perDay)
+		if err != nil {
+			log.Printf("EventController -> FindCalendar -> c.eventService.FindCalendar: %s", err)
+			InternalServerError(w, err)
+			return
+		}
+
+		var calendarDto resources.EventCalendarDto
+		Success(w, calendarDto.DomainToDto(days, *filters.From, *filters.To, loc))
+	}
+}
+
+func checkCalendarRange(filters database.UrlFilters) error {
+	if filters.To.Sub(*filters.From) > maxCalendarDays*24*time.Hour {
+		return fmt.Errorf("the range must not be longer than %d days", maxCalendarDays)
 	}
+	return nil
 }
+
+// bindTimeZone reads the tz parameter, UTC by default.
+func bindTimeZone(r *http.Request) (*time.Location, error) {
+	tz := r.URL.Query().Get("tz")
+	if tz == "" {
+		return time.UTC, nil
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil || tz == "Local" {
+		return nil, fmt.Errorf("invalid tz parameter, expected IANA time zone name")
+	}
+	return loc, nil
+}
+
 func (c EventController) FindList() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		filters, pagination, err := bindListParams(r)
+		if err != nil {
+			BadRequest(w, err)
+			return
+		}
 
-		search := r.URL.Query().Get("search")
-		location := r.URL.Query().Get("location")
-		dateParam := r.URL.Query().Get("date")
-		city := r.URL.Query().Get("city")
-		var date *time.Time
-		if dateParam != "" {
-			timestamp, err := strconv.ParseInt(dateParam, 10, 64)
-			if err != nil {
-				http.Error(w, "Invalid date format. Use YYYY-MM-DD", http.StatusBadRequest)
+		events, total, err := c.eventService.FindList(filters, pagination)
+		if err != nil {
+			http.Error(w, "Error fetching events", http.StatusInternalServerError)
+			return
+		}
+
+		facets, err := c.eventService.FindFacets(filters)
+		if err != nil {
+			http.Error(w, "Error fetching events", http.StatusInternalServerError)
+			return
+		}
+
+		paginationHeaders(w, r, pagination, total)
+		eventsDto := resources.EventsPageDto{}.DomainToDto(events, total, pagination)
+		facetsDto := resources.EventFacetsDto{}.DomainToDto(facets)
+		eventsDto.Facets = &facetsDto
+		Success(w, eventsDto)
+
+	}
+}
+
+func (c EventController) FindMap() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		filters, err := bindUrlFilters(r)
+		if err != nil {
+			BadRequest(w, err)
+			return
+		}
+		if filters.Bbox == nil {
+			BadRequest(w, fmt.Errorf("missing bbox parameter"))
+			return
+		}
+
+		zoom, err := strconv.ParseUint(r.URL.Query().Get("zoom"), 10, 64)
+		if err != nil || zoom > maxMapZoom {
+			BadRequest(w, fmt.Errorf("invalid zoom parameter(from 0 to %d)", maxMapZoom))
+			return
+		}
+
+		clusters, events, err := c.eventService.FindMap(filters, uint(zoom))
+		if err != nil {
+			log.Printf("EventController -> FindMap -> c.eventService.FindMap: %s", err)
+			InternalServerError(w, err)
+			return
+		}
+
+		var mapDto resources.EventMapDto
+		Success(w, mapDto.DomainToDto(clusters, events))
+	}
+}
+
+func (c EventController) Suggest() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		q := strings.TrimSpace(r.URL.Query().Get("q"))
+		if q == "" || utf8.RuneCountInString(q) > maxSuggestLength {
+			BadRequest(w, fmt.Errorf("invalid q parameter(from 1 to %d characters)", maxSuggestLength))
+			return
+		}
+
+		limit := uint64(defaultSuggestLimit)
+		if limitParam := r.URL.Query().Get("limit"); limitParam != "" {
+			var err error
+			limit, err = strconv.ParseUint(limitParam, 10, 64)
+			if err != nil || limit == 0 || limit > maxSuggestLimit {
+				BadRequest(w, fmt.Errorf("invalid limit parameter(from 1 to %d)", maxSuggestLimit))
 				return
 			}
-			parsedDate := time.Unix(timestamp, 0)
-			date = &parsedDate
 		}
 
-		filters := database.UrlFilters{
-			Search:   search,
-			Location: location,
-			Date:     date,
-			City:     city,
+		suggestions, err := c.eventService.Suggest(q, uint(limit))
+		if err != nil {
+			log.Printf("EventController -> Suggest -> c.eventService.Suggest: %s", err)
+			InternalServerError(w, err)
+			return
+		}
+
+		var suggestionsDto resources.EventSuggestionsDto
+		Success(w, suggestionsDto.DomainToDto(suggestions))
+	}
+}
+
+func bindUrlFilters(r *http.Request) (database.UrlFilters, error) {
+	query := r.URL.Query()
+	filters := database.UrlFilters{
+		Search:   query.Get("search"),
+		Location: query.Get("location"),
+		City:     query.Get("city"),
+	}
+
+	dateParams := []struct {
+		name   string
+		target **time.Time
+	}{{"date", &filters.Date}, {"from", &filters.From}, {"to", &filters.To}}
+	for _, param := range dateParams {
+		value := query.Get(param.name)
+		if value == "" {
+			continue
+		}
+		timestamp, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return database.UrlFilters{}, fmt.Errorf("invalid %s format, expected Unix timestamp", param.name)
 		}
+		t := time.Unix(timestamp, 0)
+		*param.target = &t
+	}
+	if filters.From != nil && filters.To != nil && filters.To.Before(*filters.From) {
+		return database.UrlFilters{}, fmt.Errorf("to must not be before from")
+	}
+
+	if status := domain.EventStatus(query.Get("status")); status != "" {
+		if status != domain.NewEventStatus && status != domain.DoneEventStatus && status != domain.CancelledEventStatus {
+			return database.UrlFilters{}, fmt.Errorf("invalid status parameter(NEW, DONE or CANCELLED)")
+		}
+		filters.Status = status
+	}
+
+	if category := query.Get("category"); category != "" {
+		categoryId, err := strconv.ParseUint(category, 10, 64)
+		if err != nil || categoryId == 0 {
+			return database.UrlFilters{}, fmt.Errorf("invalid category parameter, expected category id")
+		}
+		filters.CategoryId = categoryId
+	}
 
-		events, err := c.eventService.FindList(filters)
+	if tags := query.Get("tags"); tags != "" {
+		filters.Tags = app.NormalizeTags(strings.Split(tags, ","))
+	}
+
+	if owner := query.Get("owner"); owner != "" {
+		ownerId, err := strconv.ParseUint(owner, 10, 64)
+		if err != nil || ownerId == 0 {
+			return database.UrlFilters{}, fmt.Errorf("invalid owner parameter, expected user id")
+		}
+		filters.OwnerId = ownerId
+	}
+
+	if sort := query.Get("sort"); sort != "" {
+		if !database.IsValidEventSort(sort) {
+			return database.UrlFilters{}, fmt.Errorf("invalid sort parameter(date, created or title, prefixed with - for descending order)")
+		}
+		filters.Sort = sort
+	}
+
+	err := bindGeoFilters(r, &filters)
+	if err != nil {
+		return database.UrlFilters{}, err
+	}
+
+	return filters, nil
+}
+
+// isEventInputError tells the errors of the event service caused by the request data.
+func isEventInputError(err error) bool {
+	return errors.Is(err, app.ErrCategoryNotFound) ||
+		errors.Is(err, app.ErrTooManyTags) ||
+		errors.Is(err, app.ErrVenueNotFound) ||
+		errors.Is(err, app.ErrLocationNotFound)
+}
+
+func sameId(a, b *uint64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func bindListParams(r *http.Request) (database.UrlFilters, domain.Pagination, error) {
+	filters, err := bindUrlFilters(r)
+	if err != nil {
+		return database.UrlFilters{}, domain.Pagination{}, err
+	}
+
+	pagination, err := requests.BindPagination(r)
+	if err != nil {
+		return database.UrlFilters{}, domain.Pagination{}, err
+	}
+
+	return filters, pagination, nil
+}
+
+func (c EventController) Import() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		r.Body = http.MaxBytesReader(w, r.Body, maxImportSize)
+		file, _, err := r.FormFile("file")
 		if err != nil {
-			http.Error(w, "Error fetching events", http.StatusInternalServerError)
+			BadRequest(w, fmt.Errorf("failed to get the file"))
 			return
 		}
+		defer file.Close()
 
-		var eventsDto resources.EventsDto
-		Success(w, eventsDto.DomainToDto(events))
+		imports, err := ical.Decode(file)
+		if err != nil {
+			log.Printf("EventController -> Import -> ical.Decode: %s", err)
+			BadRequest(w, err)
+			return
+		}
 
+		user := r.Context().Value(UserKey).(domain.User)
+		results := c.eventService.ImportEvents(user.Id, imports)
+
+		var reportDto resources.EventImportReportDto
+		Success(w, reportDto.DomainToDto(results))
+	}
+}
+
+func bindGeoFilters(r *http.Request, filters *database.UrlFilters) error {
+	query := r.URL.Query()
+	lat, lon, radius := query.Get("lat"), query.Get("lon"), query.Get("radiusKm")
+
+	if lat != "" || lon != "" {
+		point, err := requests.ParseGeoPoint(lat, lon)
+		if err != nil {
+			return err
+		}
+		filters.Point = &point
+
+		filters.RadiusKm = defaultRadiusKm
+		if radius != "" {
+			filters.RadiusKm, err = strconv.ParseFloat(radius, 64)
+			if err != nil || filters.RadiusKm <= 0 || filters.RadiusKm > maxRadiusKm {
+				return fmt.Errorf("invalid radiusKm parameter(from 0 to %d)", maxRadiusKm)
+			}
+		}
+	} else if radius != "" {
+		return fmt.Errorf("radiusKm parameter requires lat and lon")
+	}
+
+	if bbox := query.Get("bbox"); bbox != "" {
+		box, err := requests.ParseBoundingBox(bbox)
+		if err != nil {
+			return err
+		}
+		filters.Bbox = &box
 	}
+
+	return nil
 }
 
 func (c EventController) SaveImage() http.HandlerFunc {
@@ -257,6 +716,7 @@ func (c EventController) SaveImage() http.HandlerFunc {
 		}
 
 		ev.Image = filename
+		ev.UpdatedBy = r.Context().Value(UserKey).(domain.User).Id
 		updatedEvent, err := c.eventService.Update(ev)
 		if err != nil {
 			log.Printf("EventController -> UploadImage -> Update: %s", err)
@@ -296,6 +756,7 @@ func (c EventController) DeleteImage() http.HandlerFunc {
 				return
 			}
 			ev.Image = ""
+			ev.UpdatedBy = r.Context().Value(UserKey).(domain.User).Id
 			_, err = c.eventService.Update(ev)
 			if err != nil {
 				log.Printf("Failed to update event after image deletion: %s", err)
@@ -347,6 +808,7 @@ func (c EventController) UpdateImage() http.HandlerFunc {
 		}
 
 		ev.Image = newFilename
+		ev.UpdatedBy = r.Context().Value(UserKey).(domain.User).Id
 		updatedEvent, err := c.eventService.Update(ev)
 		if err != nil {
 			log.Printf("EventController -> SaveImage -> Update: %s", err)
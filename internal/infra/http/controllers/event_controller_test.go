@@ -0,0 +1,110 @@
+package controllers
+
+import (
+	"context"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/app"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type fakeEventService struct {
+	app.EventService
+	updated []domain.Event
+}
+
+func (s *fakeEventService) Update(e domain.Event) (domain.Event, error) {
+	s.updated = append(s.updated, e)
+	return e, nil
+}
+
+func TestEventController_Update(t *testing.T) {
+	venueId, categoryId, capacity := uint64(3), uint64(4), uint64(50)
+	endDate := time.Date(2030, 5, 10, 22, 0, 0, 0, time.UTC)
+	stored := domain.Event{
+		Id:              1,
+		UserId:          10,
+		Title:           "Concert",
+		Description:     "Live music",
+		Status:          domain.NewEventStatus,
+		Date:            time.Date(2030, 5, 10, 18, 0, 0, 0, time.UTC),
+		EndDate:         &endDate,
+		VenueId:         &venueId,
+		CategoryId:      &categoryId,
+		Capacity:        &capacity,
+		AttendeesPublic: true,
+		Location:        "Old Hall",
+		City:            "Kyiv",
+		Lat:             50.45,
+		Lon:             30.52,
+	}
+	const details = `"title": "Concert", "description": "Live music", "image": "concert.png"`
+
+	update := func(user domain.User, body string) (*httptest.ResponseRecorder, *fakeEventService) {
+		service := &fakeEventService{}
+		ctx := context.WithValue(context.Background(), EventKey, stored)
+		ctx = context.WithValue(ctx, UserKey, user)
+		req := httptest.NewRequest(http.MethodPut, "/events/1", strings.NewReader(body)).WithContext(ctx)
+		w := httptest.NewRecorder()
+		NewEventController(service, nil).Update()(w, req)
+		return w, service
+	}
+
+	t.Run("someone else's event", func(t *testing.T) {
+		w, service := update(domain.User{Id: 11, Role: domain.CustomerRole}, `{`+details+`}`)
+		if w.Code != http.StatusForbidden || len(service.updated) != 0 {
+			t.Errorf("status = %d, updated = %d, want %d and no update", w.Code, len(service.updated), http.StatusForbidden)
+		}
+	})
+
+	t.Run("absent fields are kept", func(t *testing.T) {
+		w, service := update(domain.User{Id: 10}, `{`+details+`}`)
+		if w.Code != http.StatusOK || len(service.updated) != 1 {
+			t.Fatalf("status = %d, updated = %d", w.Code, len(service.updated))
+		}
+		e := service.updated[0]
+		if !e.Date.Equal(stored.Date) || e.EndDate == nil || !e.EndDate.Equal(endDate) {
+			t.Errorf("dates = %s - %v, want %s - %s", e.Date, e.EndDate, stored.Date, endDate)
+		}
+		if e.Capacity == nil || *e.Capacity != capacity || e.CategoryId == nil || e.VenueId == nil || !e.AttendeesPublic {
+			t.Errorf("event = %+v, want the stored capacity, category, venue and attendees visibility", e)
+		}
+		if e.Location != "Old Hall" || e.City != "Kyiv" || e.Lat != 50.45 || e.Lon != 30.52 {
+			t.Errorf("place = %s, %s (%g, %g), want the stored one", e.Location, e.City, e.Lat, e.Lon)
+		}
+	})
+
+	t.Run("null clears", func(t *testing.T) {
+		_, service := update(domain.User{Id: 10}, `{`+details+`, "capacity": null, "endDate": null}`)
+		if len(service.updated) != 1 {
+			t.Fatalf("updated = %d", len(service.updated))
+		}
+		if e := service.updated[0]; e.Capacity != nil || e.EndDate != nil {
+			t.Errorf("capacity = %v, endDate = %v, want both cleared", e.Capacity, e.EndDate)
+		}
+	})
+
+	t.Run("a new place is geocoded", func(t *testing.T) {
+		_, service := update(domain.User{Id: 1, Role: domain.AdminRole}, `{`+details+`, "venueId": null, "location": "Arena", "city": "Lviv"}`)
+		if len(service.updated) != 1 {
+			t.Fatalf("updated = %d", len(service.updated))
+		}
+		e := service.updated[0]
+		if e.Location != "Arena" || e.City != "Lviv" || e.Lat != 0 || e.Lon != 0 {
+			t.Errorf("place = %s, %s (%g, %g), want Arena, Lviv without coordinates", e.Location, e.City, e.Lat, e.Lon)
+		}
+		if e.UpdatedBy != 1 {
+			t.Errorf("UpdatedBy = %d, want 1", e.UpdatedBy)
+		}
+	})
+
+	t.Run("end date before the date", func(t *testing.T) {
+		w, service := update(domain.User{Id: 10}, `{`+details+`, "endDate": 1000}`)
+		if w.Code != http.StatusBadRequest || len(service.updated) != 0 {
+			t.Errorf("status = %d, updated = %d, want %d", w.Code, len(service.updated), http.StatusBadRequest)
+		}
+	})
+}
@@ -0,0 +1,172 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/app"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/database"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/http/requests"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/http/resources"
+	"log"
+	"net/http"
+)
+
+type NotificationController struct {
+	notificationService app.NotificationService
+}
+
+func NewNotificationController(ns app.NotificationService) NotificationController {
+	return NotificationController{
+		notificationService: ns,
+	}
+}
+
+// FindList returns the user's notifications, the newest first. With unread=true only
+// the unread ones are listed.
+func (c NotificationController) FindList() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		pagination, err := requests.BindPagination(r)
+		if err != nil {
+			BadRequest(w, err)
+			return
+		}
+		user := r.Context().Value(UserKey).(domain.User)
+
+		filters := database.NotificationFilters{UserId: user.Id}
+		switch r.URL.Query().Get("unread") {
+		case "", "false":
+		case "true":
+			filters.UnreadOnly = true
+		default:
+			BadRequest(w, fmt.Errorf("invalid unread parameter(true or false)"))
+			return
+		}
+
+		notifications, total, err := c.notificationService.FindList(filters, pagination)
+		if err != nil {
+			InternalServerError(w, err)
+			return
+		}
+
+		paginationHeaders(w, r, pagination, total)
+		var notificationsDto resources.NotificationsDto
+		Success(w, notificationsDto.DomainToDto(notifications, total, pagination))
+	}
+}
+
+func (c NotificationController) CountUnread() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := r.Context().Value(UserKey).(domain.User)
+
+		count, err := c.notificationService.CountUnread(user.Id)
+		if err != nil {
+			InternalServerError(w, err)
+			return
+		}
+
+		Success(w, resources.UnreadCountDto{Count: count})
+	}
+}
+
+func (c NotificationController) MarkRead() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		notification, ok := c.ownNotification(w, r)
+		if !ok {
+			return
+		}
+
+		err := c.notificationService.MarkRead(notification.UserId, notification.Id)
+		if err != nil {
+			InternalServerError(w, err)
+			return
+		}
+
+		noContent(w)
+	}
+}
+
+func (c NotificationController) MarkAllRead() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := r.Context().Value(UserKey).(domain.User)
+
+		err := c.notificationService.MarkAllRead(user.Id)
+		if err != nil {
+			InternalServerError(w, err)
+			return
+		}
+
+		noContent(w)
+	}
+}
+
+func (c NotificationController) Delete() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		notification, ok := c.ownNotification(w, r)
+		if !ok {
+			return
+		}
+
+		err := c.notificationService.Delete(notification.Id)
+		if err != nil {
+			InternalServerError(w, err)
+			return
+		}
+
+		noContent(w)
+	}
+}
+
+func (c NotificationController) FindPreferences() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := r.Context().Value(UserKey).(domain.User)
+
+		prefs, err := c.notificationService.FindPreferences(user.Id)
+		if err != nil {
+			InternalServerError(w, err)
+			return
+		}
+
+		var prefsDto resources.NotificationPreferencesDto
+		Success(w, prefsDto.DomainToDto(prefs))
+	}
+}
+
+func (c NotificationController) UpdatePreferences() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		prefs, err := requests.Bind(r, requests.NotificationPreferencesRequest{}, []domain.NotificationPreference{})
+		if err != nil {
+			log.Printf("NotificationController -> UpdatePreferences -> requests.Bind: %s", err)
+			BadRequest(w, err)
+			return
+		}
+		user := r.Context().Value(UserKey).(domain.User)
+
+		prefs, err = c.notificationService.UpdatePreferences(user.Id, prefs)
+		if errors.Is(err, app.ErrUnknownNotificationType) {
+			BadRequest(w, err)
+			return
+		} else if err != nil {
+			InternalServerError(w, err)
+			return
+		}
+
+		var prefsDto resources.NotificationPreferencesDto
+		Success(w, prefsDto.DomainToDto(prefs))
+	}
+}
+
+// ownNotification returns the notification of the path if it was sent to the user.
+func (c NotificationController) ownNotification(w http.ResponseWriter, r *http.Request) (domain.Notification, bool) {
+	notification, ok := r.Context().Value(NotificationKey).(domain.Notification)
+	if !ok {
+		InternalServerError(w, fmt.Errorf("failed to cast notification"))
+		return domain.Notification{}, false
+	}
+	user := r.Context().Value(UserKey).(domain.User)
+	if notification.UserId != user.Id {
+		Forbidden(w, fmt.Errorf("the notification was sent to another user"))
+		return domain.Notification{}, false
+	}
+	return notification, true
+}
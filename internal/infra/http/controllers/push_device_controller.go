@@ -0,0 +1,92 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/app"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/http/requests"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/http/resources"
+	"log"
+	"net/http"
+)
+
+type PushDeviceController struct {
+	pushService app.PushService
+}
+
+func NewPushDeviceController(ps app.PushService) PushDeviceController {
+	return PushDeviceController{
+		pushService: ps,
+	}
+}
+
+// Register registers the device for the current session, it is unregistered on logout.
+// Without a locale the user's one is taken.
+func (c PushDeviceController) Register() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		device, err := requests.Bind(r, requests.PushDeviceRequest{}, domain.PushDevice{})
+		if err != nil {
+			log.Printf("PushDeviceController -> Register -> requests.Bind: %s", err)
+			BadRequest(w, err)
+			return
+		}
+		user := r.Context().Value(UserKey).(domain.User)
+		sess := r.Context().Value(SessKey).(domain.Session)
+
+		device.UserId, device.SessionUUID = sess.UserId, sess.UUID
+		if device.Locale == "" {
+			device.Locale = user.Locale
+		}
+		device, err = c.pushService.Register(device)
+		if errors.Is(err, app.ErrPushPlatformNotSupported) {
+			BadRequest(w, err)
+			return
+		} else if err != nil {
+			InternalServerError(w, err)
+			return
+		}
+
+		var deviceDto resources.PushDeviceDto
+		Created(w, deviceDto.DomainToDto(device))
+	}
+}
+
+// FindMine returns the devices registered for the current session.
+func (c PushDeviceController) FindMine() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		sess := r.Context().Value(SessKey).(domain.Session)
+
+		devices, err := c.pushService.FindBySession(sess)
+		if err != nil {
+			InternalServerError(w, err)
+			return
+		}
+
+		var devicesDto resources.PushDevicesDto
+		Success(w, devicesDto.DomainToDto(devices))
+	}
+}
+
+func (c PushDeviceController) Unregister() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		device, ok := r.Context().Value(PushDeviceKey).(domain.PushDevice)
+		if !ok {
+			InternalServerError(w, fmt.Errorf("failed to cast push device"))
+			return
+		}
+		user := r.Context().Value(UserKey).(domain.User)
+		if device.UserId != user.Id {
+			Forbidden(w, fmt.Errorf("the device is registered by another user"))
+			return
+		}
+
+		err := c.pushService.Unregister(device.Id)
+		if err != nil {
+			InternalServerError(w, err)
+			return
+		}
+
+		noContent(w)
+	}
+}
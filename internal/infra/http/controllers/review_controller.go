@@ -0,0 +1,187 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/app"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/http/requests"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/http/resources"
+	"github.com/go-chi/chi/v5"
+	"log"
+	"net/http"
+	"strconv"
+)
+
+type ReviewController struct {
+	reviewService app.ReviewService
+}
+
+func NewReviewController(rs app.ReviewService) ReviewController {
+	return ReviewController{
+		reviewService: rs,
+	}
+}
+
+func (c ReviewController) Save() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		review, err := requests.Bind(r, requests.ReviewRequest{}, domain.Review{})
+		if err != nil {
+			log.Printf("ReviewController -> Save -> requests.Bind: %s", err)
+			BadRequest(w, err)
+			return
+		}
+		ev, ok := r.Context().Value(EventKey).(domain.Event)
+		if !ok {
+			InternalServerError(w, fmt.Errorf("failed to cast event"))
+			return
+		}
+		user := r.Context().Value(UserKey).(domain.User)
+
+		review.UserId = user.Id
+		review, err = c.reviewService.Save(ev, review)
+		if err != nil {
+			reviewError(w, err)
+			return
+		}
+
+		var reviewDto resources.ReviewDto
+		Created(w, reviewDto.DomainToDto(review))
+	}
+}
+
+// FindByEvent lists the reviews of the event, the newest first.
+func (c ReviewController) FindByEvent() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ev, ok := r.Context().Value(EventKey).(domain.Event)
+		if !ok {
+			InternalServerError(w, fmt.Errorf("failed to cast event"))
+			return
+		}
+		pagination, err := requests.BindPagination(r)
+		if err != nil {
+			BadRequest(w, err)
+			return
+		}
+
+		reviews, total, err := c.reviewService.FindByEvent(ev.Id, pagination)
+		if err != nil {
+			InternalServerError(w, err)
+			return
+		}
+
+		paginationHeaders(w, r, pagination, total)
+		var reviewsDto resources.ReviewsDto
+		Success(w, reviewsDto.DomainToDto(reviews, total, pagination))
+	}
+}
+
+func (c ReviewController) Update() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		review, ok := c.pathReview(w, r)
+		if !ok {
+			return
+		}
+		user := r.Context().Value(UserKey).(domain.User)
+		if review.UserId != user.Id {
+			Forbidden(w, fmt.Errorf("only the author can edit the review"))
+			return
+		}
+
+		req, err := requests.Bind(r, requests.ReviewRequest{}, domain.Review{})
+		if err != nil {
+			log.Printf("ReviewController -> Update -> requests.Bind: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		review.Rating = req.Rating
+		review.Body = req.Body
+		review, err = c.reviewService.Update(review)
+		if err != nil {
+			reviewError(w, err)
+			return
+		}
+
+		var reviewDto resources.ReviewDto
+		Success(w, reviewDto.DomainToDto(review))
+	}
+}
+
+// Reply lets the organizer answer the review, once.
+func (c ReviewController) Reply() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		review, ok := c.pathReview(w, r)
+		if !ok {
+			return
+		}
+		user := r.Context().Value(UserKey).(domain.User)
+
+		organizer, err := c.reviewService.IsOrganizer(review, user)
+		if err != nil {
+			InternalServerError(w, err)
+			return
+		}
+		if !organizer {
+			Forbidden(w, fmt.Errorf("only the organizer can reply to the review"))
+			return
+		}
+
+		reply, err := requests.Bind(r, requests.ReviewReplyRequest{}, "")
+		if err != nil {
+			log.Printf("ReviewController -> Reply -> requests.Bind: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		review, err = c.reviewService.Reply(review, reply)
+		if err != nil {
+			reviewError(w, err)
+			return
+		}
+
+		var reviewDto resources.ReviewDto
+		Success(w, reviewDto.DomainToDto(review))
+	}
+}
+
+// FindOrganizerRating returns the aggregate rating of all the events of the user.
+func (c ReviewController) FindOrganizerRating() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userId, err := strconv.ParseUint(chi.URLParam(r, "userId"), 10, 64)
+		if err != nil {
+			BadRequest(w, fmt.Errorf("invalid user id"))
+			return
+		}
+
+		rating, err := c.reviewService.FindOrganizerRating(userId)
+		if err != nil {
+			InternalServerError(w, err)
+			return
+		}
+
+		var ratingDto resources.RatingDto
+		Success(w, ratingDto.DomainToDto(rating))
+	}
+}
+
+func (c ReviewController) pathReview(w http.ResponseWriter, r *http.Request) (domain.Review, bool) {
+	review, ok := r.Context().Value(ReviewKey).(domain.Review)
+	if !ok {
+		InternalServerError(w, fmt.Errorf("failed to cast review"))
+		return domain.Review{}, false
+	}
+	return review, true
+}
+
+func reviewError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, app.ErrNotAttendee), errors.Is(err, app.ErrOwnEventReview):
+		Forbidden(w, err)
+	case errors.Is(err, app.ErrEventNotDone), errors.Is(err, app.ErrAlreadyReviewed),
+		errors.Is(err, app.ErrReviewEditClosed), errors.Is(err, app.ErrReviewAlreadyReplied):
+		Conflict(w, err)
+	default:
+		InternalServerError(w, err)
+	}
+}
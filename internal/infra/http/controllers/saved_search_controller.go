@@ -0,0 +1,179 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/app"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/http/requests"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/http/resources"
+	"log"
+	"net/http"
+	"time"
+)
+
+type SavedSearchController struct {
+	savedSearchService app.SavedSearchService
+	eventService       app.EventService
+}
+
+func NewSavedSearchController(ss app.SavedSearchService, es app.EventService) SavedSearchController {
+	return SavedSearchController{
+		savedSearchService: ss,
+		eventService:       es,
+	}
+}
+
+func (c SavedSearchController) Save() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		search, err := requests.Bind(r, requests.SavedSearchRequest{}, domain.SavedSearch{})
+		if err != nil {
+			log.Printf("SavedSearchController -> Save -> requests.Bind: %s", err)
+			BadRequest(w, err)
+			return
+		}
+		user := r.Context().Value(UserKey).(domain.User)
+
+		search.UserId = user.Id
+		search, err = c.savedSearchService.Save(search)
+		if err != nil {
+			savedSearchError(w, err)
+			return
+		}
+
+		var searchDto resources.SavedSearchDto
+		Created(w, searchDto.DomainToDto(search))
+	}
+}
+
+func (c SavedSearchController) Update() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req, err := requests.Bind(r, requests.SavedSearchRequest{}, domain.SavedSearch{})
+		if err != nil {
+			log.Printf("SavedSearchController -> Update -> requests.Bind: %s", err)
+			BadRequest(w, err)
+			return
+		}
+		search, ok := c.ownSavedSearch(w, r)
+		if !ok {
+			return
+		}
+
+		search.Name = req.Name
+		search.Search = req.Search
+		search.City = req.City
+		search.Point, search.RadiusKm = req.Point, req.RadiusKm
+		search.CategoryId = req.CategoryId
+		search.Channel = req.Channel
+		search.Frequency = req.Frequency
+		search, err = c.savedSearchService.Update(search)
+		if err != nil {
+			savedSearchError(w, err)
+			return
+		}
+
+		var searchDto resources.SavedSearchDto
+		Success(w, searchDto.DomainToDto(search))
+	}
+}
+
+func (c SavedSearchController) FindMine() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := r.Context().Value(UserKey).(domain.User)
+
+		searches, err := c.savedSearchService.FindByUser(user.Id)
+		if err != nil {
+			InternalServerError(w, err)
+			return
+		}
+
+		var searchesDto resources.SavedSearchesDto
+		Success(w, searchesDto.DomainToDto(searches))
+	}
+}
+
+func (c SavedSearchController) Find() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		search, ok := c.ownSavedSearch(w, r)
+		if !ok {
+			return
+		}
+
+		var searchDto resources.SavedSearchDto
+		Success(w, searchDto.DomainToDto(search))
+	}
+}
+
+func (c SavedSearchController) Delete() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		search, ok := c.ownSavedSearch(w, r)
+		if !ok {
+			return
+		}
+
+		err := c.savedSearchService.Delete(search.Id)
+		if err != nil {
+			InternalServerError(w, err)
+			return
+		}
+
+		noContent(w)
+	}
+}
+
+// FindEvents runs the saved search now over the upcoming events, paginated as /events/findList.
+func (c SavedSearchController) FindEvents() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		search, ok := c.ownSavedSearch(w, r)
+		if !ok {
+			return
+		}
+
+		pagination, err := requests.BindPagination(r)
+		if err != nil {
+			BadRequest(w, err)
+			return
+		}
+		filters := app.SavedSearchFilters(search)
+		now := time.Now()
+		filters.Status, filters.From = domain.NewEventStatus, &now
+
+		events, total, err := c.eventService.FindList(filters, pagination)
+		if err != nil {
+			log.Printf("SavedSearchController -> FindEvents -> c.eventService.FindList: %s", err)
+			InternalServerError(w, err)
+			return
+		}
+
+		paginationHeaders(w, r, pagination, total)
+		var eventsDto resources.EventsPageDto
+		Success(w, eventsDto.DomainToDto(events, total, pagination))
+	}
+}
+
+// ownSavedSearch returns the saved search of the path if it belongs to the user.
+func (c SavedSearchController) ownSavedSearch(w http.ResponseWriter, r *http.Request) (domain.SavedSearch, bool) {
+	search, ok := r.Context().Value(SavedSearchKey).(domain.SavedSearch)
+	if !ok {
+		InternalServerError(w, fmt.Errorf("failed to cast saved search"))
+		return domain.SavedSearch{}, false
+	}
+	user := r.Context().Value(UserKey).(domain.User)
+	if search.UserId != user.Id {
+		Forbidden(w, fmt.Errorf("the saved search belongs to another user"))
+		return domain.SavedSearch{}, false
+	}
+	return search, true
+}
+
+func savedSearchError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, app.ErrTooManySavedSearches):
+		Conflict(w, err)
+	case errors.Is(err, app.ErrEmptySavedSearch), errors.Is(err, app.ErrRadiusWithoutPoint), errors.Is(err, app.ErrCategoryNotFound):
+		BadRequest(w, err)
+	default:
+		log.Printf("SavedSearchController: %s", err)
+		InternalServerError(w, err)
+	}
+}
@@ -0,0 +1,127 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/app"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// streamKeepAlive keeps the proxies from closing an idle stream
+	streamKeepAlive = 25 * time.Second
+	// streamRetry is how long the clients wait before reconnecting, in milliseconds
+	streamRetry = 3000
+)
+
+type StreamController struct {
+	streamService app.StreamService
+	streamHub     app.StreamHub
+}
+
+func NewStreamController(ss app.StreamService, sh app.StreamHub) StreamController {
+	return StreamController{
+		streamService: ss,
+		streamHub:     sh,
+	}
+}
+
+// Stream sends the user's events as server-sent events. A client reconnecting with
+// Last-Event-ID (or lastEventId in the query) receives the events it has missed first,
+// otherwise only the new ones are sent.
+func (c StreamController) Stream() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			InternalServerError(w, errors.New("streaming is not supported"))
+			return
+		}
+		user := r.Context().Value(UserKey).(domain.User)
+
+		lastId := r.Header.Get("Last-Event-ID")
+		if lastId == "" {
+			lastId = r.URL.Query().Get("lastEventId")
+		}
+
+		// subscribed before the last id is taken, so nothing saved in between is missed
+		wake, cancel := c.streamHub.Subscribe(user.Id)
+		defer cancel()
+
+		var afterId uint64
+		var err error
+		if lastId != "" {
+			afterId, err = strconv.ParseUint(lastId, 10, 64)
+			if err != nil {
+				BadRequest(w, fmt.Errorf("invalid last event id"))
+				return
+			}
+		} else {
+			afterId, err = c.streamService.FindLastId(user.Id)
+			if err != nil {
+				InternalServerError(w, err)
+				return
+			}
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.Header().Set("X-Accel-Buffering", "no")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, "retry: %d\n\n", streamRetry)
+		flusher.Flush()
+
+		keepAlive := time.NewTicker(streamKeepAlive)
+		defer keepAlive.Stop()
+
+		for {
+			afterId, err = c.sendAfter(w, user.Id, afterId)
+			if err != nil {
+				// the client reconnects and resumes from the last event it has received
+				return
+			}
+			flusher.Flush()
+
+			select {
+			case <-r.Context().Done():
+				return
+			case _, ok := <-wake:
+				if !ok {
+					return
+				}
+			case <-keepAlive.C:
+				_, err = fmt.Fprint(w, ": keep-alive\n\n")
+				if err != nil {
+					return
+				}
+				flusher.Flush()
+			}
+		}
+	}
+}
+
+// sendAfter writes all the user's events following the given one and returns the id of
+// the last of them.
+func (c StreamController) sendAfter(w http.ResponseWriter, userId, afterId uint64) (uint64, error) {
+	for {
+		events, err := c.streamService.FindAfter(userId, afterId)
+		if err != nil {
+			return afterId, err
+		}
+		if len(events) == 0 {
+			return afterId, nil
+		}
+
+		for _, e := range events {
+			_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", e.Id, e.Type, strings.ReplaceAll(e.Data, "\n", "\ndata: "))
+			if err != nil {
+				return afterId, err
+			}
+			afterId = e.Id
+		}
+	}
+}
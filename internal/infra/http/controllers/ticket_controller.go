@@ -0,0 +1,134 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/app"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/http/requests"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/http/resources"
+	"github.com/skip2/go-qrcode"
+	"github.com/upper/db/v4"
+	"log"
+	"net/http"
+)
+
+const ticketQrSize = 512
+
+type TicketController struct {
+	ticketService app.TicketService
+}
+
+func NewTicketController(ts app.TicketService) TicketController {
+	return TicketController{
+		ticketService: ts,
+	}
+}
+
+// FindMyTicket responds with the ticket QR code as PNG, or with the ticket itself for ?format=json.
+func (c TicketController) FindMyTicket() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ev, ok := r.Context().Value(EventKey).(domain.Event)
+		if !ok {
+			InternalServerError(w, fmt.Errorf("failed to cast event"))
+			return
+		}
+		user := r.Context().Value(UserKey).(domain.User)
+
+		t, err := c.ticketService.FindForUser(ev.Id, user.Id)
+		if err != nil {
+			if errors.Is(err, db.ErrNoMoreRows) {
+				NotFound(w, fmt.Errorf("you have no ticket for this event"))
+				return
+			}
+			log.Printf("TicketController -> FindMyTicket -> c.ticketService.FindForUser: %s", err)
+			InternalServerError(w, err)
+			return
+		}
+
+		if r.URL.Query().Get("format") == "json" {
+			var ticketDto resources.TicketDto
+			Success(w, ticketDto.DomainToDto(t))
+			return
+		}
+
+		png, err := qrcode.Encode(t.Code, qrcode.Medium, ticketQrSize)
+		if err != nil {
+			log.Printf("TicketController -> FindMyTicket -> qrcode.Encode: %s", err)
+			InternalServerError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "image/png")
+		w.Header().Set("Cache-Control", "private, no-store")
+		w.WriteHeader(http.StatusOK)
+		_, err = w.Write(png)
+		if err != nil {
+			log.Print(err)
+		}
+	}
+}
+
+func (c TicketController) CheckIn() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ev, ok := r.Context().Value(EventKey).(domain.Event)
+		if !ok {
+			InternalServerError(w, fmt.Errorf("failed to cast event"))
+			return
+		}
+		user := r.Context().Value(UserKey).(domain.User)
+		if ev.UserId != user.Id {
+			Forbidden(w, fmt.Errorf("only the event owner can check attendees in"))
+			return
+		}
+
+		req, err := requests.Bind(r, requests.CheckInRequest{}, domain.Ticket{})
+		if err != nil {
+			log.Printf("TicketController -> CheckIn -> requests.Bind: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		t, err := c.ticketService.CheckIn(ev.Id, req.Code, user.Id)
+		if err != nil {
+			switch {
+			case errors.Is(err, app.ErrInvalidTicket):
+				BadRequest(w, err)
+			case errors.Is(err, app.ErrTicketAlreadyCheckedIn):
+				Conflict(w, err)
+			default:
+				log.Printf("TicketController -> CheckIn -> c.ticketService.CheckIn: %s", err)
+				InternalServerError(w, err)
+			}
+			return
+		}
+
+		var ticketDto resources.TicketDto
+		Success(w, ticketDto.DomainToDto(t))
+	}
+}
+
+func (c TicketController) Stats() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ev, ok := r.Context().Value(EventKey).(domain.Event)
+		if !ok {
+			InternalServerError(w, fmt.Errorf("failed to cast event"))
+			return
+		}
+		user := r.Context().Value(UserKey).(domain.User)
+		if ev.UserId != user.Id && user.Role != domain.AdminRole {
+			Forbidden(w, fmt.Errorf("check-in stats are visible only to the event owner"))
+			return
+		}
+
+		stats, err := c.ticketService.Stats(ev.Id)
+		if err != nil {
+			log.Printf("TicketController -> Stats -> c.ticketService.Stats: %s", err)
+			InternalServerError(w, err)
+			return
+		}
+
+		var statsDto resources.CheckInStatsDto
+		Success(w, statsDto.DomainToDto(stats))
+	}
+}
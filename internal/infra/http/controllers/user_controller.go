@@ -47,6 +47,9 @@ func (c UserController) Update() http.HandlerFunc {
 		u.FirstName = user.FirstName
 		u.SecondName = user.SecondName
 		u.Email = user.Email
+		if user.Locale != "" {
+			u.Locale = user.Locale
+		}
 		user, err = c.userService.Update(u)
 		if err != nil {
 			log.Printf("UserController: %s", err)
@@ -0,0 +1,278 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/app"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/database"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/filesystem"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/http/requests"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/http/resources"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/upper/db/v4"
+	"io"
+	"log"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const maxVenuePhotoSize = 10 << 20
+
+var venuePhotoExtensions = map[string]bool{".jpg": true, ".jpeg": true, ".png": true, ".webp": true}
+
+type VenueController struct {
+	venueService app.VenueService
+	eventService app.EventService
+	imageService filesystem.ImageStorageService
+}
+
+func NewVenueController(vs app.VenueService, es app.EventService, is filesystem.ImageStorageService) VenueController {
+	return VenueController{
+		venueService: vs,
+		eventService: es,
+		imageService: is,
+	}
+}
+
+func (c VenueController) Save() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		venue, err := requests.Bind(r, requests.VenueRequest{}, domain.Venue{})
+		if err != nil {
+			log.Printf("VenueController -> Save -> requests.Bind: %s", err)
+			BadRequest(w, err)
+			return
+		}
+		user := r.Context().Value(UserKey).(domain.User)
+
+		venue.UserId = user.Id
+		venue, err = c.venueService.Save(venue)
+		if err != nil {
+			InternalServerError(w, err)
+			return
+		}
+
+		var venueDto resources.VenueDto
+		Created(w, venueDto.DomainToDto(venue))
+	}
+}
+
+func (c VenueController) Update() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req, err := requests.Bind(r, requests.VenueRequest{}, domain.Venue{})
+		if err != nil {
+			log.Printf("VenueController -> Update -> requests.Bind: %s", err)
+			BadRequest(w, err)
+			return
+		}
+		venue, ok := c.editableVenue(w, r)
+		if !ok {
+			return
+		}
+
+		venue.Name = req.Name
+		venue.Address = req.Address
+		venue.City = req.City
+		venue.Lat, venue.Lon = req.Lat, req.Lon
+		venue.Capacity = req.Capacity
+		venue, err = c.venueService.Update(venue)
+		if err != nil {
+			InternalServerError(w, err)
+			return
+		}
+
+		// the venue is saved already, the events that couldn't follow it are listed
+		user := r.Context().Value(UserKey).(domain.User)
+		notRelocated, err := c.eventService.RelocateVenueEvents(venue, user.Id)
+		if err != nil {
+			InternalServerError(w, err)
+			return
+		}
+
+		var venueDto resources.VenueDto
+		venueDto = venueDto.DomainToDto(venue)
+		venueDto.NotRelocatedEventIds = notRelocated
+		Success(w, venueDto)
+	}
+}
+
+func (c VenueController) Find() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		venue, ok := r.Context().Value(VenueKey).(domain.Venue)
+		if !ok {
+			InternalServerError(w, fmt.Errorf("failed to cast venue"))
+			return
+		}
+
+		var venueDto resources.VenueDto
+		Success(w, venueDto.DomainToDto(venue))
+	}
+}
+
+func (c VenueController) FindList() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		pagination, err := requests.BindPagination(r)
+		if err != nil {
+			BadRequest(w, err)
+			return
+		}
+		filters := database.VenueFilters{
+			Search: r.URL.Query().Get("search"),
+			City:   r.URL.Query().Get("city"),
+		}
+
+		venues, total, err := c.venueService.FindList(filters, pagination)
+		if err != nil {
+			InternalServerError(w, err)
+			return
+		}
+
+		paginationHeaders(w, r, pagination, total)
+		var venuesDto resources.VenuesDto
+		Success(w, venuesDto.DomainToDto(venues, total, pagination))
+	}
+}
+
+func (c VenueController) Delete() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		venue, ok := c.editableVenue(w, r)
+		if !ok {
+			return
+		}
+
+		err := c.venueService.Delete(venue.Id)
+		if err != nil {
+			InternalServerError(w, err)
+			return
+		}
+
+		noContent(w)
+	}
+}
+
+// FindEvents lists the events held at the venue, with the same filters as /events/findList.
+func (c VenueController) FindEvents() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		venue, ok := r.Context().Value(VenueKey).(domain.Venue)
+		if !ok {
+			InternalServerError(w, fmt.Errorf("failed to cast venue"))
+			return
+		}
+
+		filters, pagination, err := bindListParams(r)
+		if err != nil {
+			BadRequest(w, err)
+			return
+		}
+		filters.VenueId = venue.Id
+
+		events, total, err := c.eventService.FindList(filters, pagination)
+		if err != nil {
+			log.Printf("VenueController -> FindEvents -> c.eventService.FindList: %s", err)
+			InternalServerError(w, err)
+			return
+		}
+
+		paginationHeaders(w, r, pagination, total)
+		var eventsDto resources.EventsPageDto
+		Success(w, eventsDto.DomainToDto(events, total, pagination))
+	}
+}
+
+func (c VenueController) AddPhoto() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		venue, ok := c.editableVenue(w, r)
+		if !ok {
+			return
+		}
+
+		r.Body = http.MaxBytesReader(w, r.Body, maxVenuePhotoSize)
+		file, header, err := r.FormFile("image")
+		if err != nil {
+			BadRequest(w, fmt.Errorf("failed to get the file"))
+			return
+		}
+		defer file.Close()
+
+		ext := strings.ToLower(filepath.Ext(header.Filename))
+		if !venuePhotoExtensions[ext] {
+			BadRequest(w, fmt.Errorf("unsupported image type %q", ext))
+			return
+		}
+
+		content, err := io.ReadAll(file)
+		if err != nil {
+			BadRequest(w, fmt.Errorf("failed to read the file"))
+			return
+		}
+
+		filename := fmt.Sprintf("venue_%d_%s%s", venue.Id, uuid.NewString(), ext)
+		err = c.imageService.SaveImage(filename, content)
+		if err != nil {
+			InternalServerError(w, err)
+			return
+		}
+
+		photo, err := c.venueService.AddPhoto(venue.Id, filename)
+		if err != nil {
+			InternalServerError(w, err)
+			return
+		}
+
+		var photoDto resources.VenuePhotoDto
+		Created(w, photoDto.DomainToDto(photo))
+	}
+}
+
+func (c VenueController) DeletePhoto() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		venue, ok := c.editableVenue(w, r)
+		if !ok {
+			return
+		}
+
+		photoId, err := strconv.ParseUint(chi.URLParam(r, "photoId"), 10, 64)
+		if err != nil {
+			BadRequest(w, fmt.Errorf("invalid photoId parameter(only non-negative integers)"))
+			return
+		}
+		photo, err := c.venueService.FindPhoto(venue.Id, photoId)
+		if errors.Is(err, db.ErrNoMoreRows) {
+			NotFound(w, fmt.Errorf("photo not found"))
+			return
+		} else if err != nil {
+			InternalServerError(w, err)
+			return
+		}
+
+		err = c.venueService.DeletePhoto(photo.Id)
+		if err != nil {
+			InternalServerError(w, err)
+			return
+		}
+		err = c.imageService.DeleteImage(photo.Image)
+		if err != nil {
+			log.Printf("VenueController -> DeletePhoto -> c.imageService.DeleteImage: %s", err)
+		}
+
+		noContent(w)
+	}
+}
+
+// editableVenue returns the venue of the path if the user created it or is an admin.
+func (c VenueController) editableVenue(w http.ResponseWriter, r *http.Request) (domain.Venue, bool) {
+	venue, ok := r.Context().Value(VenueKey).(domain.Venue)
+	if !ok {
+		InternalServerError(w, fmt.Errorf("failed to cast venue"))
+		return domain.Venue{}, false
+	}
+	user := r.Context().Value(UserKey).(domain.User)
+	if venue.UserId != user.Id && user.Role != domain.AdminRole {
+		Forbidden(w, fmt.Errorf("only the venue creator can change it"))
+		return domain.Venue{}, false
+	}
+	return venue, true
+}
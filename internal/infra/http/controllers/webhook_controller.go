@@ -0,0 +1,221 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/app"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/http/requests"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/http/resources"
+	"github.com/go-chi/chi/v5"
+	"log"
+	"net/http"
+	"strconv"
+)
+
+type WebhookController struct {
+	webhookService app.WebhookService
+}
+
+func NewWebhookController(ws app.WebhookService) WebhookController {
+	return WebhookController{
+		webhookService: ws,
+	}
+}
+
+func (c WebhookController) Save() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		hook, err := requests.Bind(r, requests.WebhookRequest{}, domain.Webhook{})
+		if err != nil {
+			log.Printf("WebhookController -> Save -> requests.Bind: %s", err)
+			BadRequest(w, err)
+			return
+		}
+		user := r.Context().Value(UserKey).(domain.User)
+
+		hook.UserId = user.Id
+		hook, err = c.webhookService.Save(hook)
+		if err != nil {
+			webhookError(w, err)
+			return
+		}
+
+		var hookDto resources.WebhookDto
+		Created(w, hookDto.DomainToDto(hook))
+	}
+}
+
+// Update keeps the secret unless a new one is given.
+func (c WebhookController) Update() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		hook, ok := c.ownWebhook(w, r)
+		if !ok {
+			return
+		}
+		// the webhook stays as active as it was unless told otherwise
+		active := hook.Active
+		req, err := requests.Bind(r, requests.WebhookRequest{Active: &active}, domain.Webhook{})
+		if err != nil {
+			log.Printf("WebhookController -> Update -> requests.Bind: %s", err)
+			BadRequest(w, err)
+			return
+		}
+
+		hook.Url = req.Url
+		hook.Events = req.Events
+		hook.Active = req.Active
+		if req.Secret != "" {
+			hook.Secret = req.Secret
+		}
+		hook, err = c.webhookService.Update(hook)
+		if err != nil {
+			webhookError(w, err)
+			return
+		}
+
+		var hookDto resources.WebhookDto
+		Success(w, hookDto.DomainToDto(hook))
+	}
+}
+
+func (c WebhookController) FindMine() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := r.Context().Value(UserKey).(domain.User)
+
+		hooks, err := c.webhookService.FindByUser(user.Id)
+		if err != nil {
+			InternalServerError(w, err)
+			return
+		}
+
+		var hooksDto resources.WebhooksDto
+		Success(w, hooksDto.DomainToDto(hooks))
+	}
+}
+
+func (c WebhookController) Find() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		hook, ok := c.ownWebhook(w, r)
+		if !ok {
+			return
+		}
+
+		var hookDto resources.WebhookDto
+		Success(w, hookDto.DomainToDto(hook))
+	}
+}
+
+func (c WebhookController) Delete() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		hook, ok := c.ownWebhook(w, r)
+		if !ok {
+			return
+		}
+
+		err := c.webhookService.Delete(hook.Id)
+		if err != nil {
+			InternalServerError(w, err)
+			return
+		}
+
+		noContent(w)
+	}
+}
+
+// FindDeliveries lists the deliveries of the webhook with their response codes, the newest first.
+func (c WebhookController) FindDeliveries() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		hook, ok := c.ownWebhook(w, r)
+		if !ok {
+			return
+		}
+
+		pagination, err := requests.BindPagination(r)
+		if err != nil {
+			BadRequest(w, err)
+			return
+		}
+
+		deliveries, total, err := c.webhookService.FindDeliveries(hook.Id, pagination)
+		if err != nil {
+			InternalServerError(w, err)
+			return
+		}
+
+		paginationHeaders(w, r, pagination, total)
+		var deliveriesDto resources.WebhookDeliveriesDto
+		Success(w, deliveriesDto.DomainToDto(deliveries, total, pagination))
+	}
+}
+
+func (c WebhookController) Redeliver() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		hook, ok := c.ownWebhook(w, r)
+		if !ok {
+			return
+		}
+		deliveryId, err := strconv.ParseUint(chi.URLParam(r, "deliveryId"), 10, 64)
+		if err != nil {
+			BadRequest(w, fmt.Errorf("invalid deliveryId parameter(only non-negative integers)"))
+			return
+		}
+
+		delivery, err := c.webhookService.Redeliver(hook, deliveryId)
+		if err != nil {
+			webhookError(w, err)
+			return
+		}
+
+		var deliveryDto resources.WebhookDeliveryDto
+		Created(w, deliveryDto.DomainToDto(delivery))
+	}
+}
+
+// Ping sends a test delivery right away and returns how it went.
+func (c WebhookController) Ping() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		hook, ok := c.ownWebhook(w, r)
+		if !ok {
+			return
+		}
+
+		delivery, err := c.webhookService.Ping(hook)
+		if err != nil {
+			InternalServerError(w, err)
+			return
+		}
+
+		var deliveryDto resources.WebhookDeliveryDto
+		Success(w, deliveryDto.DomainToDto(delivery))
+	}
+}
+
+// ownWebhook returns the webhook of the path if it belongs to the user.
+func (c WebhookController) ownWebhook(w http.ResponseWriter, r *http.Request) (domain.Webhook, bool) {
+	hook, ok := r.Context().Value(WebhookKey).(domain.Webhook)
+	if !ok {
+		InternalServerError(w, fmt.Errorf("failed to cast webhook"))
+		return domain.Webhook{}, false
+	}
+	user := r.Context().Value(UserKey).(domain.User)
+	if hook.UserId != user.Id {
+		Forbidden(w, fmt.Errorf("the webhook belongs to another user"))
+		return domain.Webhook{}, false
+	}
+	return hook, true
+}
+
+func webhookError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, app.ErrTooManyWebhooks):
+		Conflict(w, err)
+	case errors.Is(err, app.ErrInvalidWebhookUrl), errors.Is(err, app.ErrWebhookUrlNotAllowed),
+		errors.Is(err, app.ErrUnknownWebhookEventType):
+		BadRequest(w, err)
+	case errors.Is(err, app.ErrWebhookDeliveryNotFound):
+		NotFound(w, err)
+	default:
+		log.Printf("WebhookController: %s", err)
+		InternalServerError(w, err)
+	}
+}
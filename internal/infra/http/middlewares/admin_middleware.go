@@ -0,0 +1,22 @@
+package middlewares
+
+import (
+	"errors"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/http/controllers"
+	"net/http"
+)
+
+// AdminMiddleware must be used after AuthMiddleware, it lets only admins through.
+func AdminMiddleware(next http.Handler) http.Handler {
+	hfn := func(w http.ResponseWriter, r *http.Request) {
+		user, ok := r.Context().Value(controllers.UserKey).(domain.User)
+		if !ok || user.Role != domain.AdminRole {
+			controllers.Forbidden(w, errors.New("only admins can do this"))
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	}
+	return http.HandlerFunc(hfn)
+}
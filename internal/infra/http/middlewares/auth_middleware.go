@@ -13,11 +13,17 @@ import (
 	"net/http"
 )
 
-func AuthMiddleware(ja *jwtauth.JWTAuth, as app.AuthService, us app.UserService) func(http.Handler) http.Handler {
+// AuthMiddleware takes the token from the Authorization header unless other places to
+// look for it are given.
+func AuthMiddleware(ja *jwtauth.JWTAuth, as app.AuthService, us app.UserService, findTokenFns ...func(r *http.Request) string) func(http.Handler) http.Handler {
+	if len(findTokenFns) == 0 {
+		findTokenFns = []func(r *http.Request) string{jwtauth.TokenFromHeader}
+	}
+
 	return func(next http.Handler) http.Handler {
 		hfn := func(w http.ResponseWriter, r *http.Request) {
 			ctx := r.Context()
-			token, err := jwtauth.VerifyRequest(ja, r, jwtauth.TokenFromHeader)
+			token, err := jwtauth.VerifyRequest(ja, r, findTokenFns...)
 
 			if err != nil {
 				controllers.Unauthorized(w, err)
@@ -0,0 +1,42 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// RedactQueryMiddleware hides the values of the query parameters from the request URI, the
+// access log shows it. The parsed URL is left as it is, so the handlers still get them.
+func RedactQueryMiddleware(params ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		hfn := func(w http.ResponseWriter, r *http.Request) {
+			r.RequestURI = redactQuery(r.RequestURI, params)
+			next.ServeHTTP(w, r)
+		}
+		return http.HandlerFunc(hfn)
+	}
+}
+
+func redactQuery(uri string, params []string) string {
+	path, rawQuery, found := strings.Cut(uri, "?")
+	if !found {
+		return uri
+	}
+
+	parts := strings.Split(rawQuery, "&")
+	for i, part := range parts {
+		key, _, _ := strings.Cut(part, "=")
+		name, err := url.QueryUnescape(key)
+		if err != nil {
+			name = key
+		}
+		for _, param := range params {
+			if name == param {
+				parts[i] = key + "=REDACTED"
+				break
+			}
+		}
+	}
+	return path + "?" + strings.Join(parts, "&")
+}
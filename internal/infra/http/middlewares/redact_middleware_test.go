@@ -0,0 +1,44 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRedactQueryMiddleware(t *testing.T) {
+	tests := []struct {
+		uri  string
+		want string
+	}{
+		{"/api/v1/stream", "/api/v1/stream"},
+		{"/api/v1/stream?jwt=eyJhbGciOi.eyJ1aWQ.c2ln", "/api/v1/stream?jwt=REDACTED"},
+		{"/api/v1/stream?lastEventId=5&jwt=secret&x=1", "/api/v1/stream?lastEventId=5&jwt=REDACTED&x=1"},
+		{"/api/v1/stream?%6Awt=secret", "/api/v1/stream?%6Awt=REDACTED"},
+		{"/api/v1/stream?jwt", "/api/v1/stream?jwt=REDACTED"},
+		{"/api/v1/events?search=jwt", "/api/v1/events?search=jwt"},
+	}
+	for _, tt := range tests {
+		var logged, token string
+		logger := func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				next.ServeHTTP(w, r)
+				logged = r.RequestURI
+			})
+		}
+		handler := RedactQueryMiddleware("jwt")(logger(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token = r.URL.Query().Get("jwt")
+		})))
+
+		req := httptest.NewRequest(http.MethodGet, tt.uri, nil)
+		want := req.URL.Query().Get("jwt")
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+
+		if logged != tt.want {
+			t.Errorf("logged %q, want %q", logged, tt.want)
+		}
+		if token != want {
+			t.Errorf("%s: handler got jwt %q, want %q", tt.uri, token, want)
+		}
+	}
+}
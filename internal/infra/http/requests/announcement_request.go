@@ -0,0 +1,21 @@
+package requests
+
+import (
+	"strings"
+
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+)
+
+type AnnouncementRequest struct {
+	Title   string `json:"title" validate:"required,max=120"`
+	Body    string `json:"body" validate:"required,max=2000"`
+	Channel string `json:"channel" validate:"omitempty,oneof=IN_APP EMAIL"`
+}
+
+func (r AnnouncementRequest) ToDomainModel() (interface{}, error) {
+	return domain.Announcement{
+		Title:   strings.TrimSpace(r.Title),
+		Body:    strings.TrimSpace(r.Body),
+		Channel: domain.NotificationChannel(r.Channel),
+	}, nil
+}
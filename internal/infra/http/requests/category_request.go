@@ -0,0 +1,19 @@
+package requests
+
+import (
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+)
+
+type CategoryRequest struct {
+	ParentId *uint64 `json:"parentId" validate:"omitempty,gt=0"`
+	Name     string  `json:"name" validate:"required,max=80"`
+	Slug     string  `json:"slug" validate:"omitempty,max=80"`
+}
+
+func (r CategoryRequest) ToDomainModel() (interface{}, error) {
+	return domain.Category{
+		ParentId: r.ParentId,
+		Name:     r.Name,
+		Slug:     r.Slug,
+	}, nil
+}
@@ -0,0 +1,38 @@
+package requests
+
+import (
+	"errors"
+
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+)
+
+var errEmptyComment = errors.New("comment body is empty")
+
+// CommentRequest is validated and sanitized the same way as event descriptions.
+type CommentRequest struct {
+	Body     string  `json:"body" validate:"required,max=200"`
+	ParentId *uint64 `json:"parentId" validate:"omitempty,gt=0"`
+}
+
+type UpdateCommentRequest struct {
+	Body string `json:"body" validate:"required,max=200"`
+}
+
+func (r CommentRequest) ToDomainModel() (interface{}, error) {
+	body := domain.SanitizeText(r.Body)
+	if body == "" {
+		return nil, errEmptyComment
+	}
+	return domain.Comment{
+		Body:     body,
+		ParentId: r.ParentId,
+	}, nil
+}
+
+func (r UpdateCommentRequest) ToDomainModel() (interface{}, error) {
+	body := domain.SanitizeText(r.Body)
+	if body == "" {
+		return nil, errEmptyComment
+	}
+	return domain.Comment{Body: body}, nil
+}
@@ -6,48 +6,101 @@ import (
 )
 
 type CreateEventRequest struct {
-	Title       string  `json:"title" validate:"required,max=80"`
-	Description string  `json:"description"  validate:"required,max=200"`
-	Image       string  `json:"image"`
-	Lat         float64 `json:"lat" validate:"required"`
-	Lon         float64 `json:"lon" validate:"required"`
-	City        string  `json:"city"`
-	Location    string  `json:"location"  validate:"required,max=200"`
-	Date        int64   `json:"date"`
+	Title           string   `json:"title" validate:"required,max=80"`
+	Description     string   `json:"description"  validate:"required,max=200"`
+	Image           string   `json:"image"`
+	VenueId         *uint64  `json:"venueId" validate:"omitempty,gt=0"`
+	Lat             float64  `json:"lat" validate:"min=-90,max=90"`
+	Lon             float64  `json:"lon" validate:"min=-180,max=180"`
+	City            string   `json:"city"`
+	Location        string   `json:"location"  validate:"required_without=VenueId,max=200"`
+	Date            int64    `json:"date"`
+	EndDate         *int64   `json:"endDate" validate:"omitempty,gtefield=Date"`
+	Capacity        *uint64  `json:"capacity" validate:"omitempty,gt=0"`
+	AttendeesPublic bool     `json:"attendeesPublic"`
+	CategoryId      *uint64  `json:"categoryId" validate:"omitempty,gt=0"`
+	Tags            []string `json:"tags" validate:"omitempty,max=10,dive,max=30"`
 }
 type UpdateEventRequest struct {
-	Title       string  `json:"title" validate:"required,max=40"`
-	Description string  `json:"description"  validate:"required,max=200"`
-	Image       string  `json:"image" validate:"required"`
-	Lat         float64 `json:"lat" validate:"required"`
-	Lon         float64 `json:"lon" validate:"required"`
-	City        string  `json:"city"`
-	Location    string  `json:"location"  validate:"required,max=200"`
-	Date        int64   `json:"date"`
+	Title           string   `json:"title" validate:"required,max=40"`
+	Description     string   `json:"description"  validate:"required,max=200"`
+	Image           string   `json:"image" validate:"required"`
+	VenueId         *uint64  `json:"venueId" validate:"omitempty,gt=0"`
+	Lat             float64  `json:"lat" validate:"min=-90,max=90"`
+	Lon             float64  `json:"lon" validate:"min=-180,max=180"`
+	City            string   `json:"city"`
+	Location        string   `json:"location"  validate:"required_without=VenueId,max=200"`
+	Date            int64    `json:"date"`
+	EndDate         *int64   `json:"endDate" validate:"omitempty,gtefield=Date"`
+	Capacity        *uint64  `json:"capacity" validate:"omitempty,gt=0"`
+	AttendeesPublic bool     `json:"attendeesPublic"`
+	CategoryId      *uint64  `json:"categoryId" validate:"omitempty,gt=0"`
+	Tags            []string `json:"tags" validate:"omitempty,max=10,dive,max=30"`
 }
 
 func (r CreateEventRequest) ToDomainModel() (interface{}, error) {
 	return domain.Event{
-		Title:       r.Title,
-		Description: r.Description,
-		Image:       r.Image,
-		City:        r.City,
-		Location:    r.Location,
-		Lat:         r.Lat,
-		Lon:         r.Lon,
-		Date:        time.Unix(r.Date, 0),
+		Title:           r.Title,
+		Description:     domain.SanitizeText(r.Description),
+		Image:           r.Image,
+		City:            r.City,
+		Location:        r.Location,
+		VenueId:         r.VenueId,
+		Lat:             r.Lat,
+		Lon:             r.Lon,
+		Capacity:        r.Capacity,
+		AttendeesPublic: r.AttendeesPublic,
+		CategoryId:      r.CategoryId,
+		Tags:            r.Tags,
+		Date:            time.Unix(r.Date, 0),
+		EndDate:         unixTime(r.EndDate),
 	}, nil
 }
 
 func (r UpdateEventRequest) ToDomainModel() (interface{}, error) {
 	return domain.Event{
-		Title:       r.Title,
-		Description: r.Description,
-		Image:       r.Image,
-		City:        r.City,
-		Location:    r.Location,
-		Lat:         r.Lat,
-		Lon:         r.Lon,
-		Date:        time.Unix(r.Date, 0),
+		Title:           r.Title,
+		Description:     domain.SanitizeText(r.Description),
+		Image:           r.Image,
+		City:            r.City,
+		Location:        r.Location,
+		VenueId:         r.VenueId,
+		Lat:             r.Lat,
+		Lon:             r.Lon,
+		Capacity:        r.Capacity,
+		AttendeesPublic: r.AttendeesPublic,
+		CategoryId:      r.CategoryId,
+		Tags:            r.Tags,
+		Date:            time.Unix(r.Date, 0),
+		EndDate:         unixTime(r.EndDate),
 	}, nil
 }
+
+// NewUpdateEventRequest presets the request with the event, so the fields absent from the
+// body keep their values. Sending null clears the optional ones. The coordinates are not
+// preset, without them the event keeps its own unless the place changes.
+func NewUpdateEventRequest(e domain.Event) UpdateEventRequest {
+	var endDate *int64
+	if e.EndDate != nil {
+		timestamp := e.EndDate.Unix()
+		endDate = &timestamp
+	}
+	return UpdateEventRequest{
+		VenueId:         e.VenueId,
+		City:            e.City,
+		Location:        e.Location,
+		Date:            e.Date.Unix(),
+		EndDate:         endDate,
+		Capacity:        e.Capacity,
+		AttendeesPublic: e.AttendeesPublic,
+		CategoryId:      e.CategoryId,
+	}
+}
+
+func unixTime(timestamp *int64) *time.Time {
+	if timestamp == nil {
+		return nil
+	}
+	t := time.Unix(*timestamp, 0)
+	return &t
+}
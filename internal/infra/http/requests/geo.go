@@ -0,0 +1,46 @@
+package requests
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+)
+
+// ParseGeoPoint parses the lat and lon query parameters.
+func ParseGeoPoint(lat, lon string) (domain.GeoPoint, error) {
+	latValue, err := strconv.ParseFloat(lat, 64)
+	if err != nil || latValue < -90 || latValue > 90 {
+		return domain.GeoPoint{}, fmt.Errorf("invalid lat parameter(from -90 to 90)")
+	}
+	lonValue, err := strconv.ParseFloat(lon, 64)
+	if err != nil || lonValue < -180 || lonValue > 180 {
+		return domain.GeoPoint{}, fmt.Errorf("invalid lon parameter(from -180 to 180)")
+	}
+	return domain.GeoPoint{Lat: latValue, Lon: lonValue}, nil
+}
+
+// ParseBoundingBox parses "minLon,minLat,maxLon,maxLat", the order used by GeoJSON.
+func ParseBoundingBox(bbox string) (domain.BoundingBox, error) {
+	parts := strings.Split(bbox, ",")
+	if len(parts) != 4 {
+		return domain.BoundingBox{}, fmt.Errorf("invalid bbox parameter(expected minLon,minLat,maxLon,maxLat)")
+	}
+
+	values := make([]float64, 4)
+	for i, part := range parts {
+		value, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return domain.BoundingBox{}, fmt.Errorf("invalid bbox parameter(expected minLon,minLat,maxLon,maxLat)")
+		}
+		values[i] = value
+	}
+
+	box := domain.BoundingBox{MinLon: values[0], MinLat: values[1], MaxLon: values[2], MaxLat: values[3]}
+	if box.MinLat < -90 || box.MaxLat > 90 || box.MinLat > box.MaxLat ||
+		box.MinLon < -180 || box.MaxLon > 180 || box.MinLon > box.MaxLon {
+		return domain.BoundingBox{}, fmt.Errorf("invalid bbox parameter(coordinates out of range)")
+	}
+	return box, nil
+}
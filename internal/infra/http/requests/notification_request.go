@@ -0,0 +1,22 @@
+package requests
+
+import (
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+)
+
+type NotificationPreferenceRequest struct {
+	Type    string `json:"type" validate:"required"`
+	Enabled bool   `json:"enabled"`
+}
+
+type NotificationPreferencesRequest struct {
+	Items []NotificationPreferenceRequest `json:"items" validate:"required,min=1,dive"`
+}
+
+func (r NotificationPreferencesRequest) ToDomainModel() (interface{}, error) {
+	prefs := make([]domain.NotificationPreference, len(r.Items))
+	for i, item := range r.Items {
+		prefs[i] = domain.NotificationPreference{Type: domain.NotificationType(item.Type), Enabled: item.Enabled}
+	}
+	return prefs, nil
+}
@@ -0,0 +1,37 @@
+package requests
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+)
+
+const (
+	defaultPerPage = 20
+	maxPerPage     = 100
+)
+
+// BindPagination reads the page and perPage query parameters, pages are counted from 1.
+func BindPagination(r *http.Request) (domain.Pagination, error) {
+	p := domain.Pagination{Page: 1, CountPerPage: defaultPerPage}
+
+	if page := r.URL.Query().Get("page"); page != "" {
+		value, err := strconv.ParseUint(page, 10, 64)
+		if err != nil || value == 0 {
+			return domain.Pagination{}, fmt.Errorf("invalid page parameter(only positive integers)")
+		}
+		p.Page = value
+	}
+
+	if perPage := r.URL.Query().Get("perPage"); perPage != "" {
+		value, err := strconv.ParseUint(perPage, 10, 64)
+		if err != nil || value == 0 || value > maxPerPage {
+			return domain.Pagination{}, fmt.Errorf("invalid perPage parameter(from 1 to %d)", maxPerPage)
+		}
+		p.CountPerPage = value
+	}
+
+	return p, nil
+}
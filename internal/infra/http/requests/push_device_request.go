@@ -0,0 +1,23 @@
+package requests
+
+import (
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+)
+
+type PushDeviceRequest struct {
+	Platform string `json:"platform" validate:"required,oneof=WEB ANDROID IOS"`
+	Token    string `json:"token" validate:"required,max=1024"`
+	P256dh   string `json:"p256dh" validate:"required_if=Platform WEB,max=255"`
+	Auth     string `json:"auth" validate:"required_if=Platform WEB,max=255"`
+	Locale   string `json:"locale" validate:"omitempty,oneof=en uk"`
+}
+
+func (r PushDeviceRequest) ToDomainModel() (interface{}, error) {
+	return domain.PushDevice{
+		Platform: domain.PushPlatform(r.Platform),
+		Token:    r.Token,
+		P256dh:   r.P256dh,
+		Auth:     r.Auth,
+		Locale:   r.Locale,
+	}, nil
+}
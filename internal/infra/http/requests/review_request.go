@@ -0,0 +1,25 @@
+package requests
+
+import (
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+)
+
+type ReviewRequest struct {
+	Rating uint8  `json:"rating" validate:"required,min=1,max=5"`
+	Body   string `json:"body" validate:"max=1000"`
+}
+
+type ReviewReplyRequest struct {
+	Reply string `json:"reply" validate:"required,max=1000"`
+}
+
+func (r ReviewRequest) ToDomainModel() (interface{}, error) {
+	return domain.Review{
+		Rating: r.Rating,
+		Body:   domain.SanitizeText(r.Body),
+	}, nil
+}
+
+func (r ReviewReplyRequest) ToDomainModel() (interface{}, error) {
+	return domain.SanitizeText(r.Reply), nil
+}
@@ -0,0 +1,35 @@
+package requests
+
+import (
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+)
+
+type SavedSearchRequest struct {
+	Name       string   `json:"name" validate:"required,max=80"`
+	Search     string   `json:"search" validate:"max=255"`
+	City       string   `json:"city" validate:"max=255"`
+	Lat        *float64 `json:"lat" validate:"required_with=Lon,omitempty,min=-90,max=90"`
+	Lon        *float64 `json:"lon" validate:"required_with=Lat,omitempty,min=-180,max=180"`
+	RadiusKm   float64  `json:"radiusKm" validate:"min=0,max=500"`
+	CategoryId *uint64  `json:"categoryId" validate:"omitempty,gt=0"`
+	Channel    string   `json:"channel" validate:"omitempty,oneof=IN_APP EMAIL"`
+	Frequency  string   `json:"frequency" validate:"omitempty,oneof=INSTANT DAILY"`
+}
+
+func (r SavedSearchRequest) ToDomainModel() (interface{}, error) {
+	var point *domain.GeoPoint
+	if r.Lat != nil && r.Lon != nil {
+		point = &domain.GeoPoint{Lat: *r.Lat, Lon: *r.Lon}
+	}
+
+	return domain.SavedSearch{
+		Name:       r.Name,
+		Search:     r.Search,
+		City:       r.City,
+		Point:      point,
+		RadiusKm:   r.RadiusKm,
+		CategoryId: r.CategoryId,
+		Channel:    domain.NotificationChannel(r.Channel),
+		Frequency:  domain.SavedSearchFrequency(r.Frequency),
+	}, nil
+}
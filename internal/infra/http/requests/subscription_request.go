@@ -0,0 +1,19 @@
+package requests
+
+import (
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+)
+
+type SubscribeRequest struct {
+	Rsvp string `json:"rsvp" validate:"omitempty,oneof=GOING MAYBE DECLINED"`
+}
+
+func (r SubscribeRequest) ToDomainModel() (interface{}, error) {
+	rsvp := domain.RsvpStatus(r.Rsvp)
+	if rsvp == "" {
+		rsvp = domain.GoingRsvpStatus
+	}
+	return domain.Subscription{
+		Rsvp: rsvp,
+	}, nil
+}
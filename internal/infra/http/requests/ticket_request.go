@@ -0,0 +1,15 @@
+package requests
+
+import (
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+)
+
+type CheckInRequest struct {
+	Code string `json:"code" validate:"required,max=200"`
+}
+
+func (r CheckInRequest) ToDomainModel() (interface{}, error) {
+	return domain.Ticket{
+		Code: r.Code,
+	}, nil
+}
@@ -9,6 +9,7 @@ type RegisterRequest struct {
 	SecondName string `json:"secondName" validate:"required,gte=1,max=40"`
 	Email      string `json:"email" validate:"required,email"`
 	Password   string `json:"password" validate:"required,gte=4,max=20"`
+	Locale     string `json:"locale" validate:"omitempty,oneof=en uk"`
 }
 
 type LoginRequest struct {
@@ -20,14 +21,21 @@ type UpdateUserRequest struct {
 	FirstName  string `json:"firstName" validate:"required,gte=1,max=40"`
 	SecondName string `json:"secondName" validate:"required,gte=1,max=40"`
 	Email      string `json:"email" validate:"required,email"`
+	Locale     string `json:"locale" validate:"omitempty,oneof=en uk"`
 }
 
 func (r RegisterRequest) ToDomainModel() (interface{}, error) {
+	locale := r.Locale
+	if locale == "" {
+		locale = domain.DefaultLocale
+	}
+
 	return domain.User{
 		FirstName:  r.FirstName,
 		SecondName: r.SecondName,
 		Email:      r.Email,
 		Password:   r.Password,
+		Locale:     locale,
 	}, nil
 }
 
@@ -36,6 +44,7 @@ func (r UpdateUserRequest) ToDomainModel() (interface{}, error) {
 		FirstName:  r.FirstName,
 		SecondName: r.SecondName,
 		Email:      r.Email,
+		Locale:     r.Locale,
 	}, nil
 }
 
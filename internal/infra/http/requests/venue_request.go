@@ -0,0 +1,25 @@
+package requests
+
+import (
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+)
+
+type VenueRequest struct {
+	Name     string  `json:"name" validate:"required,max=120"`
+	Address  string  `json:"address" validate:"max=255"`
+	City     string  `json:"city" validate:"max=255"`
+	Lat      float64 `json:"lat" validate:"required,min=-90,max=90"`
+	Lon      float64 `json:"lon" validate:"required,min=-180,max=180"`
+	Capacity *uint64 `json:"capacity" validate:"omitempty,gt=0"`
+}
+
+func (r VenueRequest) ToDomainModel() (interface{}, error) {
+	return domain.Venue{
+		Name:     r.Name,
+		Address:  r.Address,
+		City:     r.City,
+		Lat:      r.Lat,
+		Lon:      r.Lon,
+		Capacity: r.Capacity,
+	}, nil
+}
@@ -0,0 +1,30 @@
+package requests
+
+import (
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+)
+
+type WebhookRequest struct {
+	Url    string   `json:"url" validate:"required,url,max=2048"`
+	Secret string   `json:"secret" validate:"omitempty,min=16,max=100"`
+	Events []string `json:"events" validate:"required,min=1,dive,required"`
+	Active *bool    `json:"active"`
+}
+
+func (r WebhookRequest) ToDomainModel() (interface{}, error) {
+	events := make([]domain.WebhookEventType, len(r.Events))
+	for i, e := range r.Events {
+		events[i] = domain.WebhookEventType(e)
+	}
+	active := true
+	if r.Active != nil {
+		active = *r.Active
+	}
+
+	return domain.Webhook{
+		Url:    r.Url,
+		Secret: r.Secret,
+		Events: events,
+		Active: active,
+	}, nil
+}
@@ -0,0 +1,47 @@
+package resources
+
+import (
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"time"
+)
+
+type AnnouncementDto struct {
+	Id          uint64                     `json:"id"`
+	EventId     uint64                     `json:"eventId"`
+	Title       string                     `json:"title"`
+	Body        string                     `json:"body"`
+	Channel     domain.NotificationChannel `json:"channel"`
+	Recipients  uint64                     `json:"recipients"`
+	CreatedDate time.Time                  `json:"createdDate"`
+}
+
+type AnnouncementsDto struct {
+	Items []AnnouncementDto `json:"items"`
+	Total uint64            `json:"total"`
+	Pages uint              `json:"pages"`
+}
+
+func (d AnnouncementDto) DomainToDto(a domain.Announcement) AnnouncementDto {
+	return AnnouncementDto{
+		Id:          a.Id,
+		EventId:     a.EventId,
+		Title:       a.Title,
+		Body:        a.Body,
+		Channel:     a.Channel,
+		Recipients:  a.Recipients,
+		CreatedDate: a.CreatedDate,
+	}
+}
+
+func (d AnnouncementsDto) DomainToDto(announcements []domain.Announcement, total uint64, p domain.Pagination) AnnouncementsDto {
+	items := make([]AnnouncementDto, len(announcements))
+	for i, a := range announcements {
+		items[i] = AnnouncementDto{}.DomainToDto(a)
+	}
+
+	return AnnouncementsDto{
+		Items: items,
+		Total: total,
+		Pages: uint((total + p.CountPerPage - 1) / p.CountPerPage),
+	}
+}
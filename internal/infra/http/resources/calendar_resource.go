@@ -0,0 +1,15 @@
+package resources
+
+import "github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+
+type CalendarFeedDto struct {
+	Token string `json:"token"`
+	Url   string `json:"url"`
+}
+
+func (d CalendarFeedDto) DomainToDto(user domain.User, url string) CalendarFeedDto {
+	return CalendarFeedDto{
+		Token: user.CalendarToken,
+		Url:   url,
+	}
+}
@@ -0,0 +1,53 @@
+package resources
+
+import (
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+)
+
+type CategoryDto struct {
+	Id       uint64        `json:"id"`
+	ParentId *uint64       `json:"parentId,omitempty"`
+	Name     string        `json:"name"`
+	Slug     string        `json:"slug"`
+	Children []CategoryDto `json:"children,omitempty"`
+}
+
+type CategoriesDto struct {
+	Categories []CategoryDto `json:"categories"`
+}
+
+func (d CategoryDto) DomainToDto(c domain.Category) CategoryDto {
+	return CategoryDto{
+		Id:       c.Id,
+		ParentId: c.ParentId,
+		Name:     c.Name,
+		Slug:     c.Slug,
+	}
+}
+
+// DomainToDto nests the categories under their parents, the roots are listed at the top level.
+func (d CategoriesDto) DomainToDto(categories []domain.Category) CategoriesDto {
+	children := make(map[uint64][]domain.Category)
+	var roots []domain.Category
+	for _, c := range categories {
+		if c.ParentId == nil {
+			roots = append(roots, c)
+		} else {
+			children[*c.ParentId] = append(children[*c.ParentId], c)
+		}
+	}
+
+	var build func(cs []domain.Category) []CategoryDto
+	build = func(cs []domain.Category) []CategoryDto {
+		result := make([]CategoryDto, len(cs))
+		for i, c := range cs {
+			result[i] = CategoryDto{}.DomainToDto(c)
+			if len(children[c.Id]) > 0 {
+				result[i].Children = build(children[c.Id])
+			}
+		}
+		return result
+	}
+
+	return CategoriesDto{Categories: build(roots)}
+}
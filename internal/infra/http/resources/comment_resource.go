@@ -0,0 +1,71 @@
+package resources
+
+import (
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"time"
+)
+
+// AuthorDto keeps the contacts of the author private, unlike UserDto.
+type AuthorDto struct {
+	Id         uint64 `json:"id"`
+	FirstName  string `json:"firstName"`
+	SecondName string `json:"secondName"`
+	Image      string `json:"image"`
+}
+
+func (d AuthorDto) DomainToDto(user domain.User) AuthorDto {
+	return AuthorDto{
+		Id:         user.Id,
+		FirstName:  user.FirstName,
+		SecondName: user.SecondName,
+		Image:      user.Image,
+	}
+}
+
+type CommentDto struct {
+	Id          uint64       `json:"id"`
+	EventId     uint64       `json:"eventId"`
+	ParentId    *uint64      `json:"parentId,omitempty"`
+	Body        string       `json:"body"`
+	Author      AuthorDto    `json:"author"`
+	Replies     []CommentDto `json:"replies,omitempty"`
+	CreatedDate time.Time    `json:"createdDate"`
+	UpdatedDate *time.Time   `json:"updatedDate,omitempty"`
+}
+
+type CommentsDto struct {
+	Items []CommentDto `json:"items"`
+	Total uint64       `json:"total"`
+	Pages uint         `json:"pages"`
+}
+
+func (d CommentDto) DomainToDto(c domain.Comment) CommentDto {
+	var replies []CommentDto
+	for _, reply := range c.Replies {
+		replies = append(replies, CommentDto{}.DomainToDto(reply))
+	}
+
+	return CommentDto{
+		Id:          c.Id,
+		EventId:     c.EventId,
+		ParentId:    c.ParentId,
+		Body:        c.Body,
+		Author:      AuthorDto{}.DomainToDto(c.Author),
+		Replies:     replies,
+		CreatedDate: c.CreatedDate,
+		UpdatedDate: c.UpdatedDate,
+	}
+}
+
+func (d CommentsDto) DomainToDto(comments []domain.Comment, total uint64, p domain.Pagination) CommentsDto {
+	items := make([]CommentDto, len(comments))
+	for i, c := range comments {
+		items[i] = CommentDto{}.DomainToDto(c)
+	}
+
+	return CommentsDto{
+		Items: items,
+		Total: total,
+		Pages: uint((total + p.CountPerPage - 1) / p.CountPerPage),
+	}
+}
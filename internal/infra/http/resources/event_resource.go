@@ -6,17 +6,29 @@ import (
 )
 
 type EventDto struct {
-	Id          uint64             `db:"id,omitempty"`
-	UserId      uint64             `db:"user_id,omitempty"`
-	Title       string             `db:"title"`
-	Description string             `db:"description"`
-	Status      domain.EventStatus `db:"status"`
-	Date        time.Time          `db:"date"`
-	Image       string             `db:"image"`
-	City        string             `db:"city"`
-	Location    string             `db:"location"`
-	Lat         float64            `db:"lat"`
-	Lon         float64            `db:"long"`
+	Id               uint64             `db:"id,omitempty"`
+	UserId           uint64             `db:"user_id,omitempty"`
+	Title            string             `db:"title"`
+	Description      string             `db:"description"`
+	Status           domain.EventStatus `db:"status"`
+	Date             time.Time          `db:"date"`
+	EndDate          *time.Time         `db:"end_date"`
+	DistanceKm       *float64           `db:"distance_km"`
+	SearchRank       *float64           `db:"search_rank"`
+	Snippet          string             `db:"snippet"`
+	Image            string             `db:"image"`
+	City             string             `db:"city"`
+	Location         string             `db:"location"`
+	VenueId          *uint64            `db:"venue_id"`
+	Lat              float64            `db:"lat"`
+	Lon              float64            `db:"long"`
+	LocationMismatch bool               `db:"location_mismatch"`
+	Capacity         *uint64            `db:"capacity"`
+	AttendeesPublic  bool               `db:"attendees_public"`
+	CategoryId       *uint64            `db:"category_id"`
+	Tags             []string           `db:"tags"`
+	RatingAverage    *float64           `db:"rating_average"`
+	RatingCount      uint64             `db:"rating_count"`
 }
 type EventsDto struct {
 	Events []EventDto `json:"events"`
@@ -33,18 +45,203 @@ func (d EventsDto) DomainToDto(ev []domain.Event) EventsDto {
 	}
 }
 
+type EventsPageDto struct {
+	Events []EventDto      `json:"events"`
+	Total  uint64          `json:"total"`
+	Pages  uint            `json:"pages"`
+	Facets *EventFacetsDto `json:"facets,omitempty"`
+}
+
+type CategoryFacetDto struct {
+	CategoryId uint64 `json:"categoryId"`
+	Name       string `json:"name"`
+	Count      uint64 `json:"count"`
+}
+
+type TagFacetDto struct {
+	Tag   string `json:"tag"`
+	Count uint64 `json:"count"`
+}
+
+type EventFacetsDto struct {
+	Categories []CategoryFacetDto `json:"categories"`
+	Tags       []TagFacetDto      `json:"tags"`
+}
+
+func (d EventFacetsDto) DomainToDto(f domain.EventFacets) EventFacetsDto {
+	result := EventFacetsDto{
+		Categories: make([]CategoryFacetDto, len(f.Categories)),
+		Tags:       make([]TagFacetDto, len(f.Tags)),
+	}
+	for i, c := range f.Categories {
+		result.Categories[i] = CategoryFacetDto{CategoryId: c.CategoryId, Name: c.Name, Count: c.Count}
+	}
+	for i, t := range f.Tags {
+		result.Tags[i] = TagFacetDto{Tag: t.Tag, Count: t.Count}
+	}
+	return result
+}
+
+func (d EventsPageDto) DomainToDto(ev []domain.Event, total uint64, p domain.Pagination) EventsPageDto {
+	return EventsPageDto{
+		Events: EventsDto{}.DomainToDto(ev).Events,
+		Total:  total,
+		Pages:  uint((total + p.CountPerPage - 1) / p.CountPerPage),
+	}
+}
+
+type CalendarDayDto struct {
+	Date   string     `json:"date"`
+	Count  uint64     `json:"count"`
+	Events []EventDto `json:"events,omitempty"`
+}
+
+type EventCalendarDto struct {
+	From     time.Time        `json:"from"`
+	To       time.Time        `json:"to"`
+	TimeZone string           `json:"timeZone"`
+	Total    uint64           `json:"total"`
+	Days     []CalendarDayDto `json:"days"`
+}
+
+func (d EventCalendarDto) DomainToDto(days []domain.CalendarDay, from, to time.Time, loc *time.Location) EventCalendarDto {
+	result := EventCalendarDto{
+		From:     from.In(loc),
+		To:       to.In(loc),
+		TimeZone: loc.String(),
+		Days:     make([]CalendarDayDto, len(days)),
+	}
+	for i, day := range days {
+		result.Total += day.Count
+		result.Days[i] = CalendarDayDto{
+			Date:  day.Date,
+			Count: day.Count,
+		}
+		if day.Events != nil {
+			result.Days[i].Events = EventsDto{}.DomainToDto(day.Events).Events
+		}
+	}
+	return result
+}
+
 func (d EventDto) DomainToDto(event domain.Event) EventDto {
 	return EventDto{
-		Id:          event.Id,
-		UserId:      event.UserId,
-		Title:       event.Title,
-		Description: event.Description,
-		Status:      event.Status,
-		Image:       event.Image,
-		City:        event.City,
-		Location:    event.Location,
-		Lat:         event.Lat,
-		Lon:         event.Lon,
-		Date:        event.Date,
+		Id:               event.Id,
+		UserId:           event.UserId,
+		Title:            event.Title,
+		Description:      event.Description,
+		Status:           event.Status,
+		Image:            event.Image,
+		City:             event.City,
+		Location:         event.Location,
+		VenueId:          event.VenueId,
+		Lat:              event.Lat,
+		Lon:              event.Lon,
+		LocationMismatch: event.LocationMismatch,
+		Capacity:         event.Capacity,
+		AttendeesPublic:  event.AttendeesPublic,
+		CategoryId:       event.CategoryId,
+		Tags:             event.Tags,
+		Date:             event.Date,
+		EndDate:          event.EndDate,
+		DistanceKm:       event.DistanceKm,
+		SearchRank:       event.SearchRank,
+		Snippet:          event.Snippet,
+		RatingAverage:    ratingAverage(event.RatingAverage, event.RatingCount),
+		RatingCount:      event.RatingCount,
+	}
+}
+
+type EventImportResultDto struct {
+	Uid     string                   `json:"uid"`
+	Title   string                   `json:"title"`
+	EventId uint64                   `json:"eventId,omitempty"`
+	Status  domain.EventImportStatus `json:"status"`
+	Error   string                   `json:"error,omitempty"`
+}
+
+type EventImportReportDto struct {
+	Created uint64                 `json:"created"`
+	Updated uint64                 `json:"updated"`
+	Skipped uint64                 `json:"skipped"`
+	Items   []EventImportResultDto `json:"items"`
+}
+
+func (d EventImportReportDto) DomainToDto(results []domain.EventImportResult) EventImportReportDto {
+	report := EventImportReportDto{Items: make([]EventImportResultDto, len(results))}
+	for i, r := range results {
+		switch r.Status {
+		case domain.CreatedEventImportStatus:
+			report.Created++
+		case domain.UpdatedEventImportStatus:
+			report.Updated++
+		default:
+			report.Skipped++
+		}
+		report.Items[i] = EventImportResultDto{
+			Uid:     r.Uid,
+			Title:   r.Title,
+			EventId: r.EventId,
+			Status:  r.Status,
+			Error:   r.Error,
+		}
+	}
+	return report
+}
+
+type EventClusterDto struct {
+	Lat     float64   `json:"lat"`
+	Lon     float64   `json:"lon"`
+	Count   uint64    `json:"count"`
+	Bbox    []float64 `json:"bbox"`
+	EventId uint64    `json:"eventId,omitempty"`
+}
+
+type EventMapDto struct {
+	Clusters []EventClusterDto `json:"clusters"`
+	Events   []EventDto        `json:"events"`
+}
+
+func (d EventMapDto) DomainToDto(clusters []domain.EventCluster, events []domain.Event) EventMapDto {
+	result := EventMapDto{
+		Clusters: make([]EventClusterDto, len(clusters)),
+		Events:   EventsDto{}.DomainToDto(events).Events,
+	}
+	for i, c := range clusters {
+		result.Clusters[i] = EventClusterDto{
+			Lat:     c.Center.Lat,
+			Lon:     c.Center.Lon,
+			Count:   c.Count,
+			Bbox:    []float64{c.Bounds.MinLon, c.Bounds.MinLat, c.Bounds.MaxLon, c.Bounds.MaxLat},
+			EventId: c.EventId,
+		}
+	}
+	return result
+}
+
+type SuggestionDto struct {
+	Value string `json:"value"`
+	Count uint64 `json:"count"`
+}
+
+type EventSuggestionsDto struct {
+	Cities    []SuggestionDto `json:"cities"`
+	Locations []SuggestionDto `json:"locations"`
+	Titles    []SuggestionDto `json:"titles"`
+}
+
+func (d EventSuggestionsDto) DomainToDto(s domain.EventSuggestions) EventSuggestionsDto {
+	return EventSuggestionsDto{
+		Cities:    suggestionsToDto(s.Cities),
+		Locations: suggestionsToDto(s.Locations),
+		Titles:    suggestionsToDto(s.Titles),
+	}
+}
+
+func suggestionsToDto(suggestions []domain.Suggestion) []SuggestionDto {
+	result := make([]SuggestionDto, len(suggestions))
+	for i, s := range suggestions {
+		result[i] = SuggestionDto{Value: s.Value, Count: s.Count}
 	}
+	return result
 }
@@ -0,0 +1,60 @@
+package resources
+
+import (
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"time"
+)
+
+type EventChangeDto struct {
+	Field       string `json:"field"`
+	From        string `json:"from"`
+	To          string `json:"to"`
+	Significant bool   `json:"significant"`
+}
+
+type EventRevisionDto struct {
+	Id          uint64           `json:"id"`
+	EventId     uint64           `json:"eventId"`
+	UserId      *uint64          `json:"userId"`
+	Changes     []EventChangeDto `json:"changes"`
+	CreatedDate time.Time        `json:"createdDate"`
+}
+
+type EventRevisionsDto struct {
+	Items []EventRevisionDto `json:"items"`
+	Total uint64             `json:"total"`
+	Pages uint               `json:"pages"`
+}
+
+func (d EventRevisionDto) DomainToDto(rev domain.EventRevision) EventRevisionDto {
+	changes := make([]EventChangeDto, len(rev.Changes))
+	for i, c := range rev.Changes {
+		changes[i] = EventChangeDto{
+			Field:       c.Field,
+			From:        c.From,
+			To:          c.To,
+			Significant: c.Significant,
+		}
+	}
+
+	return EventRevisionDto{
+		Id:          rev.Id,
+		EventId:     rev.EventId,
+		UserId:      rev.UserId,
+		Changes:     changes,
+		CreatedDate: rev.CreatedDate,
+	}
+}
+
+func (d EventRevisionsDto) DomainToDto(revisions []domain.EventRevision, total uint64, p domain.Pagination) EventRevisionsDto {
+	items := make([]EventRevisionDto, len(revisions))
+	for i, rev := range revisions {
+		items[i] = EventRevisionDto{}.DomainToDto(rev)
+	}
+
+	return EventRevisionsDto{
+		Items: items,
+		Total: total,
+		Pages: uint((total + p.CountPerPage - 1) / p.CountPerPage),
+	}
+}
@@ -0,0 +1,72 @@
+package resources
+
+import (
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"time"
+)
+
+type NotificationDto struct {
+	Id          uint64                     `json:"id"`
+	EventId     uint64                     `json:"eventId,omitempty"`
+	Type        domain.NotificationType    `json:"type"`
+	Channel     domain.NotificationChannel `json:"channel"`
+	Title       string                     `json:"title"`
+	Body        string                     `json:"body"`
+	Read        bool                       `json:"read"`
+	ReadDate    *time.Time                 `json:"readDate,omitempty"`
+	CreatedDate time.Time                  `json:"createdDate"`
+}
+
+type NotificationsDto struct {
+	Items []NotificationDto `json:"items"`
+	Total uint64            `json:"total"`
+	Pages uint              `json:"pages"`
+}
+
+type UnreadCountDto struct {
+	Count uint64 `json:"count"`
+}
+
+type NotificationPreferenceDto struct {
+	Type    domain.NotificationType `json:"type"`
+	Enabled bool                    `json:"enabled"`
+}
+
+type NotificationPreferencesDto struct {
+	Items []NotificationPreferenceDto `json:"items"`
+}
+
+func (d NotificationDto) DomainToDto(n domain.Notification) NotificationDto {
+	return NotificationDto{
+		Id:          n.Id,
+		EventId:     n.EventId,
+		Type:        n.Type,
+		Channel:     n.Channel,
+		Title:       n.Title,
+		Body:        n.Body,
+		Read:        n.ReadDate != nil,
+		ReadDate:    n.ReadDate,
+		CreatedDate: n.CreatedDate,
+	}
+}
+
+func (d NotificationsDto) DomainToDto(notifications []domain.Notification, total uint64, p domain.Pagination) NotificationsDto {
+	items := make([]NotificationDto, len(notifications))
+	for i, n := range notifications {
+		items[i] = NotificationDto{}.DomainToDto(n)
+	}
+
+	return NotificationsDto{
+		Items: items,
+		Total: total,
+		Pages: uint((total + p.CountPerPage - 1) / p.CountPerPage),
+	}
+}
+
+func (d NotificationPreferencesDto) DomainToDto(prefs []domain.NotificationPreference) NotificationPreferencesDto {
+	items := make([]NotificationPreferenceDto, len(prefs))
+	for i, p := range prefs {
+		items[i] = NotificationPreferenceDto{Type: p.Type, Enabled: p.Enabled}
+	}
+	return NotificationPreferencesDto{Items: items}
+}
@@ -0,0 +1,38 @@
+package resources
+
+import (
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"time"
+)
+
+type PushDeviceDto struct {
+	Id          uint64              `json:"id"`
+	Platform    domain.PushPlatform `json:"platform"`
+	Token       string              `json:"token"`
+	Locale      string              `json:"locale"`
+	CreatedDate time.Time           `json:"createdDate"`
+	UpdatedDate time.Time           `json:"updatedDate"`
+}
+
+type PushDevicesDto struct {
+	Items []PushDeviceDto `json:"items"`
+}
+
+func (d PushDeviceDto) DomainToDto(device domain.PushDevice) PushDeviceDto {
+	return PushDeviceDto{
+		Id:          device.Id,
+		Platform:    device.Platform,
+		Token:       device.Token,
+		Locale:      device.Locale,
+		CreatedDate: device.CreatedDate,
+		UpdatedDate: device.UpdatedDate,
+	}
+}
+
+func (d PushDevicesDto) DomainToDto(devices []domain.PushDevice) PushDevicesDto {
+	items := make([]PushDeviceDto, len(devices))
+	for i, device := range devices {
+		items[i] = PushDeviceDto{}.DomainToDto(device)
+	}
+	return PushDevicesDto{Items: items}
+}
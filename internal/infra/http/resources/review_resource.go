@@ -0,0 +1,73 @@
+package resources
+
+import (
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"math"
+	"time"
+)
+
+type ReviewDto struct {
+	Id          uint64     `json:"id"`
+	EventId     uint64     `json:"eventId"`
+	Rating      uint8      `json:"rating"`
+	Body        string     `json:"body"`
+	Author      AuthorDto  `json:"author"`
+	Reply       *string    `json:"reply,omitempty"`
+	ReplyDate   *time.Time `json:"replyDate,omitempty"`
+	CreatedDate time.Time  `json:"createdDate"`
+	UpdatedDate *time.Time `json:"updatedDate,omitempty"`
+}
+
+type ReviewsDto struct {
+	Items []ReviewDto `json:"items"`
+	Total uint64      `json:"total"`
+	Pages uint        `json:"pages"`
+}
+
+type RatingDto struct {
+	Average *float64 `json:"average"`
+	Count   uint64   `json:"count"`
+}
+
+func (d ReviewDto) DomainToDto(rv domain.Review) ReviewDto {
+	return ReviewDto{
+		Id:          rv.Id,
+		EventId:     rv.EventId,
+		Rating:      rv.Rating,
+		Body:        rv.Body,
+		Author:      AuthorDto{}.DomainToDto(rv.Author),
+		Reply:       rv.Reply,
+		ReplyDate:   rv.ReplyDate,
+		CreatedDate: rv.CreatedDate,
+		UpdatedDate: rv.UpdatedDate,
+	}
+}
+
+func (d ReviewsDto) DomainToDto(reviews []domain.Review, total uint64, p domain.Pagination) ReviewsDto {
+	items := make([]ReviewDto, len(reviews))
+	for i, rv := range reviews {
+		items[i] = ReviewDto{}.DomainToDto(rv)
+	}
+
+	return ReviewsDto{
+		Items: items,
+		Total: total,
+		Pages: uint((total + p.CountPerPage - 1) / p.CountPerPage),
+	}
+}
+
+func (d RatingDto) DomainToDto(r domain.Rating) RatingDto {
+	return RatingDto{
+		Average: ratingAverage(r.Average, r.Count),
+		Count:   r.Count,
+	}
+}
+
+// ratingAverage is rounded to a tenth, there is none without reviews.
+func ratingAverage(average float64, count uint64) *float64 {
+	if count == 0 {
+		return nil
+	}
+	rounded := math.Round(average*10) / 10
+	return &rounded
+}
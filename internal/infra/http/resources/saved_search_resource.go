@@ -0,0 +1,51 @@
+package resources
+
+import (
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"time"
+)
+
+type SavedSearchDto struct {
+	Id             uint64                      `json:"id"`
+	Name           string                      `json:"name"`
+	Search         string                      `json:"search"`
+	City           string                      `json:"city"`
+	Lat            *float64                    `json:"lat,omitempty"`
+	Lon            *float64                    `json:"lon,omitempty"`
+	RadiusKm       *float64                    `json:"radiusKm,omitempty"`
+	CategoryId     *uint64                     `json:"categoryId,omitempty"`
+	Channel        domain.NotificationChannel  `json:"channel"`
+	Frequency      domain.SavedSearchFrequency `json:"frequency"`
+	LastDigestDate *time.Time                  `json:"lastDigestDate,omitempty"`
+	CreatedDate    time.Time                   `json:"createdDate"`
+}
+
+type SavedSearchesDto struct {
+	Items []SavedSearchDto `json:"items"`
+}
+
+func (d SavedSearchDto) DomainToDto(s domain.SavedSearch) SavedSearchDto {
+	dto := SavedSearchDto{
+		Id:             s.Id,
+		Name:           s.Name,
+		Search:         s.Search,
+		City:           s.City,
+		CategoryId:     s.CategoryId,
+		Channel:        s.Channel,
+		Frequency:      s.Frequency,
+		LastDigestDate: s.LastDigestDate,
+		CreatedDate:    s.CreatedDate,
+	}
+	if s.Point != nil {
+		dto.Lat, dto.Lon, dto.RadiusKm = &s.Point.Lat, &s.Point.Lon, &s.RadiusKm
+	}
+	return dto
+}
+
+func (d SavedSearchesDto) DomainToDto(searches []domain.SavedSearch) SavedSearchesDto {
+	items := make([]SavedSearchDto, len(searches))
+	for i, s := range searches {
+		items[i] = SavedSearchDto{}.DomainToDto(s)
+	}
+	return SavedSearchesDto{Items: items}
+}
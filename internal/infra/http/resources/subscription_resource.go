@@ -0,0 +1,67 @@
+package resources
+
+import (
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"time"
+)
+
+type SubscriptionDto struct {
+	EventId  uint64                    `json:"eventId"`
+	UserId   uint64                    `json:"userId"`
+	Status   domain.SubscriptionStatus `json:"status"`
+	Rsvp     domain.RsvpStatus         `json:"rsvp"`
+	Position uint64                    `json:"position,omitempty"`
+}
+
+func (d SubscriptionDto) DomainToDto(sub domain.Subscription) SubscriptionDto {
+	return SubscriptionDto{
+		EventId:  sub.EventId,
+		UserId:   sub.UserId,
+		Status:   sub.Status,
+		Rsvp:     sub.Rsvp,
+		Position: sub.Position,
+	}
+}
+
+type AttendeeDto struct {
+	User           UserDto           `json:"user"`
+	Rsvp           domain.RsvpStatus `json:"rsvp"`
+	SubscribedDate time.Time         `json:"subscribedDate"`
+}
+
+type AttendeeCountsDto struct {
+	Going      uint64 `json:"going"`
+	Maybe      uint64 `json:"maybe"`
+	Declined   uint64 `json:"declined"`
+	Waitlisted uint64 `json:"waitlisted"`
+}
+
+type AttendeesDto struct {
+	Items  []AttendeeDto     `json:"items"`
+	Total  uint64            `json:"total"`
+	Pages  uint              `json:"pages"`
+	Counts AttendeeCountsDto `json:"counts"`
+}
+
+func (d AttendeesDto) DomainToDto(attendees []domain.Attendee, total uint64, p domain.Pagination, counts domain.AttendeeCounts) AttendeesDto {
+	items := make([]AttendeeDto, len(attendees))
+	for i, a := range attendees {
+		items[i] = AttendeeDto{
+			User:           UserDto{}.DomainToDto(a.User),
+			Rsvp:           a.Rsvp,
+			SubscribedDate: a.SubscribedDate,
+		}
+	}
+
+	return AttendeesDto{
+		Items: items,
+		Total: total,
+		Pages: uint((total + p.CountPerPage - 1) / p.CountPerPage),
+		Counts: AttendeeCountsDto{
+			Going:      counts.Going,
+			Maybe:      counts.Maybe,
+			Declined:   counts.Declined,
+			Waitlisted: counts.Waitlisted,
+		},
+	}
+}
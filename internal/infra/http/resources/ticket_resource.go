@@ -0,0 +1,40 @@
+package resources
+
+import (
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"time"
+)
+
+type TicketDto struct {
+	Id            uint64     `json:"id"`
+	EventId       uint64     `json:"eventId"`
+	UserId        uint64     `json:"userId"`
+	Code          string     `json:"code"`
+	CheckedInDate *time.Time `json:"checkedInDate,omitempty"`
+	CreatedDate   time.Time  `json:"createdDate"`
+}
+
+type CheckInStatsDto struct {
+	Issued    uint64 `json:"issued"`
+	CheckedIn uint64 `json:"checkedIn"`
+	Remaining uint64 `json:"remaining"`
+}
+
+func (d TicketDto) DomainToDto(t domain.Ticket) TicketDto {
+	return TicketDto{
+		Id:            t.Id,
+		EventId:       t.EventId,
+		UserId:        t.UserId,
+		Code:          t.Code,
+		CheckedInDate: t.CheckedInDate,
+		CreatedDate:   t.CreatedDate,
+	}
+}
+
+func (d CheckInStatsDto) DomainToDto(s domain.CheckInStats) CheckInStatsDto {
+	return CheckInStatsDto{
+		Issued:    s.Issued,
+		CheckedIn: s.CheckedIn,
+		Remaining: s.Issued - s.CheckedIn,
+	}
+}
@@ -9,6 +9,7 @@ type UserDto struct {
 	Email      string      `json:"email"`
 	Image      string      `db:"image"`
 	Role       domain.Role `json:"role,omitempty"`
+	Locale     string      `json:"locale"`
 }
 
 type AuthDto struct {
@@ -30,6 +31,7 @@ func (d UserDto) DomainToDto(user domain.User) UserDto {
 		Email:      user.Email,
 		Image:      user.Image,
 		Role:       user.Role,
+		Locale:     user.Locale,
 	}
 }
 
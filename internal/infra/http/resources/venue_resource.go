@@ -0,0 +1,69 @@
+package resources
+
+import (
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+)
+
+type VenuePhotoDto struct {
+	Id    uint64 `json:"id"`
+	Image string `json:"image"`
+}
+
+type VenueDto struct {
+	Id       uint64          `json:"id"`
+	UserId   uint64          `json:"userId"`
+	Name     string          `json:"name"`
+	Address  string          `json:"address"`
+	City     string          `json:"city"`
+	Lat      float64         `json:"lat"`
+	Lon      float64         `json:"lon"`
+	Capacity *uint64         `json:"capacity,omitempty"`
+	Photos   []VenuePhotoDto `json:"photos"`
+	// set on update, the upcoming events that couldn't be moved to the new place
+	NotRelocatedEventIds []uint64 `json:"notRelocatedEventIds,omitempty"`
+}
+
+type VenuesDto struct {
+	Items []VenueDto `json:"items"`
+	Total uint64     `json:"total"`
+	Pages uint       `json:"pages"`
+}
+
+func (d VenuePhotoDto) DomainToDto(p domain.VenuePhoto) VenuePhotoDto {
+	return VenuePhotoDto{
+		Id:    p.Id,
+		Image: p.Image,
+	}
+}
+
+func (d VenueDto) DomainToDto(v domain.Venue) VenueDto {
+	photos := make([]VenuePhotoDto, len(v.Photos))
+	for i, p := range v.Photos {
+		photos[i] = VenuePhotoDto{}.DomainToDto(p)
+	}
+
+	return VenueDto{
+		Id:       v.Id,
+		UserId:   v.UserId,
+		Name:     v.Name,
+		Address:  v.Address,
+		City:     v.City,
+		Lat:      v.Lat,
+		Lon:      v.Lon,
+		Capacity: v.Capacity,
+		Photos:   photos,
+	}
+}
+
+func (d VenuesDto) DomainToDto(venues []domain.Venue, total uint64, p domain.Pagination) VenuesDto {
+	items := make([]VenueDto, len(venues))
+	for i, v := range venues {
+		items[i] = VenueDto{}.DomainToDto(v)
+	}
+
+	return VenuesDto{
+		Items: items,
+		Total: total,
+		Pages: uint((total + p.CountPerPage - 1) / p.CountPerPage),
+	}
+}
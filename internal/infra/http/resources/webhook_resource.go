@@ -0,0 +1,95 @@
+package resources
+
+import (
+	"encoding/json"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+	"time"
+)
+
+type WebhookDto struct {
+	Id          uint64                    `json:"id"`
+	Url         string                    `json:"url"`
+	Secret      string                    `json:"secret"`
+	Events      []domain.WebhookEventType `json:"events"`
+	Active      bool                      `json:"active"`
+	CreatedDate time.Time                 `json:"createdDate"`
+	UpdatedDate time.Time                 `json:"updatedDate"`
+}
+
+type WebhooksDto struct {
+	Items []WebhookDto `json:"items"`
+}
+
+type WebhookDeliveryDto struct {
+	Id              uint64                       `json:"id"`
+	WebhookId       uint64                       `json:"webhookId"`
+	EventType       domain.WebhookEventType      `json:"eventType"`
+	Payload         json.RawMessage              `json:"payload"`
+	Status          domain.WebhookDeliveryStatus `json:"status"`
+	Attempts        uint                         `json:"attempts"`
+	NextAttemptDate *time.Time                   `json:"nextAttemptDate,omitempty"`
+	ResponseCode    int                          `json:"responseCode,omitempty"`
+	Error           string                       `json:"error,omitempty"`
+	CreatedDate     time.Time                    `json:"createdDate"`
+	DeliveredDate   *time.Time                   `json:"deliveredDate,omitempty"`
+}
+
+type WebhookDeliveriesDto struct {
+	Items []WebhookDeliveryDto `json:"items"`
+	Total uint64               `json:"total"`
+	Pages uint                 `json:"pages"`
+}
+
+func (d WebhookDto) DomainToDto(w domain.Webhook) WebhookDto {
+	return WebhookDto{
+		Id:          w.Id,
+		Url:         w.Url,
+		Secret:      w.Secret,
+		Events:      w.Events,
+		Active:      w.Active,
+		CreatedDate: w.CreatedDate,
+		UpdatedDate: w.UpdatedDate,
+	}
+}
+
+func (d WebhooksDto) DomainToDto(webhooks []domain.Webhook) WebhooksDto {
+	items := make([]WebhookDto, len(webhooks))
+	for i, w := range webhooks {
+		items[i] = WebhookDto{}.DomainToDto(w)
+	}
+	return WebhooksDto{Items: items}
+}
+
+func (d WebhookDeliveryDto) DomainToDto(delivery domain.WebhookDelivery) WebhookDeliveryDto {
+	var next *time.Time
+	if delivery.Status == domain.PendingWebhookDeliveryStatus {
+		next = &delivery.NextAttemptDate
+	}
+
+	return WebhookDeliveryDto{
+		Id:              delivery.Id,
+		WebhookId:       delivery.WebhookId,
+		EventType:       delivery.EventType,
+		Payload:         json.RawMessage(delivery.Payload),
+		Status:          delivery.Status,
+		Attempts:        delivery.Attempts,
+		NextAttemptDate: next,
+		ResponseCode:    delivery.ResponseCode,
+		Error:           delivery.Error,
+		CreatedDate:     delivery.CreatedDate,
+		DeliveredDate:   delivery.DeliveredDate,
+	}
+}
+
+func (d WebhookDeliveriesDto) DomainToDto(deliveries []domain.WebhookDelivery, total uint64, p domain.Pagination) WebhookDeliveriesDto {
+	items := make([]WebhookDeliveryDto, len(deliveries))
+	for i, delivery := range deliveries {
+		items[i] = WebhookDeliveryDto{}.DomainToDto(delivery)
+	}
+
+	return WebhookDeliveriesDto{
+		Items: items,
+		Total: total,
+		Pages: uint((total + p.CountPerPage - 1) / p.CountPerPage),
+	}
+}
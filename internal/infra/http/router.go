@@ -6,6 +6,7 @@ import (
 	"github.com/BohdanBoriak/boilerplate-go-back/config"
 	"github.com/BohdanBoriak/boilerplate-go-back/config/container"
 	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/http/controllers"
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/infra/http/middlewares"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/cors"
 	"net/http"
@@ -20,11 +21,13 @@ func Router(cont container.Container) http.Handler {
 
 	router := chi.NewRouter()
 
+	// the stream takes the token as ?jwt=, it must not end up in the access log
+	router.Use(middlewares.RedactQueryMiddleware("jwt"))
 	router.Use(middleware.RedirectSlashes, middleware.Logger, cors.Handler(cors.Options{
 		AllowedOrigins:   []string{"https://*", "http://*", "capacitor://localhost"},
 		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
-		ExposedHeaders:   []string{"Link"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", "Last-Event-ID"},
+		ExposedHeaders:   []string{"Link", "X-Total-Count"},
 		AllowCredentials: false,
 		MaxAge:           300,
 	}))
@@ -42,14 +45,31 @@ func Router(cont container.Container) http.Handler {
 				apiRouter.Route("/auth", func(apiRouter chi.Router) {
 					AuthRouter(apiRouter, cont.AuthController, cont.AuthMw)
 				})
+				apiRouter.Get(
+					"/calendar/{token}.ics",
+					cont.CalendarController.Feed(),
+				)
+				// EventSource can't set headers, the token may be passed as ?jwt= as well
+				apiRouter.With(cont.StreamAuthMw).Get(
+					"/stream",
+					cont.StreamController.Stream(),
+				)
 			})
 
 			// Protected routes
 			apiRouter.Group(func(apiRouter chi.Router) {
 				apiRouter.Use(cont.AuthMw)
 
-				UserRouter(apiRouter, cont.UserController)
-				EventRouter(apiRouter, cont.EventController, cont.PathMw)
+				UserRouter(apiRouter, cont.UserController, cont.CalendarController, cont.ReviewController)
+				EventRouter(apiRouter, cont.EventController, cont.TicketController, cont.CalendarController, cont.AnnouncementController, cont.CommentController, cont.ReviewController, cont.PathMw)
+				CategoryRouter(apiRouter, cont.CategoryController, cont.CategoryPathMw)
+				VenueRouter(apiRouter, cont.VenueController, cont.VenuePathMw)
+				SavedSearchRouter(apiRouter, cont.SavedSearchController, cont.SavedSearchPathMw)
+				NotificationRouter(apiRouter, cont.NotificationController, cont.NotificationPathMw)
+				PushDeviceRouter(apiRouter, cont.PushDeviceController, cont.PushDevicePathMw)
+				WebhookRouter(apiRouter, cont.WebhookController, cont.WebhookPathMw)
+				CommentRouter(apiRouter, cont.CommentController, cont.CommentPathMw)
+				ReviewRouter(apiRouter, cont.ReviewController, cont.ReviewPathMw)
 				apiRouter.Handle("/*", NotFoundJSON())
 			})
 		})
@@ -85,7 +105,7 @@ func AuthRouter(r chi.Router, ac controllers.AuthController, amw func(http.Handl
 	})
 }
 
-func UserRouter(r chi.Router, uc controllers.UserController) {
+func UserRouter(r chi.Router, uc controllers.UserController, cc controllers.CalendarController, rc controllers.ReviewController) {
 	r.Route("/users", func(apiRouter chi.Router) {
 		apiRouter.Get(
 			"/",
@@ -115,16 +135,32 @@ func UserRouter(r chi.Router, uc controllers.UserController) {
 			"/updateImage",
 			uc.UpdateImage(),
 		)
+		apiRouter.Get(
+			"/calendar",
+			cc.FindMyFeed(),
+		)
+		apiRouter.Post(
+			"/calendar/token",
+			cc.RotateFeed(),
+		)
+		apiRouter.Get(
+			"/{userId}/rating",
+			rc.FindOrganizerRating(),
+		)
 	})
 }
 
-func EventRouter(r chi.Router, ev controllers.EventController, pathMw func(http.Handler) http.Handler) {
+func EventRouter(r chi.Router, ev controllers.EventController, tc controllers.TicketController, cc controllers.CalendarController, ac controllers.AnnouncementController, cm controllers.CommentController, rc controllers.ReviewController, pathMw func(http.Handler) http.Handler) {
 	r.Route("/events", func(apiRouter chi.Router) {
 
 		apiRouter.Post(
 			"/",
 			ev.Save(),
 		)
+		apiRouter.Post(
+			"/import",
+			ev.Import(),
+		)
 		apiRouter.With(pathMw).Put(
 			"/update/{eventId}",
 			ev.Update(),
@@ -133,6 +169,10 @@ func EventRouter(r chi.Router, ev controllers.EventController, pathMw func(http.
 			"/delete/{eventId}",
 			ev.Delete(),
 		)
+		apiRouter.With(pathMw).Post(
+			"/{eventId}/cancel",
+			ev.Cancel(),
+		)
 		apiRouter.Get(
 			"/findAll",
 			ev.FindAll(),
@@ -140,6 +180,48 @@ func EventRouter(r chi.Router, ev controllers.EventController, pathMw func(http.
 		apiRouter.With(pathMw).Post(
 			"/subscribe/{eventId}", ev.Subscribe(),
 		)
+		apiRouter.With(pathMw).Post(
+			"/{eventId}/subscribe", ev.Subscribe(),
+		)
+		apiRouter.With(pathMw).Delete(
+			"/{eventId}/subscribe", ev.Unsubscribe(),
+		)
+		apiRouter.With(pathMw).Get(
+			"/{eventId}/attendees", ev.FindAttendees(),
+		)
+		apiRouter.With(pathMw).Get(
+			"/{eventId}/history", ev.History(),
+		)
+		apiRouter.With(pathMw).Get(
+			"/{eventId}/announcements", ac.FindByEvent(),
+		)
+		apiRouter.With(pathMw).Post(
+			"/{eventId}/announcements", ac.Announce(),
+		)
+		apiRouter.With(pathMw).Get(
+			"/{eventId}/comments", cm.FindByEvent(),
+		)
+		apiRouter.With(pathMw).Post(
+			"/{eventId}/comments", cm.Save(),
+		)
+		apiRouter.With(pathMw).Get(
+			"/{eventId}/reviews", rc.FindByEvent(),
+		)
+		apiRouter.With(pathMw).Post(
+			"/{eventId}/reviews", rc.Save(),
+		)
+		apiRouter.With(pathMw).Get(
+			"/{eventId}.ics", cc.EventIcs(),
+		)
+		apiRouter.With(pathMw).Get(
+			"/{eventId}/ticket", tc.FindMyTicket(),
+		)
+		apiRouter.With(pathMw).Post(
+			"/{eventId}/checkin", tc.CheckIn(),
+		)
+		apiRouter.With(pathMw).Get(
+			"/{eventId}/checkin/stats", tc.Stats(),
+		)
 		apiRouter.Get(
 			"/subscriptions", ev.GetUserSubscriptions(),
 		)
@@ -150,10 +232,22 @@ func EventRouter(r chi.Router, ev controllers.EventController, pathMw func(http.
 			"/groupedByDate",
 			ev.FindEventsGroupByDate(),
 		)
+		apiRouter.Get(
+			"/calendar",
+			ev.FindCalendar(),
+		)
 		apiRouter.Get(
 			"/findList",
 			ev.FindList(),
 		)
+		apiRouter.Get(
+			"/map",
+			ev.FindMap(),
+		)
+		apiRouter.Get(
+			"/suggest",
+			ev.Suggest(),
+		)
 		apiRouter.With(pathMw).Post(
 			"/{eventId}/uploadImage",
 			ev.SaveImage(),
@@ -173,6 +267,206 @@ func EventRouter(r chi.Router, ev controllers.EventController, pathMw func(http.
 	})
 }
 
+func CategoryRouter(r chi.Router, cc controllers.CategoryController, pathMw func(http.Handler) http.Handler) {
+	r.Route("/categories", func(apiRouter chi.Router) {
+		apiRouter.Get(
+			"/",
+			cc.FindAll(),
+		)
+		apiRouter.With(middlewares.AdminMiddleware).Post(
+			"/",
+			cc.Save(),
+		)
+		apiRouter.With(middlewares.AdminMiddleware, pathMw).Put(
+			"/{categoryId}",
+			cc.Update(),
+		)
+		apiRouter.With(middlewares.AdminMiddleware, pathMw).Delete(
+			"/{categoryId}",
+			cc.Delete(),
+		)
+	})
+}
+
+func VenueRouter(r chi.Router, vc controllers.VenueController, pathMw func(http.Handler) http.Handler) {
+	r.Route("/venues", func(apiRouter chi.Router) {
+		apiRouter.Get(
+			"/",
+			vc.FindList(),
+		)
+		apiRouter.Post(
+			"/",
+			vc.Save(),
+		)
+		apiRouter.With(pathMw).Get(
+			"/{venueId}",
+			vc.Find(),
+		)
+		apiRouter.With(pathMw).Put(
+			"/{venueId}",
+			vc.Update(),
+		)
+		apiRouter.With(pathMw).Delete(
+			"/{venueId}",
+			vc.Delete(),
+		)
+		apiRouter.With(pathMw).Get(
+			"/{venueId}/events",
+			vc.FindEvents(),
+		)
+		apiRouter.With(pathMw).Post(
+			"/{venueId}/photos",
+			vc.AddPhoto(),
+		)
+		apiRouter.With(pathMw).Delete(
+			"/{venueId}/photos/{photoId}",
+			vc.DeletePhoto(),
+		)
+	})
+}
+
+func SavedSearchRouter(r chi.Router, sc controllers.SavedSearchController, pathMw func(http.Handler) http.Handler) {
+	r.Route("/savedSearches", func(apiRouter chi.Router) {
+		apiRouter.Get(
+			"/",
+			sc.FindMine(),
+		)
+		apiRouter.Post(
+			"/",
+			sc.Save(),
+		)
+		apiRouter.With(pathMw).Get(
+			"/{savedSearchId}",
+			sc.Find(),
+		)
+		apiRouter.With(pathMw).Put(
+			"/{savedSearchId}",
+			sc.Update(),
+		)
+		apiRouter.With(pathMw).Delete(
+			"/{savedSearchId}",
+			sc.Delete(),
+		)
+		apiRouter.With(pathMw).Get(
+			"/{savedSearchId}/events",
+			sc.FindEvents(),
+		)
+	})
+}
+
+func NotificationRouter(r chi.Router, nc controllers.NotificationController, pathMw func(http.Handler) http.Handler) {
+	r.Route("/notifications", func(apiRouter chi.Router) {
+		apiRouter.Get(
+			"/",
+			nc.FindList(),
+		)
+		apiRouter.Get(
+			"/unreadCount",
+			nc.CountUnread(),
+		)
+		apiRouter.Post(
+			"/read",
+			nc.MarkAllRead(),
+		)
+		apiRouter.Get(
+			"/preferences",
+			nc.FindPreferences(),
+		)
+		apiRouter.Put(
+			"/preferences",
+			nc.UpdatePreferences(),
+		)
+		apiRouter.With(pathMw).Post(
+			"/{notificationId}/read",
+			nc.MarkRead(),
+		)
+		apiRouter.With(pathMw).Delete(
+			"/{notificationId}",
+			nc.Delete(),
+		)
+	})
+}
+
+func PushDeviceRouter(r chi.Router, pc controllers.PushDeviceController, pathMw func(http.Handler) http.Handler) {
+	r.Route("/pushDevices", func(apiRouter chi.Router) {
+		apiRouter.Get(
+			"/",
+			pc.FindMine(),
+		)
+		apiRouter.Post(
+			"/",
+			pc.Register(),
+		)
+		apiRouter.With(pathMw).Delete(
+			"/{pushDeviceId}",
+			pc.Unregister(),
+		)
+	})
+}
+
+func WebhookRouter(r chi.Router, wc controllers.WebhookController, pathMw func(http.Handler) http.Handler) {
+	r.Route("/webhooks", func(apiRouter chi.Router) {
+		apiRouter.Get(
+			"/",
+			wc.FindMine(),
+		)
+		apiRouter.Post(
+			"/",
+			wc.Save(),
+		)
+		apiRouter.With(pathMw).Get(
+			"/{webhookId}",
+			wc.Find(),
+		)
+		apiRouter.With(pathMw).Put(
+			"/{webhookId}",
+			wc.Update(),
+		)
+		apiRouter.With(pathMw).Delete(
+			"/{webhookId}",
+			wc.Delete(),
+		)
+		apiRouter.With(pathMw).Post(
+			"/{webhookId}/ping",
+			wc.Ping(),
+		)
+		apiRouter.With(pathMw).Get(
+			"/{webhookId}/deliveries",
+			wc.FindDeliveries(),
+		)
+		apiRouter.With(pathMw).Post(
+			"/{webhookId}/deliveries/{deliveryId}/redeliver",
+			wc.Redeliver(),
+		)
+	})
+}
+
+func CommentRouter(r chi.Router, cc controllers.CommentController, pathMw func(http.Handler) http.Handler) {
+	r.Route("/comments", func(apiRouter chi.Router) {
+		apiRouter.With(pathMw).Put(
+			"/{commentId}",
+			cc.Update(),
+		)
+		apiRouter.With(pathMw).Delete(
+			"/{commentId}",
+			cc.Delete(),
+		)
+	})
+}
+
+func ReviewRouter(r chi.Router, rc controllers.ReviewController, pathMw func(http.Handler) http.Handler) {
+	r.Route("/reviews", func(apiRouter chi.Router) {
+		apiRouter.With(pathMw).Put(
+			"/{reviewId}",
+			rc.Update(),
+		)
+		apiRouter.With(pathMw).Post(
+			"/{reviewId}/reply",
+			rc.Reply(),
+		)
+	})
+}
+
 func NotFoundJSON() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
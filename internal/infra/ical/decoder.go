@@ -0,0 +1,219 @@
+package ical
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+)
+
+const (
+	localDateTimeFormat = "20060102T150405"
+	dateFormat          = "20060102"
+
+	// MaxEvents is the most VEVENTs a calendar may have to be decoded
+	MaxEvents = 500
+)
+
+var (
+	ErrNotCalendar   = errors.New("not an iCalendar file")
+	ErrTooManyEvents = fmt.Errorf("calendar has more than %d events", MaxEvents)
+)
+
+type property struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+// Decode reads the VEVENTs of a VCALENDAR (RFC 5545). Items that can't be turned into
+// an event are returned with Error set, so the caller can report them one by one.
+func Decode(r io.Reader) ([]domain.EventImport, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(lines) == 0 || !strings.EqualFold(lines[0], "BEGIN:VCALENDAR") {
+		return nil, ErrNotCalendar
+	}
+
+	var (
+		imports []domain.EventImport
+		props   []property
+		depth   int // nesting inside the current VEVENT, e.g. VALARM
+		inEvent bool
+	)
+	for _, line := range lines {
+		p, err := parseLine(line)
+		if err != nil {
+			continue
+		}
+
+		switch {
+		case p.name == "BEGIN" && strings.EqualFold(p.value, "VEVENT") && !inEvent:
+			if len(imports) == MaxEvents {
+				return nil, ErrTooManyEvents
+			}
+			inEvent, props, depth = true, nil, 0
+		case p.name == "BEGIN" && inEvent:
+			depth++
+		case p.name == "END" && inEvent && depth > 0:
+			depth--
+		case p.name == "END" && strings.EqualFold(p.value, "VEVENT") && inEvent:
+			inEvent = false
+			imports = append(imports, toEventImport(props))
+		case inEvent && depth == 0:
+			props = append(props, p)
+		}
+	}
+
+	return imports, nil
+}
+
+func toEventImport(props []property) domain.EventImport {
+	var (
+		e     domain.Event
+		start bool
+	)
+	for _, p := range props {
+		var err error
+		switch p.name {
+		case "UID":
+			e.ExternalUid = p.value
+		case "SUMMARY":
+			e.Title = unescape(p.value)
+		case "DESCRIPTION":
+			e.Description = unescape(p.value)
+		case "LOCATION":
+			e.Location = unescape(p.value)
+		case "DTSTART":
+			e.Date, err = parseTime(p)
+			start = err == nil
+		case "DTEND":
+			var end time.Time
+			end, err = parseTime(p)
+			if err == nil {
+				e.EndDate = &end
+			}
+		case "GEO":
+			e.Lat, e.Lon, err = parseGeo(p.value)
+		case "STATUS":
+			if strings.EqualFold(p.value, "CANCELLED") {
+				e.Status = domain.CancelledEventStatus
+			}
+		}
+		if err != nil {
+			return domain.EventImport{Event: e, Error: fmt.Errorf("%s: %w", p.name, err)}
+		}
+	}
+
+	if e.ExternalUid == "" {
+		return domain.EventImport{Event: e, Error: errors.New("UID is missing")}
+	}
+	if !start {
+		return domain.EventImport{Event: e, Error: errors.New("DTSTART is missing")}
+	}
+	if e.EndDate != nil && e.EndDate.Before(e.Date) {
+		return domain.EventImport{Event: e, Error: errors.New("DTEND is before DTSTART")}
+	}
+
+	return domain.EventImport{Event: e}
+}
+
+func unfold(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if len(lines) > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		if line == "" {
+			continue
+		}
+		lines = append(lines, strings.TrimPrefix(line, "\ufeff"))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return lines, nil
+}
+
+// parseLine splits "NAME;PARAM=value;PARAM="quoted":value".
+func parseLine(line string) (property, error) {
+	p := property{params: map[string]string{}}
+
+	quoted := false
+	for i, r := range line {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case r == ':' && !quoted:
+			parts := strings.Split(line[:i], ";")
+			p.name = strings.ToUpper(parts[0])
+			for _, param := range parts[1:] {
+				k, v, _ := strings.Cut(param, "=")
+				p.params[strings.ToUpper(k)] = strings.Trim(v, `"`)
+			}
+			p.value = line[i+1:]
+			return p, nil
+		}
+	}
+
+	return property{}, fmt.Errorf("invalid content line %q", line)
+}
+
+func parseTime(p property) (time.Time, error) {
+	if p.params["VALUE"] == "DATE" || len(p.value) == len(dateFormat) {
+		return time.ParseInLocation(dateFormat, p.value, time.UTC)
+	}
+	if strings.HasSuffix(p.value, "Z") {
+		return time.Parse(dateTimeFormat, p.value)
+	}
+
+	loc := time.UTC
+	if tzid := p.params["TZID"]; tzid != "" {
+		l, err := time.LoadLocation(tzid)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("unknown time zone %q", tzid)
+		}
+		loc = l
+	}
+	return time.ParseInLocation(localDateTimeFormat, p.value, loc)
+}
+
+func parseGeo(value string) (float64, float64, error) {
+	latStr, lonStr, ok := strings.Cut(value, ";")
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid value %q", value)
+	}
+	lat, err := strconv.ParseFloat(latStr, 64)
+	if err != nil || lat < -90 || lat > 90 {
+		return 0, 0, fmt.Errorf("invalid latitude %q", latStr)
+	}
+	lon, err := strconv.ParseFloat(lonStr, 64)
+	if err != nil || lon < -180 || lon > 180 {
+		return 0, 0, fmt.Errorf("invalid longitude %q", lonStr)
+	}
+	return lat, lon, nil
+}
+
+var textUnescaper = strings.NewReplacer(
+	`\\`, `\`,
+	`\;`, ";",
+	`\,`, ",",
+	`\n`, "\n",
+	`\N`, "\n",
+)
+
+func unescape(s string) string {
+	return textUnescaper.Replace(s)
+}
@@ -0,0 +1,33 @@
+package ical
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func calendar(events int) string {
+	var b strings.Builder
+	b.WriteString("BEGIN:VCALENDAR\r\nVERSION:2.0\r\n")
+	for i := 0; i < events; i++ {
+		fmt.Fprintf(&b, "BEGIN:VEVENT\r\nUID:event-%d\r\nSUMMARY:Event %d\r\nDTSTART:20250601T100000Z\r\nEND:VEVENT\r\n", i, i)
+	}
+	b.WriteString("END:VCALENDAR\r\n")
+	return b.String()
+}
+
+func TestDecode_MaxEvents(t *testing.T) {
+	imports, err := Decode(strings.NewReader(calendar(MaxEvents)))
+	if err != nil {
+		t.Fatalf("Decode: %s", err)
+	}
+	if len(imports) != MaxEvents {
+		t.Fatalf("imports = %d, want %d", len(imports), MaxEvents)
+	}
+
+	_, err = Decode(strings.NewReader(calendar(MaxEvents + 1)))
+	if !errors.Is(err, ErrTooManyEvents) {
+		t.Fatalf("err = %v, want %v", err, ErrTooManyEvents)
+	}
+}
@@ -0,0 +1,138 @@
+package ical
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+)
+
+const (
+	ProductId = "-//Eventio//Events//EN"
+	UidDomain = "eventio"
+
+	dateTimeFormat = "20060102T150405Z"
+	maxLineOctets  = 75
+)
+
+// EventUid is stable for the whole life of the event, so calendar clients update
+// the entry instead of adding a new one after every change.
+func EventUid(id uint64) string {
+	return fmt.Sprintf("event-%d@%s", id, UidDomain)
+}
+
+// Encode writes the events as a VCALENDAR (RFC 5545). Deleted and cancelled events
+// are kept in the feed with STATUS:CANCELLED so clients remove them from their calendars.
+func Encode(w io.Writer, name string, events []domain.Event) error {
+	enc := encoder{w: bufio.NewWriter(w)}
+
+	enc.line("BEGIN:VCALENDAR")
+	enc.line("VERSION:2.0")
+	enc.line("PRODID:" + ProductId)
+	enc.line("CALSCALE:GREGORIAN")
+	enc.line("METHOD:PUBLISH")
+	if name != "" {
+		enc.line("X-WR-CALNAME:" + escape(name))
+	}
+
+	now := time.Now()
+	for _, e := range events {
+		enc.event(e, now)
+	}
+
+	enc.line("END:VCALENDAR")
+	if enc.err != nil {
+		return enc.err
+	}
+	return enc.w.Flush()
+}
+
+type encoder struct {
+	w   *bufio.Writer
+	err error
+}
+
+func (e *encoder) event(evn domain.Event, now time.Time) {
+	e.line("BEGIN:VEVENT")
+	e.line("UID:" + EventUid(evn.Id))
+	e.line("DTSTAMP:" + formatTime(now))
+	e.line("DTSTART:" + formatTime(evn.Date))
+	if evn.EndDate != nil {
+		e.line("DTEND:" + formatTime(*evn.EndDate))
+	}
+	e.line("SUMMARY:" + escape(evn.Title))
+	if evn.Description != "" {
+		e.line("DESCRIPTION:" + escape(evn.Description))
+	}
+	if location := eventLocation(evn); location != "" {
+		e.line("LOCATION:" + escape(location))
+	}
+	if evn.Lat != 0 || evn.Lon != 0 {
+		e.line(fmt.Sprintf("GEO:%.6f;%.6f", evn.Lat, evn.Lon))
+	}
+	if !evn.CreatedDate.IsZero() {
+		e.line("CREATED:" + formatTime(evn.CreatedDate))
+	}
+	if !evn.UpdatedDate.IsZero() {
+		e.line("LAST-MODIFIED:" + formatTime(evn.UpdatedDate))
+		e.line(fmt.Sprintf("SEQUENCE:%d", evn.UpdatedDate.Unix()))
+	}
+	if evn.Status == domain.CancelledEventStatus || evn.DeletedDate != nil {
+		e.line("STATUS:CANCELLED")
+	} else {
+		e.line("STATUS:CONFIRMED")
+	}
+	e.line("END:VEVENT")
+}
+
+// line writes a content line folded to 75 octets, without splitting UTF-8 sequences.
+func (e *encoder) line(s string) {
+	if e.err != nil {
+		return
+	}
+
+	var b strings.Builder
+	width := 0
+	for _, r := range s {
+		size := len(string(r))
+		if width+size > maxLineOctets {
+			b.WriteString("\r\n ")
+			width = 1
+		}
+		b.WriteRune(r)
+		width += size
+	}
+	b.WriteString("\r\n")
+
+	_, e.err = e.w.WriteString(b.String())
+}
+
+func eventLocation(evn domain.Event) string {
+	switch {
+	case evn.Location != "" && evn.City != "":
+		return evn.Location + ", " + evn.City
+	case evn.Location != "":
+		return evn.Location
+	default:
+		return evn.City
+	}
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(dateTimeFormat)
+}
+
+var textEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	";", `\;`,
+	",", `\,`,
+	"\r\n", `\n`,
+	"\n", `\n`,
+)
+
+func escape(s string) string {
+	return textEscaper.Replace(s)
+}
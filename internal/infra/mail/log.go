@@ -0,0 +1,19 @@
+package mail
+
+import (
+	"log"
+
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+)
+
+// LogMailer only logs the emails, for development.
+type LogMailer struct{}
+
+func NewLogMailer() LogMailer {
+	return LogMailer{}
+}
+
+func (m LogMailer) Send(mail domain.Mail) error {
+	log.Printf("Mail to %s: %s", mail.To, mail.Subject)
+	return nil
+}
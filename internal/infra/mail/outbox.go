@@ -0,0 +1,35 @@
+package mail
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+)
+
+// OutboxMailer writes each email as an .eml file into a directory, so they can be
+// opened with a mail client instead of being sent.
+type OutboxMailer struct {
+	dir  string
+	from string
+}
+
+func NewOutboxMailer(dir, from string) (OutboxMailer, error) {
+	err := os.MkdirAll(dir, 0o755)
+	if err != nil {
+		return OutboxMailer{}, err
+	}
+	return OutboxMailer{dir: dir, from: from}, nil
+}
+
+func (m OutboxMailer) Send(mail domain.Mail) error {
+	content, err := message(m.from, mail)
+	if err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%s_%d.eml", time.Now().UTC().Format("20060102T150405.000000000"), mail.Id)
+	return os.WriteFile(filepath.Join(m.dir, name), content, 0o644)
+}
@@ -0,0 +1,47 @@
+package mail
+
+import (
+	"net"
+	"net/mail"
+	"net/smtp"
+
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+)
+
+// SmtpMailer sends the emails through an SMTP server. The connection is upgraded with
+// STARTTLS when the server supports it, authentication is only used with a user set.
+type SmtpMailer struct {
+	addr     string
+	from     string
+	user     string
+	password string
+}
+
+func NewSmtpMailer(addr, from, user, password string) SmtpMailer {
+	return SmtpMailer{addr: addr, from: from, user: user, password: password}
+}
+
+func (m SmtpMailer) Send(email domain.Mail) error {
+	content, err := message(m.from, email)
+	if err != nil {
+		return err
+	}
+	sender, err := mail.ParseAddress(m.from)
+	if err != nil {
+		return err
+	}
+	recipient, err := mail.ParseAddress(email.To)
+	if err != nil {
+		return err
+	}
+
+	var auth smtp.Auth
+	if m.user != "" {
+		host, _, err := net.SplitHostPort(m.addr)
+		if err != nil {
+			return err
+		}
+		auth = smtp.PlainAuth("", m.user, m.password, host)
+	}
+	return smtp.SendMail(m.addr, auth, sender.Address, []string{recipient.Address}, content)
+}
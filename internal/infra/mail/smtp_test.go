@@ -0,0 +1,164 @@
+package mail
+
+import (
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+)
+
+type caughtMail struct {
+	from string
+	to   []string
+	data []byte
+}
+
+// catchMail accepts a single SMTP session on a local port, the way cmd/smtp-catcher does,
+// and hands over what was sent.
+func catchMail(t *testing.T) (string, <-chan caughtMail) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen: %s", err)
+	}
+	t.Cleanup(func() { _ = listener.Close() })
+
+	caught := make(chan caughtMail, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		c := textproto.NewConn(conn)
+
+		var m caughtMail
+		_ = c.PrintfLine("220 catcher ready")
+		for {
+			line, err := c.ReadLine()
+			if err != nil {
+				return
+			}
+			verb, arg, _ := strings.Cut(line, " ")
+			switch strings.ToUpper(verb) {
+			case "EHLO", "HELO":
+				_ = c.PrintfLine("250 catcher")
+			case "MAIL":
+				m.from = strings.Trim(strings.TrimPrefix(arg, "FROM:"), "<>")
+				_ = c.PrintfLine("250 OK")
+			case "RCPT":
+				m.to = append(m.to, strings.Trim(strings.TrimPrefix(arg, "TO:"), "<>"))
+				_ = c.PrintfLine("250 OK")
+			case "DATA":
+				_ = c.PrintfLine("354 End data with <CR><LF>.<CR><LF>")
+				m.data, err = c.ReadDotBytes()
+				if err != nil {
+					return
+				}
+				_ = c.PrintfLine("250 OK")
+				caught <- m
+			case "QUIT":
+				_ = c.PrintfLine("221 Bye")
+				return
+			default:
+				_ = c.PrintfLine("502 command not implemented")
+			}
+		}
+	}()
+
+	return listener.Addr().String(), caught
+}
+
+func TestSmtpMailer_Send(t *testing.T) {
+	addr, caught := catchMail(t)
+	mailer := NewSmtpMailer(addr, "Eventio <no-reply@eventio.local>", "", "")
+
+	email := domain.Mail{
+		To:      "Олена <olena@example.com>",
+		Subject: "Нагадування: Meetup",
+		Html:    "<p>Meetup starts in <b>1 hour</b> — don't be late</p>",
+		Text:    "Meetup starts in 1 hour — don't be late",
+	}
+	err := mailer.Send(email)
+	if err != nil {
+		t.Fatalf("Send: %s", err)
+	}
+
+	var m caughtMail
+	select {
+	case m = <-caught:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no mail caught")
+	}
+
+	if m.from != "no-reply@eventio.local" {
+		t.Errorf("envelope from = %q", m.from)
+	}
+	if len(m.to) != 1 || m.to[0] != "olena@example.com" {
+		t.Errorf("envelope to = %q", m.to)
+	}
+
+	msg, err := mail.ReadMessage(strings.NewReader(string(m.data)))
+	if err != nil {
+		t.Fatalf("mail.ReadMessage: %s", err)
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil {
+		t.Fatalf("decode Subject: %s", err)
+	}
+	if subject != email.Subject {
+		t.Errorf("Subject = %q, want %q", subject, email.Subject)
+	}
+	from, err := msg.Header.AddressList("From")
+	if err != nil || len(from) != 1 || from[0].Address != "no-reply@eventio.local" || from[0].Name != "Eventio" {
+		t.Errorf("From = %q", msg.Header.Get("From"))
+	}
+	to, err := msg.Header.AddressList("To")
+	if err != nil || len(to) != 1 || to[0].Address != "olena@example.com" || to[0].Name != "Олена" {
+		t.Errorf("To = %q", msg.Header.Get("To"))
+	}
+	for _, h := range []string{"Date", "Message-ID", "MIME-Version"} {
+		if msg.Header.Get(h) == "" {
+			t.Errorf("%s header is missing", h)
+		}
+	}
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("Content-Type = %q", msg.Header.Get("Content-Type"))
+	}
+	parts := multipart.NewReader(msg.Body, params["boundary"])
+	for _, want := range []struct{ contentType, body string }{
+		{"text/plain", email.Text},
+		{"text/html", email.Html},
+	} {
+		part, err := parts.NextPart()
+		if err != nil {
+			t.Fatalf("NextPart: %s", err)
+		}
+		contentType, params, err := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		if err != nil || contentType != want.contentType || params["charset"] != "utf-8" {
+			t.Errorf("part Content-Type = %q, want %s; charset=utf-8", part.Header.Get("Content-Type"), want.contentType)
+		}
+		// the quoted-printable encoding is undone by the reader
+		body, err := io.ReadAll(part)
+		if err != nil {
+			t.Fatalf("read %s part: %s", want.contentType, err)
+		}
+		if strings.TrimRight(string(body), "\r\n") != want.body {
+			t.Errorf("%s part = %q, want %q", want.contentType, body, want.body)
+		}
+	}
+	_, err = parts.NextPart()
+	if err != io.EOF {
+		t.Errorf("more parts than expected: %v", err)
+	}
+}
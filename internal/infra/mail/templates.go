@@ -0,0 +1,80 @@
+package mail
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"path"
+	"strings"
+	texttemplate "text/template"
+
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+)
+
+//go:embed templates
+var bundledTemplates embed.FS
+
+// Templates renders the emails from the bundled templates/<locale>/<name>.{html,txt} files.
+// The text template defines the subject as the "subject" block.
+type Templates struct {
+	html map[string]*htmltemplate.Template
+	text map[string]*texttemplate.Template
+}
+
+func NewTemplates() (Templates, error) {
+	t := Templates{
+		html: make(map[string]*htmltemplate.Template),
+		text: make(map[string]*texttemplate.Template),
+	}
+
+	err := fs.WalkDir(bundledTemplates, "templates", func(file string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		locale := path.Base(path.Dir(file))
+		name := strings.TrimSuffix(path.Base(file), path.Ext(file))
+		key := locale + "/" + name
+		switch path.Ext(file) {
+		case ".html":
+			t.html[key], err = htmltemplate.ParseFS(bundledTemplates, file)
+		case ".txt":
+			t.text[key], err = texttemplate.ParseFS(bundledTemplates, file)
+		}
+		return err
+	})
+	if err != nil {
+		return Templates{}, err
+	}
+	return t, nil
+}
+
+// Render falls back to the default locale when the email is not translated.
+func (t Templates) Render(locale, name string, data interface{}) (string, string, string, error) {
+	key := locale + "/" + name
+	if t.html[key] == nil || t.text[key] == nil {
+		key = domain.DefaultLocale + "/" + name
+	}
+	html, text := t.html[key], t.text[key]
+	if html == nil || text == nil {
+		return "", "", "", fmt.Errorf("mail template %q not found", name)
+	}
+
+	var subject, htmlBody, textBody bytes.Buffer
+	err := text.ExecuteTemplate(&subject, "subject", data)
+	if err != nil {
+		return "", "", "", err
+	}
+	err = text.Execute(&textBody, data)
+	if err != nil {
+		return "", "", "", err
+	}
+	err = html.Execute(&htmlBody, data)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	return strings.TrimSpace(subject.String()), htmlBody.String(), strings.TrimSpace(textBody.String()) + "\n", nil
+}
@@ -0,0 +1,133 @@
+package push
+
+import (
+	"bytes"
+	"crypto"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+)
+
+const (
+	apnsProductionUrl = "https://api.push.apple.com/3/device/"
+	apnsSandboxUrl    = "https://api.sandbox.push.apple.com/3/device/"
+	// Apple rejects the provider tokens older than an hour and throttles refreshes more
+	// often than every 20 minutes
+	apnsTokenTTL = 40 * time.Minute
+)
+
+// ApnsSender sends the pushes to the iOS apps through the Apple Push Notification service,
+// with token based authentication. The requests go over HTTP/2, as APNs requires.
+type ApnsSender struct {
+	url    string
+	topic  string
+	keyId  string
+	teamId string
+	key    crypto.Signer
+	client *http.Client
+	token  *accessToken
+}
+
+// NewApnsSender takes the .p8 signing key with its id, the developer team and the bundle id
+// of the app as the topic.
+func NewApnsSender(keyFile, keyId, teamId, topic string, production bool) (ApnsSender, error) {
+	key, err := readPrivateKey(keyFile)
+	if err != nil {
+		return ApnsSender{}, fmt.Errorf("invalid APNs key: %w", err)
+	}
+
+	url := apnsSandboxUrl
+	if production {
+		url = apnsProductionUrl
+	}
+	return ApnsSender{
+		url:    url,
+		topic:  topic,
+		keyId:  keyId,
+		teamId: teamId,
+		key:    key,
+		client: &http.Client{Timeout: 10 * time.Second},
+		token:  &accessToken{},
+	}, nil
+}
+
+func (s ApnsSender) Send(d domain.PushDevice, m domain.PushMessage) error {
+	token, err := s.providerToken()
+	if err != nil {
+		return err
+	}
+
+	payload := map[string]interface{}{
+		"aps": map[string]interface{}{
+			"alert": map[string]string{
+				"title": m.Title,
+				"body":  m.Body,
+			},
+			"sound": "default",
+		},
+	}
+	for k, v := range m.Data {
+		if k != "aps" {
+			payload[k] = v
+		}
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, s.url+d.Token, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "bearer "+token)
+	req.Header.Set("Apns-Topic", s.topic)
+	req.Header.Set("Apns-Push-Type", "alert")
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 300 {
+		return nil
+	}
+
+	var failure struct {
+		Reason string `json:"reason"`
+	}
+	_ = json.NewDecoder(io.LimitReader(resp.Body, 4096)).Decode(&failure)
+	switch failure.Reason {
+	case "BadDeviceToken", "Unregistered", "DeviceTokenNotForTopic":
+		return domain.ErrInvalidPushToken
+	}
+	if resp.StatusCode == http.StatusGone {
+		return domain.ErrInvalidPushToken
+	}
+	return fmt.Errorf("apns responded with %s: %s", resp.Status, failure.Reason)
+}
+
+func (s ApnsSender) providerToken() (string, error) {
+	s.token.mu.Lock()
+	defer s.token.mu.Unlock()
+	if time.Now().Before(s.token.expires) {
+		return s.token.value, nil
+	}
+
+	now := time.Now()
+	token, err := signJwt(s.key, s.keyId, map[string]interface{}{
+		"iss": s.teamId,
+		"iat": now.Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+	s.token.value = token
+	s.token.expires = now.Add(apnsTokenTTL)
+	return token, nil
+}
@@ -0,0 +1,48 @@
+package push
+
+import (
+	"log"
+	"sync"
+
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+)
+
+type FakePush struct {
+	Device  domain.PushDevice
+	Message domain.PushMessage
+}
+
+// FakeSender keeps the pushes in memory and logs them, it stands in for the providers
+// locally and in tests. The given tokens are rejected as invalid.
+type FakeSender struct {
+	mu      *sync.Mutex
+	sent    *[]FakePush
+	invalid map[string]bool
+}
+
+func NewFakeSender(invalidTokens ...string) FakeSender {
+	invalid := make(map[string]bool, len(invalidTokens))
+	for _, token := range invalidTokens {
+		invalid[token] = true
+	}
+	return FakeSender{mu: &sync.Mutex{}, sent: &[]FakePush{}, invalid: invalid}
+}
+
+func (s FakeSender) Send(d domain.PushDevice, m domain.PushMessage) error {
+	if s.invalid[d.Token] {
+		return domain.ErrInvalidPushToken
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	*s.sent = append(*s.sent, FakePush{Device: d, Message: m})
+	log.Printf("Push to %s device %d of user %d: %s", d.Platform, d.Id, d.UserId, m.Title)
+	return nil
+}
+
+// Sent returns the pushes sent so far.
+func (s FakeSender) Sent() []FakePush {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]FakePush(nil), *s.sent...)
+}
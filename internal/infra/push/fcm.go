@@ -0,0 +1,176 @@
+package push
+
+import (
+	"bytes"
+	"crypto"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+)
+
+const (
+	fcmUrl   = "https://fcm.googleapis.com/v1/projects/%s/messages:send"
+	fcmScope = "https://www.googleapis.com/auth/firebase.messaging"
+)
+
+// FcmSender sends the pushes to the Android apps through the Firebase Cloud Messaging
+// HTTP v1 API, authorized as the service account.
+type FcmSender struct {
+	projectId   string
+	clientEmail string
+	tokenUri    string
+	key         crypto.Signer
+	client      *http.Client
+	token       *accessToken
+}
+
+type accessToken struct {
+	mu      sync.Mutex
+	value   string
+	expires time.Time
+}
+
+type serviceAccount struct {
+	ProjectId   string `json:"project_id"`
+	ClientEmail string `json:"client_email"`
+	PrivateKey  string `json:"private_key"`
+	TokenUri    string `json:"token_uri"`
+}
+
+// NewFcmSender reads the JSON key of the service account, as downloaded from the Firebase console.
+func NewFcmSender(credentialsFile string) (FcmSender, error) {
+	data, err := os.ReadFile(credentialsFile)
+	if err != nil {
+		return FcmSender{}, err
+	}
+	var account serviceAccount
+	err = json.Unmarshal(data, &account)
+	if err != nil {
+		return FcmSender{}, fmt.Errorf("invalid FCM credentials: %w", err)
+	}
+	key, err := parsePrivateKey([]byte(account.PrivateKey))
+	if err != nil {
+		return FcmSender{}, fmt.Errorf("invalid FCM credentials: %w", err)
+	}
+
+	return FcmSender{
+		projectId:   account.ProjectId,
+		clientEmail: account.ClientEmail,
+		tokenUri:    account.TokenUri,
+		key:         key,
+		client:      &http.Client{Timeout: 10 * time.Second},
+		token:       &accessToken{},
+	}, nil
+}
+
+func (s FcmSender) Send(d domain.PushDevice, m domain.PushMessage) error {
+	token, err := s.accessToken()
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(map[string]interface{}{
+		"message": map[string]interface{}{
+			"token": d.Token,
+			"notification": map[string]string{
+				"title": m.Title,
+				"body":  m.Body,
+			},
+			"data": m.Data,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf(fcmUrl, s.projectId), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 300 {
+		return nil
+	}
+
+	var failure struct {
+		Error struct {
+			Status  string `json:"status"`
+			Message string `json:"message"`
+			Details []struct {
+				ErrorCode string `json:"errorCode"`
+			} `json:"details"`
+		} `json:"error"`
+	}
+	_ = json.NewDecoder(io.LimitReader(resp.Body, 4096)).Decode(&failure)
+	if resp.StatusCode == http.StatusNotFound {
+		return domain.ErrInvalidPushToken
+	}
+	for _, detail := range failure.Error.Details {
+		if detail.ErrorCode == "UNREGISTERED" {
+			return domain.ErrInvalidPushToken
+		}
+	}
+	return fmt.Errorf("fcm responded with %s: %s", resp.Status, failure.Error.Message)
+}
+
+// accessToken exchanges a token signed by the service account for an OAuth 2 access token,
+// it is reused until shortly before it expires.
+func (s FcmSender) accessToken() (string, error) {
+	s.token.mu.Lock()
+	defer s.token.mu.Unlock()
+	if time.Now().Before(s.token.expires) {
+		return s.token.value, nil
+	}
+
+	now := time.Now()
+	assertion, err := signJwt(s.key, "", map[string]interface{}{
+		"iss":   s.clientEmail,
+		"scope": fcmScope,
+		"aud":   s.tokenUri,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {assertion},
+	}
+	resp, err := s.client.Post(s.tokenUri, "application/x-www-form-urlencoded", strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return "", fmt.Errorf("fcm token exchange responded with %s: %s", resp.Status, msg)
+	}
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&token)
+	if err != nil {
+		return "", err
+	}
+	s.token.value = token.AccessToken
+	s.token.expires = now.Add(time.Duration(token.ExpiresIn)*time.Second - time.Minute)
+	return s.token.value, nil
+}
@@ -0,0 +1,125 @@
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+)
+
+const userAgent = "Eventio-Webhooks/1.0"
+
+var (
+	errAddressNotAllowed = errors.New("webhook address is not allowed")
+	errTimeout           = errors.New("webhook timed out")
+	errUnreachable       = errors.New("webhook could not be reached")
+)
+
+// HttpSender posts the deliveries as JSON. The receivers verify them by computing the
+// hex HMAC-SHA256 of "<X-Webhook-Timestamp>.<body>" with the secret of the webhook and
+// comparing it with X-Webhook-Signature, and reject old timestamps to prevent replays.
+// Only public addresses are connected to, see domain.IsPublicAddress, whatever the host
+// of the webhook resolves to at the time.
+type HttpSender struct {
+	client *http.Client
+}
+
+func NewHttpSender() HttpSender {
+	return newHttpSender(domain.IsPublicAddress)
+}
+
+func newHttpSender(allowed func(ip net.IP) bool) HttpSender {
+	dialer := &net.Dialer{
+		Timeout: 5 * time.Second,
+		// the address is checked after the host has been resolved, right before connecting
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			ip := net.ParseIP(host)
+			if ip == nil || !allowed(ip) {
+				return errAddressNotAllowed
+			}
+			return nil
+		},
+	}
+
+	return HttpSender{
+		client: &http.Client{
+			Timeout: 10 * time.Second,
+			Transport: &http.Transport{
+				// a proxy would be the address checked instead of the webhook's
+				Proxy:               nil,
+				DialContext:         dialer.DialContext,
+				TLSHandshakeTimeout: 5 * time.Second,
+				MaxIdleConns:        10,
+				IdleConnTimeout:     90 * time.Second,
+			},
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+// Send returns the response code, non 2xx codes are returned with an error.
+func (s HttpSender) Send(w domain.Webhook, d domain.WebhookDelivery) (int, error) {
+	req, err := http.NewRequest(http.MethodPost, w.Url, bytes.NewReader([]byte(d.Payload)))
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("X-Webhook-Id", strconv.FormatUint(w.Id, 10))
+	req.Header.Set("X-Webhook-Delivery", strconv.FormatUint(d.Id, 10))
+	req.Header.Set("X-Webhook-Event", string(d.EventType))
+	req.Header.Set("X-Webhook-Timestamp", timestamp)
+	req.Header.Set("X-Webhook-Signature", "sha256="+Sign(w.Secret, timestamp, d.Payload))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		// the transport errors tell about the network of the server, the owner of the
+		// webhook only gets to know what went wrong
+		log.Printf("HttpSender -> Send -> s.client.Do(%d): %s", d.Id, err)
+		return 0, publicError(err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("webhook responded with %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
+
+func publicError(err error) error {
+	var netErr net.Error
+	switch {
+	case errors.Is(err, errAddressNotAllowed):
+		return errAddressNotAllowed
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return errTimeout
+	default:
+		return errUnreachable
+	}
+}
+
+func Sign(secret, timestamp, payload string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "." + payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
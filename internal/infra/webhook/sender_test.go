@@ -0,0 +1,73 @@
+package webhook
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/BohdanBoriak/boilerplate-go-back/internal/domain"
+)
+
+func TestHttpSender_RefusesPrivateAddresses(t *testing.T) {
+	called := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer srv.Close()
+
+	hook := domain.Webhook{Id: 1, Url: srv.URL, Secret: "whsec_test"}
+	code, err := NewHttpSender().Send(hook, domain.WebhookDelivery{Id: 1, EventType: domain.PingWebhook, Payload: "{}"})
+	if err != errAddressNotAllowed {
+		t.Errorf("Send error = %v, want %v", err, errAddressNotAllowed)
+	}
+	if code != 0 || called {
+		t.Errorf("the webhook on %s was called", srv.URL)
+	}
+}
+
+func TestHttpSender_Send(t *testing.T) {
+	var got *http.Request
+	var body string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		got, body = r, string(b)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	sender := newHttpSender(func(net.IP) bool { return true })
+	hook := domain.Webhook{Id: 1, Url: srv.URL, Secret: "whsec_test"}
+	code, err := sender.Send(hook, domain.WebhookDelivery{Id: 1, EventType: domain.PingWebhook, Payload: `{"type":"ping"}`})
+	if err != nil {
+		t.Fatalf("Send: %s", err)
+	}
+	if code != http.StatusNoContent {
+		t.Errorf("code = %d, want %d", code, http.StatusNoContent)
+	}
+	if body != `{"type":"ping"}` {
+		t.Errorf("body = %q", body)
+	}
+	timestamp := got.Header.Get("X-Webhook-Timestamp")
+	want := "sha256=" + Sign(hook.Secret, timestamp, body)
+	if timestamp == "" || got.Header.Get("X-Webhook-Signature") != want {
+		t.Errorf("X-Webhook-Signature = %q, want %q", got.Header.Get("X-Webhook-Signature"), want)
+	}
+}
+
+func TestHttpSender_HidesTransportErrors(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen: %s", err)
+	}
+	addr := listener.Addr().String()
+	_ = listener.Close()
+
+	sender := newHttpSender(func(net.IP) bool { return true })
+	hook := domain.Webhook{Id: 1, Url: "http://" + addr, Secret: "whsec_test"}
+	_, err = sender.Send(hook, domain.WebhookDelivery{Id: 1, EventType: domain.PingWebhook, Payload: "{}"})
+	if err != errUnreachable {
+		t.Errorf("Send error = %v, want %v", err, errUnreachable)
+	}
+}